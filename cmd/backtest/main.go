@@ -0,0 +1,227 @@
+// Command backtest replays a recorded order stream, from the database or a
+// CSV/JSONL file, through a fresh matching engine with a strategy's orders
+// interleaved, and reports how the strategy's orders would have filled.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"order-matching-system/internal/backtest"
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/models"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	flowFile := flag.String("flow", "", "recorded flow file (.csv or .jsonl); without it the flow is read from the database")
+	symbol := flag.String("symbol", "", "symbol whose flow is read from the database")
+	from := flag.String("from", "", "start of the recorded window, RFC 3339 (database only)")
+	to := flag.String("to", "", "end of the recorded window, RFC 3339 (database only; default now)")
+	export := flag.String("export", "", "write the recorded flow to this .csv or .jsonl file and exit")
+
+	ordersFile := flag.String("orders", "", "strategy orders and cancels placed at fixed times (.csv or .jsonl)")
+	account := flag.String("account", backtest.DefaultAccount, "account the strategy's orders are placed under")
+	joinSide := flag.String("join", "", "work a passive order at the touch on this side: buy or sell")
+	joinSymbol := flag.String("join-symbol", "", "symbol of the -join order (default -symbol)")
+	joinQty := flag.Float64("join-qty", 100, "total quantity of the -join order")
+	joinClip := flag.Float64("join-clip", 0, "largest child order of -join; 0 places the whole remainder")
+
+	out := flag.String("out", "", "directory orders.csv and fills.csv are written to; empty prints the summary only")
+	flag.Parse()
+
+	// The engine logs every order it handles; only its failures matter here
+	slog.SetLogLoggerLevel(slog.LevelError)
+
+	var flow []*models.FlowEvent
+	var instruments []*models.Instrument
+	var err error
+	if *flowFile != "" {
+		flow, err = backtest.LoadFlow(*flowFile)
+		if err != nil {
+			log.Fatalf("failed to read flow: %v", err)
+		}
+	} else {
+		flow, instruments, err = loadRecorded(*symbol, *from, *to)
+		if err != nil {
+			log.Fatalf("failed to load recorded flow: %v", err)
+		}
+	}
+	log.Printf("Loaded %d recorded events", len(flow))
+
+	if *export != "" {
+		if err := backtest.SaveFlow(*export, flow); err != nil {
+			log.Fatalf("failed to export flow: %v", err)
+		}
+		log.Printf("Wrote flow to %s", *export)
+		return
+	}
+
+	cfg := backtest.Config{Flow: flow, Account: *account, Instruments: instruments}
+	if *ordersFile != "" {
+		if cfg.Orders, err = backtest.LoadFlow(*ordersFile); err != nil {
+			log.Fatalf("failed to read strategy orders: %v", err)
+		}
+	}
+	if *joinSide != "" {
+		side := models.OrderSide(*joinSide)
+		if side != models.OrderSideBuy && side != models.OrderSideSell {
+			log.Fatalf("-join must be buy or sell, got %q", *joinSide)
+		}
+		sym := *joinSymbol
+		if sym == "" {
+			sym = *symbol
+		}
+		if sym == "" {
+			log.Fatal("-join needs -join-symbol or -symbol")
+		}
+		cfg.Strategy = &backtest.JoinTouch{Symbol: sym, Side: side, Quantity: *joinQty, Clip: *joinClip}
+	}
+	if len(cfg.Orders) == 0 && cfg.Strategy == nil {
+		log.Println("No strategy given (-orders or -join); replaying the recorded flow alone")
+	}
+
+	bt, err := backtest.New(cfg)
+	if err != nil {
+		log.Fatalf("invalid backtest: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	began := time.Now()
+	report, err := bt.Run(ctx)
+	if err != nil {
+		log.Fatalf("backtest failed: %v", err)
+	}
+	log.Printf("Replay finished in %v", time.Since(began).Round(time.Millisecond))
+
+	writeSummary(os.Stdout, report)
+
+	if *out != "" {
+		if err := os.MkdirAll(*out, 0o755); err != nil {
+			log.Fatalf("failed to create output directory: %v", err)
+		}
+		for name, write := range map[string]func(io.Writer, *backtest.Report) error{
+			"orders.csv": writeOrders,
+			"fills.csv":  writeFills,
+		} {
+			if err := writeFile(filepath.Join(*out, name), report, write); err != nil {
+				log.Fatalf("failed to write %s: %v", name, err)
+			}
+		}
+		fmt.Printf("\nwrote orders.csv and fills.csv to %s\n", *out)
+	}
+}
+
+// loadRecorded reads symbol's order stream and instrument from the database
+// configured by the DB_* variables
+func loadRecorded(symbol, from, to string) ([]*models.FlowEvent, []*models.Instrument, error) {
+	if symbol == "" || from == "" {
+		return nil, nil, fmt.Errorf("-symbol and -from are required without -flow")
+	}
+	fromTime, err := time.Parse(time.RFC3339, from)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid -from: %w", err)
+	}
+	toTime := time.Now()
+	if to != "" {
+		if toTime, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, nil, fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Println("Loading configuration from system environment variables")
+	}
+	err = database.Initialize(database.Config{
+		Host:     getRequiredEnv("DB_HOST"),
+		Port:     getRequiredEnv("DB_PORT"),
+		User:     getRequiredEnv("DB_USER"),
+		Password: getRequiredEnv("DB_PASSWORD"),
+		Database: getRequiredEnv("DB_NAME"),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer database.Close()
+
+	ctx := context.Background()
+	flow, err := database.NewOrderRepository(database.DB).GetOrderFlow(ctx, symbol, fromTime, toTime)
+	if err != nil {
+		return nil, nil, err
+	}
+	instrument, err := database.NewInstrumentRepository(database.DB).GetInstrument(ctx, symbol)
+	if err != nil {
+		return nil, nil, err
+	}
+	return flow, []*models.Instrument{instrument}, nil
+}
+
+// writeSummary prints replay counts, fill statistics and the strategy's
+// positions
+func writeSummary(w io.Writer, report *backtest.Report) {
+	fmt.Fprintf(w, "\nreplayed %d orders, %d cancels (%d missed) and %d amendments (%d missed), %d trades\n",
+		report.RecordedOrders, report.RecordedCancels, report.MissedCancels, report.RecordedAmends, report.MissedAmends, report.Trades)
+	if len(report.Orders) == 0 {
+		return
+	}
+
+	var placed, filled, notional, makerQty, slippage, slippageQty float64
+	for _, o := range report.Orders {
+		placed += o.Order.InitialQuantity
+		for _, f := range o.Fills {
+			filled += f.Quantity
+			notional += f.Price * f.Quantity
+			if f.Maker {
+				makerQty += f.Quantity
+			}
+			// Positive when the fill was worse than the arrival midpoint
+			if o.ArrivalMid > 0 {
+				diff := f.Price - o.ArrivalMid
+				if f.Side == models.OrderSideSell {
+					diff = -diff
+				}
+				slippage += diff / o.ArrivalMid * 1e4 * f.Quantity
+				slippageQty += f.Quantity
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\nstrategy: %d orders, %g of %g filled (%.1f%%) in %d fills\n",
+		len(report.Orders), filled, placed, 100*filled/placed, len(report.Fills))
+	if filled > 0 {
+		fmt.Fprintf(w, "average price %.6g, %.1f%% as maker", notional/filled, 100*makerQty/filled)
+		if slippageQty > 0 {
+			fmt.Fprintf(w, ", %.2f bps vs arrival mid", slippage/slippageQty)
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "SYMBOL\tPOSITION\tAVG COST\tREALIZED\tUNREALIZED\t")
+	for _, p := range report.Positions {
+		fmt.Fprintf(tw, "%s\t%g\t%.6g\t%.2f\t%.2f\t\n", p.Symbol, p.Quantity, p.AverageCost, p.RealizedPnL, p.UnrealizedPnL)
+	}
+	tw.Flush()
+}
+
+func getRequiredEnv(key string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		log.Fatalf("%s environment variable is required", key)
+	}
+	return value
+}
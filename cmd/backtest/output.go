@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"order-matching-system/internal/backtest"
+)
+
+func writeFile(path string, report *backtest.Report, write func(io.Writer, *backtest.Report) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, report); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeOrders writes one row per strategy order with its queue position on
+// arrival and how long it took to start filling
+func writeOrders(w io.Writer, report *backtest.Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"order_id", "placed_at", "symbol", "side", "type", "price", "quantity", "filled_quantity", "average_fill_price",
+		"status", "arrival_mid", "queue_ahead", "queue_better", "fills", "first_fill_at", "time_to_first_fill",
+	})
+	for _, r := range report.Orders {
+		o := r.Order
+		var queueAhead, queueBetter, firstFillAt, timeToFill string
+		if r.Queue != nil {
+			queueAhead, queueBetter = formatFloat(r.Queue.Ahead), formatFloat(r.Queue.Better)
+		}
+		if len(r.Fills) > 0 {
+			first := r.Fills[0].Time
+			firstFillAt, timeToFill = formatTime(first), first.Sub(r.PlacedAt).String()
+		}
+		cw.Write([]string{
+			strconv.Itoa(o.ID), formatTime(r.PlacedAt), o.Symbol, string(o.Side), string(o.Type),
+			formatFloat(o.Price), formatFloat(o.InitialQuantity), formatFloat(o.FilledQuantity), formatFloat(o.AverageFillPrice),
+			string(o.Status), formatFloat(r.ArrivalMid), queueAhead, queueBetter,
+			strconv.Itoa(len(r.Fills)), firstFillAt, timeToFill,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeFills writes one row per trade of a strategy order
+func writeFills(w io.Writer, report *backtest.Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"time", "order_id", "trade_id", "side", "price", "quantity", "liquidity"})
+	for _, f := range report.Fills {
+		liquidity := "taker"
+		if f.Maker {
+			liquidity = "maker"
+		}
+		cw.Write([]string{
+			formatTime(f.Time), strconv.Itoa(f.OrderID), strconv.Itoa(f.TradeID), string(f.Side),
+			formatFloat(f.Price), formatFloat(f.Quantity), liquidity,
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
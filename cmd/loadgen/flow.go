@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"order-matching-system/internal/models"
+)
+
+// flowConfig describes the order flow to generate
+type flowConfig struct {
+	symbols     []string
+	accounts    int
+	marketRatio float64 // Share of new orders that are market orders
+	crossRatio  float64 // Share of limit orders priced to cross the spread
+	cancelRatio float64 // Share of operations that cancel a resting order
+	mid         float64
+	tick        float64
+	spreadTicks int // Passive orders rest up to this many ticks from mid
+	qtyMin      float64
+	qtyMax      float64
+}
+
+// opKind is the kind of operation a worker performs
+type opKind int
+
+const (
+	opLimit opKind = iota
+	opMarket
+	opCancel
+	numOps
+)
+
+var opNames = [numOps]string{"place limit", "place market", "cancel"}
+
+// op is one generated operation: an order to place or an order to cancel
+type op struct {
+	kind    opKind
+	req     *models.PlaceOrderRequest
+	orderID int
+}
+
+// flow generates operations for one worker. Each worker cancels only orders
+// it placed itself, so flows need no locking.
+type flow struct {
+	cfg     *flowConfig
+	rng     *rand.Rand
+	account string
+	resting []int // Orders this worker placed that were open when last seen
+}
+
+func newFlow(cfg *flowConfig, seed int64, worker int) *flow {
+	rng := rand.New(rand.NewSource(seed + int64(worker)))
+	return &flow{
+		cfg:     cfg,
+		rng:     rng,
+		account: accountName(worker % cfg.accounts),
+	}
+}
+
+func (f *flow) next() op {
+	if len(f.resting) > 0 && f.rng.Float64() < f.cfg.cancelRatio {
+		i := f.rng.Intn(len(f.resting))
+		id := f.resting[i]
+		f.resting[i] = f.resting[len(f.resting)-1]
+		f.resting = f.resting[:len(f.resting)-1]
+		return op{kind: opCancel, orderID: id}
+	}
+
+	req := &models.PlaceOrderRequest{
+		AccountID: f.account,
+		Symbol:    f.cfg.symbols[f.rng.Intn(len(f.cfg.symbols))],
+		Side:      models.OrderSideBuy,
+		Type:      models.OrderTypeLimit,
+		Quantity:  f.quantity(),
+	}
+	if f.rng.Intn(2) == 0 {
+		req.Side = models.OrderSideSell
+	}
+
+	if f.rng.Float64() < f.cfg.marketRatio {
+		req.Type = models.OrderTypeMarket
+		return op{kind: opMarket, req: req}
+	}
+
+	// Passive orders rest on their own side of mid; crossing orders are
+	// priced on the far side so that they take liquidity
+	ticks := float64(1 + f.rng.Intn(f.cfg.spreadTicks))
+	if f.rng.Float64() < f.cfg.crossRatio {
+		ticks = -ticks
+	}
+	if req.Side == models.OrderSideBuy {
+		ticks = -ticks
+	}
+	req.Price = roundTo(f.cfg.mid+ticks*f.cfg.tick, f.cfg.tick)
+	if req.Price <= 0 {
+		req.Price = f.cfg.tick
+	}
+	return op{kind: opLimit, req: req}
+}
+
+// placed records the outcome of an order so that it can be canceled later
+func (f *flow) placed(order *models.Order) {
+	if order.Status == models.OrderStatusOpen {
+		f.resting = append(f.resting, order.ID)
+	}
+}
+
+// quantity picks a whole number of units between the configured bounds
+func (f *flow) quantity() float64 {
+	lo, hi := f.cfg.qtyMin, f.cfg.qtyMax
+	if hi <= lo {
+		return lo
+	}
+	return math.Round(lo + f.rng.Float64()*(hi-lo))
+}
+
+// roundTo rounds v to a multiple of step, without float noise
+func roundTo(v, step float64) float64 {
+	return models.FromUnits(models.ToUnits(math.Round(v/step) * step))
+}
+
+func accountName(i int) string {
+	return fmt.Sprintf("loadgen-%d", i)
+}
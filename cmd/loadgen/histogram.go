@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"strings"
+	"time"
+)
+
+// Latencies are counted in log-linear buckets: values below 2^subBucketBits
+// nanoseconds get a bucket each, and every power of two above that is split
+// into 2^(subBucketBits-1) equal buckets, bounding the error at about 3%
+const (
+	subBucketBits = 6
+	subBuckets    = 1 << subBucketBits
+	halfBuckets   = subBuckets / 2
+)
+
+// histogram records a latency distribution in constant memory. It is not
+// safe for concurrent use; each worker keeps its own and they are merged.
+type histogram struct {
+	counts [subBuckets + 64*halfBuckets]uint64
+	total  uint64
+	sum    time.Duration
+	min    time.Duration
+	max    time.Duration
+}
+
+func bucketIndex(d time.Duration) int {
+	v := uint64(d)
+	if d < 0 {
+		v = 0
+	}
+	if v < subBuckets {
+		return int(v)
+	}
+	shift := bits.Len64(v) - subBucketBits
+	return subBuckets + (shift-1)*halfBuckets + int(v>>shift) - halfBuckets
+}
+
+// bucketBounds returns the smallest and largest value counted in bucket i
+func bucketBounds(i int) (time.Duration, time.Duration) {
+	if i < subBuckets {
+		return time.Duration(i), time.Duration(i)
+	}
+	shift := (i-subBuckets)/halfBuckets + 1
+	mantissa := uint64((i-subBuckets)%halfBuckets + halfBuckets)
+	low := mantissa << shift
+	return time.Duration(low), time.Duration(low + 1<<shift - 1)
+}
+
+func (h *histogram) record(d time.Duration) {
+	h.counts[bucketIndex(d)]++
+	if h.total == 0 || d < h.min {
+		h.min = d
+	}
+	if d > h.max {
+		h.max = d
+	}
+	h.total++
+	h.sum += d
+}
+
+func (h *histogram) merge(other *histogram) {
+	if other.total == 0 {
+		return
+	}
+	for i, n := range other.counts {
+		h.counts[i] += n
+	}
+	if h.total == 0 || other.min < h.min {
+		h.min = other.min
+	}
+	if other.max > h.max {
+		h.max = other.max
+	}
+	h.total += other.total
+	h.sum += other.sum
+}
+
+// quantile returns the latency at or below which fraction q of the recorded
+// values fall, as the midpoint of its bucket
+func (h *histogram) quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(q * float64(h.total)))
+	if rank == 0 {
+		rank = 1
+	}
+
+	var seen uint64
+	for i, n := range h.counts {
+		seen += n
+		if seen >= rank {
+			low, high := bucketBounds(i)
+			mid := low + (high-low)/2
+			if mid > h.max {
+				return h.max
+			}
+			return mid
+		}
+	}
+	return h.max
+}
+
+func (h *histogram) mean() time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.total)
+}
+
+// writeDistribution draws the histogram as bars over power-of-two ranges
+func (h *histogram) writeDistribution(w io.Writer) {
+	if h.total == 0 {
+		return
+	}
+
+	// Coarsen to one row per power of two
+	var rows []struct {
+		upTo  time.Duration
+		count uint64
+	}
+	var peak uint64
+	for i, n := range h.counts {
+		if n == 0 {
+			continue
+		}
+		_, high := bucketBounds(i)
+		upTo := time.Duration(1) << bits.Len64(uint64(high))
+		if len(rows) == 0 || rows[len(rows)-1].upTo != upTo {
+			rows = append(rows, struct {
+				upTo  time.Duration
+				count uint64
+			}{upTo: upTo})
+		}
+		rows[len(rows)-1].count += n
+		if c := rows[len(rows)-1].count; c > peak {
+			peak = c
+		}
+	}
+
+	const width = 40
+	for _, row := range rows {
+		bar := int(math.Round(float64(row.count) / float64(peak) * width))
+		if bar == 0 {
+			bar = 1
+		}
+		fmt.Fprintf(w, "    < %-10v %-*s %d\n", row.upTo, width, strings.Repeat("#", bar), row.count)
+	}
+}
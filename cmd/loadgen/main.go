@@ -0,0 +1,381 @@
+// Command loadgen drives generated order flow at the matching system, over
+// the HTTP API or directly against a MatchingEngine, and reports throughput
+// and latency. With -verify it checks book invariants after the run.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/models"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	targetName := flag.String("target", "http", "system under load: http or engine")
+	baseURL := flag.String("url", getEnv("OMS_URL", "http://localhost:8080"), "API base URL for the http target (env OMS_URL)")
+	keyID := flag.String("key", os.Getenv("OMS_API_KEY"), "API key ID for signed requests (env OMS_API_KEY)")
+	secret := flag.String("secret", os.Getenv("OMS_API_SECRET"), "API key secret (env OMS_API_SECRET)")
+
+	duration := flag.Duration("duration", 30*time.Second, "how long to run; 0 runs until -orders is reached")
+	maxOps := flag.Int64("orders", 0, "stop after this many operations; 0 means no limit")
+	concurrency := flag.Int("concurrency", 16, "number of concurrent workers")
+	rate := flag.Float64("rate", 0, "target operations per second across all workers; 0 means as fast as possible")
+
+	symbols := flag.String("symbols", "LOADA,LOADB", "comma-separated symbols to trade")
+	accounts := flag.Int("accounts", 8, "number of accounts orders are spread over")
+	marketRatio := flag.Float64("market-ratio", 0.1, "share of new orders that are market orders")
+	crossRatio := flag.Float64("cross-ratio", 0.3, "share of limit orders priced to cross the spread")
+	cancelRatio := flag.Float64("cancel-ratio", 0.2, "share of operations that cancel a resting order")
+	mid := flag.Float64("mid", 100, "price that generated orders are centered on")
+	tick := flag.Float64("tick", 0.01, "price increment of generated orders")
+	spreadTicks := flag.Int("spread-ticks", 10, "how many ticks from mid orders are priced")
+	qtyMin := flag.Float64("qty-min", 1, "smallest order quantity")
+	qtyMax := flag.Float64("qty-max", 100, "largest order quantity")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed, for repeatable flow")
+
+	doVerify := flag.Bool("verify", false, "check book invariants after the run")
+	verifySample := flag.Int("verify-sample", 1000, "number of placed orders whose fills are checked by -verify")
+	cleanup := flag.Bool("cleanup", true, "cancel the generated accounts' open orders after the run")
+	flag.Parse()
+
+	// The engine logs every order it handles; only its failures matter here
+	slog.SetLogLoggerLevel(slog.LevelError)
+
+	cfg := &flowConfig{
+		symbols:     splitList(*symbols),
+		accounts:    *accounts,
+		marketRatio: *marketRatio,
+		crossRatio:  *crossRatio,
+		cancelRatio: *cancelRatio,
+		mid:         *mid,
+		tick:        *tick,
+		spreadTicks: *spreadTicks,
+		qtyMin:      *qtyMin,
+		qtyMax:      *qtyMax,
+	}
+	if err := cfg.validate(); err != nil {
+		log.Fatalf("invalid flow: %v", err)
+	}
+	if *concurrency < 1 {
+		log.Fatal("-concurrency must be at least 1")
+	}
+	if *duration <= 0 && *maxOps <= 0 {
+		log.Fatal("one of -duration or -orders is required")
+	}
+
+	var t target
+	var err error
+	switch *targetName {
+	case "http":
+		t, err = newHTTPTarget(*baseURL, *keyID, *secret, *concurrency)
+	case "engine":
+		t, err = newEngineTarget(dbConfig())
+	default:
+		log.Fatalf("unknown target %q, expected http or engine", *targetName)
+	}
+	if err != nil {
+		log.Fatalf("failed to set up %s target: %v", *targetName, err)
+	}
+	defer t.close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Running %d workers against %s target (seed %d)", *concurrency, *targetName, *seed)
+	r := &runner{
+		target:      t,
+		flow:        cfg,
+		concurrency: *concurrency,
+		maxOps:      *maxOps,
+		rate:        *rate,
+		seed:        *seed,
+	}
+	result := r.run(ctx, *duration)
+	result.write(os.Stdout, *targetName, *concurrency)
+
+	// Checks and cleanup still run after an interrupt
+	after, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	failed := false
+	if *doVerify {
+		violations, err := verify(after, t, cfg.symbols, result.placed, *verifySample, *seed)
+		if err != nil {
+			log.Fatalf("verification failed to run: %v", err)
+		}
+		if len(violations) > 0 {
+			fmt.Printf("\nverify: %d invariant violations\n", len(violations))
+			for _, v := range violations {
+				fmt.Printf("  %s\n", v)
+			}
+			failed = true
+		} else {
+			fmt.Printf("\nverify: book invariants hold (%d symbols, fills of %d orders checked)\n",
+				len(cfg.symbols), min(*verifySample, len(result.placed)))
+		}
+	}
+
+	if *cleanup {
+		canceled := 0
+		for i := 0; i < cfg.accounts; i++ {
+			n, err := t.massCancel(after, accountName(i))
+			if err != nil {
+				log.Printf("Failed to cancel open orders for %s: %v", accountName(i), err)
+				continue
+			}
+			canceled += n
+		}
+		log.Printf("Canceled %d resting orders", canceled)
+	}
+
+	if failed {
+		t.close()
+		os.Exit(1)
+	}
+}
+
+func (c *flowConfig) validate() error {
+	switch {
+	case len(c.symbols) == 0:
+		return errors.New("at least one symbol is required")
+	case c.accounts < 1:
+		return errors.New("-accounts must be at least 1")
+	case c.marketRatio < 0 || c.marketRatio > 1, c.crossRatio < 0 || c.crossRatio > 1, c.cancelRatio < 0 || c.cancelRatio >= 1:
+		return errors.New("ratios must be between 0 and 1, and -cancel-ratio below 1")
+	case c.tick <= 0 || c.mid <= 0:
+		return errors.New("-mid and -tick must be positive")
+	case c.spreadTicks < 1:
+		return errors.New("-spread-ticks must be at least 1")
+	case c.qtyMin < 1 || c.qtyMax < c.qtyMin:
+		return errors.New("-qty-min must be at least 1 and no larger than -qty-max")
+	}
+	return nil
+}
+
+// runner runs workers until the duration elapses or the operation budget
+// is spent
+type runner struct {
+	target      target
+	flow        *flowConfig
+	concurrency int
+	maxOps      int64
+	rate        float64
+	seed        int64
+
+	started atomic.Int64
+}
+
+// stats are one worker's counts and latencies, merged into the result
+type stats struct {
+	latency  [numOps]histogram
+	ok       [numOps]uint64
+	rejected [numOps]uint64 // Orders refused by risk checks, cancels of orders no longer open
+	failed   [numOps]uint64
+	lastErr  [numOps]error
+	placed   []placedOrder
+}
+
+type result struct {
+	stats
+	elapsed time.Duration
+}
+
+func (r *runner) run(ctx context.Context, duration time.Duration) *result {
+	if duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	// A shared ticker paces all workers when a rate is set
+	var ticks <-chan time.Time
+	if r.rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / r.rate))
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	workers := make([]*stats, r.concurrency)
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := range workers {
+		workers[i] = &stats{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r.work(ctx, newFlow(r.flow, r.seed, i), ticks, workers[i])
+		}(i)
+	}
+	wg.Wait()
+
+	res := &result{elapsed: time.Since(start)}
+	for _, s := range workers {
+		for k := range s.latency {
+			res.latency[k].merge(&s.latency[k])
+			res.ok[k] += s.ok[k]
+			res.rejected[k] += s.rejected[k]
+			res.failed[k] += s.failed[k]
+			if s.lastErr[k] != nil {
+				res.lastErr[k] = s.lastErr[k]
+			}
+		}
+		res.placed = append(res.placed, s.placed...)
+	}
+	return res
+}
+
+func (r *runner) work(ctx context.Context, f *flow, ticks <-chan time.Time, s *stats) {
+	for {
+		if ticks != nil {
+			select {
+			case <-ticks:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if r.maxOps > 0 && r.started.Add(1) > r.maxOps {
+			return
+		}
+
+		o := f.next()
+		start := time.Now()
+		var err error
+		if o.kind == opCancel {
+			err = r.target.cancelOrder(ctx, o.orderID)
+		} else {
+			var order *models.Order
+			order, err = r.target.placeOrder(ctx, o.req)
+			if err == nil {
+				f.placed(order)
+				s.placed = append(s.placed, placedOrder{
+					id: order.ID, side: order.Side, typ: order.Type, price: order.Price, quantity: order.InitialQuantity,
+				})
+			}
+		}
+		elapsed := time.Since(start)
+
+		switch {
+		case err == nil:
+			s.ok[o.kind]++
+			s.latency[o.kind].record(elapsed)
+		case errors.Is(err, errRejected), errors.Is(err, errNotCancelable):
+			s.rejected[o.kind]++
+			s.latency[o.kind].record(elapsed)
+		case ctx.Err() != nil:
+			// Cut off by the end of the run rather than failed
+			return
+		default:
+			s.failed[o.kind]++
+			s.lastErr[o.kind] = err
+		}
+	}
+}
+
+// write prints throughput, outcome counts and latency by operation
+func (res *result) write(w io.Writer, targetName string, concurrency int) {
+	var total uint64
+	for k := range res.latency {
+		total += res.ok[k] + res.rejected[k] + res.failed[k]
+	}
+	seconds := res.elapsed.Seconds()
+	fmt.Fprintf(w, "\n%s target, %d workers: %d operations in %v (%.1f ops/s)\n\n",
+		targetName, concurrency, total, res.elapsed.Round(time.Millisecond), float64(total)/seconds)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "OPERATION\tOK\tREJECTED\tFAILED\tOPS/S\tMEAN\tP50\tP90\tP99\tP99.9\tMAX\t")
+	for k := opKind(0); k < numOps; k++ {
+		h := &res.latency[k]
+		count := res.ok[k] + res.rejected[k] + res.failed[k]
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%.1f\t%v\t%v\t%v\t%v\t%v\t%v\t\n",
+			opNames[k], res.ok[k], res.rejected[k], res.failed[k], float64(count)/seconds,
+			round(h.mean()), round(h.quantile(0.5)), round(h.quantile(0.9)),
+			round(h.quantile(0.99)), round(h.quantile(0.999)), round(h.max))
+	}
+	tw.Flush()
+
+	for k := opKind(0); k < numOps; k++ {
+		if res.lastErr[k] != nil {
+			fmt.Fprintf(w, "\nlast %s failure: %v\n", opNames[k], res.lastErr[k])
+		}
+	}
+
+	for k := opKind(0); k < numOps; k++ {
+		if res.latency[k].total == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s latency:\n", opNames[k])
+		res.latency[k].writeDistribution(w)
+	}
+}
+
+// round trims durations to three significant figures for display
+func round(d time.Duration) time.Duration {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond)
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond)
+	case d >= time.Microsecond:
+		return d.Round(10 * time.Nanosecond)
+	}
+	return d
+}
+
+// dbConfig reads the database settings of the engine target from the same
+// environment variables as the server
+func dbConfig() database.Config {
+	if err := godotenv.Load(); err != nil {
+		log.Println("Loading configuration from system environment variables")
+	}
+	return database.Config{
+		Host:     getRequiredEnv("DB_HOST"),
+		Port:     getRequiredEnv("DB_PORT"),
+		User:     getRequiredEnv("DB_USER"),
+		Password: getRequiredEnv("DB_PASSWORD"),
+		Database: getRequiredEnv("DB_NAME"),
+	}
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func getRequiredEnv(key string) string {
+	value := os.Getenv(key)
+	if value == "" {
+		log.Fatalf("%s environment variable is required", key)
+	}
+	return value
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/models"
+	"order-matching-system/internal/service"
+	"order-matching-system/pkg/client"
+)
+
+// target is the system under load: the HTTP API or an in-process engine
+type target interface {
+	placeOrder(ctx context.Context, req *models.PlaceOrderRequest) (*models.Order, error)
+	cancelOrder(ctx context.Context, orderID int) error
+
+	// Used after the run to check invariants and clean up
+	openOrders(ctx context.Context, symbol string) ([]*models.Order, error)
+	topOfBook(ctx context.Context, symbol string) (*models.OrderBook, error)
+	orderFills(ctx context.Context, orderID int) (*models.OrderFills, error)
+	massCancel(ctx context.Context, accountID string) (int, error)
+	close() error
+}
+
+// errRejected marks orders refused by a risk check or halt, which are
+// counted separately from failures
+var errRejected = errors.New("order rejected")
+
+// errNotCancelable marks cancels that lost the race with a fill, which are
+// expected under load
+var errNotCancelable = errors.New("order no longer open")
+
+// httpTarget drives the HTTP API through the Go client
+type httpTarget struct {
+	client *client.Client
+}
+
+func newHTTPTarget(baseURL, keyID, secret string, concurrency int) (*httpTarget, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = concurrency
+
+	// Retries would hide failures and distort latency
+	opts := []client.Option{
+		client.WithHTTPClient(&http.Client{Transport: transport}),
+		client.WithRetry(0, 0, 0),
+	}
+	if keyID != "" {
+		opts = append(opts, client.WithAPIKey(keyID, secret))
+	}
+
+	c, err := client.New(baseURL, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &httpTarget{client: c}, nil
+}
+
+func (t *httpTarget) placeOrder(ctx context.Context, req *models.PlaceOrderRequest) (*models.Order, error) {
+	order, err := t.client.PlaceOrder(ctx, req)
+	if errors.Is(err, client.ErrRejected) {
+		return nil, fmt.Errorf("%w: %v", errRejected, err)
+	}
+	return order, err
+}
+
+func (t *httpTarget) cancelOrder(ctx context.Context, orderID int) error {
+	err := t.client.CancelOrder(ctx, orderID)
+	if errors.Is(err, client.ErrNotFound) {
+		return errNotCancelable
+	}
+	return err
+}
+
+// openOrders lists every open order, a page of the API's maximum at a time
+func (t *httpTarget) openOrders(ctx context.Context, symbol string) ([]*models.Order, error) {
+	const pageSize = 1000
+
+	var orders []*models.Order
+	filter := client.OrderFilter{Symbol: symbol, Status: client.Open, Limit: pageSize}
+	for {
+		page, err := t.client.ListOrders(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, page...)
+		if len(page) < pageSize {
+			return orders, nil
+		}
+		filter.BeforeID = page[len(page)-1].ID
+	}
+}
+
+func (t *httpTarget) topOfBook(ctx context.Context, symbol string) (*models.OrderBook, error) {
+	return t.client.GetOrderBook(ctx, symbol, client.BookOptions{Level: client.BookLevel1})
+}
+
+func (t *httpTarget) orderFills(ctx context.Context, orderID int) (*models.OrderFills, error) {
+	return t.client.GetOrderFills(ctx, orderID)
+}
+
+func (t *httpTarget) massCancel(ctx context.Context, accountID string) (int, error) {
+	result, err := t.client.MassCancel(ctx, client.OrderFilter{AccountID: accountID})
+	if err != nil {
+		return 0, err
+	}
+	return result.Canceled, nil
+}
+
+func (t *httpTarget) close() error {
+	return nil
+}
+
+// engineTarget calls a MatchingEngine in this process, measuring matching
+// and database time without HTTP. It must not share a database with a
+// running server, whose engine would not see this one's book lock.
+type engineTarget struct {
+	db     *sql.DB
+	engine *service.MatchingEngine
+	orders *database.OrderRepository
+	trades *database.TradeRepository
+	book   *database.OrderBookRepository
+}
+
+func newEngineTarget(cfg database.Config) (*engineTarget, error) {
+	if err := database.Initialize(cfg); err != nil {
+		return nil, err
+	}
+	db := database.DB
+
+	return &engineTarget{
+		db:     db,
+		engine: service.NewMatchingEngine(db),
+		orders: database.NewOrderRepository(db),
+		trades: database.NewTradeRepository(db),
+		book:   database.NewOrderBookRepository(db),
+	}, nil
+}
+
+func (t *engineTarget) placeOrder(ctx context.Context, req *models.PlaceOrderRequest) (*models.Order, error) {
+	order := req.NewOrder()
+	if err := t.engine.ProcessOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	if order.Status == models.OrderStatusRejected {
+		return nil, fmt.Errorf("%w: %s", errRejected, order.StatusReason)
+	}
+	return order, nil
+}
+
+func (t *engineTarget) cancelOrder(ctx context.Context, orderID int) error {
+	_, err := t.engine.CancelOrder(ctx, orderID, "loadgen")
+	if errors.Is(err, service.ErrOrderNotCancelable) {
+		return errNotCancelable
+	}
+	return err
+}
+
+func (t *engineTarget) openOrders(ctx context.Context, symbol string) ([]*models.Order, error) {
+	return t.orders.GetOpenOrdersBySymbol(ctx, symbol)
+}
+
+func (t *engineTarget) topOfBook(ctx context.Context, symbol string) (*models.OrderBook, error) {
+	return t.book.GetOrderBook(ctx, symbol, models.BookLevel1, 1)
+}
+
+func (t *engineTarget) orderFills(ctx context.Context, orderID int) (*models.OrderFills, error) {
+	order, err := t.orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	trades, err := t.trades.GetTradesByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.OrderFills{
+		OrderID:          order.ID,
+		FilledQuantity:   order.FilledQuantity,
+		AverageFillPrice: order.AverageFillPrice,
+		Fills:            trades,
+	}, nil
+}
+
+func (t *engineTarget) massCancel(ctx context.Context, accountID string) (int, error) {
+	result, err := t.engine.MassCancel(ctx, models.OrderFilter{AccountID: accountID}, "loadgen")
+	if err != nil {
+		return 0, err
+	}
+	return result.Canceled, nil
+}
+
+func (t *engineTarget) close() error {
+	return t.db.Close()
+}
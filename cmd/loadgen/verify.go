@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"order-matching-system/internal/models"
+)
+
+// placedOrder is what a worker remembers about an order it placed, enough to
+// check its fills afterwards
+type placedOrder struct {
+	id       int
+	side     models.OrderSide
+	typ      models.OrderType
+	price    float64
+	quantity float64
+}
+
+// verifier checks book invariants after a run and collects violations
+type verifier struct {
+	target     target
+	violations []string
+}
+
+func (v *verifier) failf(format string, args ...interface{}) {
+	v.violations = append(v.violations, fmt.Sprintf(format, args...))
+}
+
+// checkBook verifies that the symbol's book is not crossed and that every
+// resting order is a limit order with consistent quantities
+func (v *verifier) checkBook(ctx context.Context, symbol string) error {
+	book, err := v.target.topOfBook(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get order book for %s: %w", symbol, err)
+	}
+	if len(book.Bids) > 0 && len(book.Asks) > 0 && book.Bids[0].Price >= book.Asks[0].Price {
+		v.failf("%s: book is crossed, best bid %v >= best ask %v", symbol, book.Bids[0].Price, book.Asks[0].Price)
+	}
+
+	orders, err := v.target.openOrders(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("failed to list open orders for %s: %w", symbol, err)
+	}
+
+	for _, o := range orders {
+		if o.Type != models.OrderTypeLimit {
+			v.failf("order %d: %s order resting on the book", o.ID, o.Type)
+		}
+		if o.RemainingQuantity <= 0 {
+			v.failf("order %d: open with remaining quantity %v", o.ID, o.RemainingQuantity)
+		}
+		if models.ToUnits(o.FilledQuantity)+models.ToUnits(o.RemainingQuantity) != models.ToUnits(o.InitialQuantity) {
+			v.failf("order %d: filled %v + remaining %v != quantity %v",
+				o.ID, o.FilledQuantity, o.RemainingQuantity, o.InitialQuantity)
+		}
+	}
+	return nil
+}
+
+// checkFills verifies that an order's trades add up to its filled quantity
+// and respect its limit price
+func (v *verifier) checkFills(ctx context.Context, p placedOrder) error {
+	fills, err := v.target.orderFills(ctx, p.id)
+	if err != nil {
+		return fmt.Errorf("failed to get fills for order %d: %w", p.id, err)
+	}
+
+	var filled int64
+	for _, t := range fills.Fills {
+		if t.Busted {
+			continue
+		}
+		if t.BuyOrderID != p.id && t.SellOrderID != p.id {
+			v.failf("order %d: fill %d belongs to orders %d and %d", p.id, t.ID, t.BuyOrderID, t.SellOrderID)
+		}
+		filled += models.ToUnits(t.Quantity)
+
+		if p.typ != models.OrderTypeLimit {
+			continue
+		}
+		if (p.side == models.OrderSideBuy && t.Price > p.price) || (p.side == models.OrderSideSell && t.Price < p.price) {
+			v.failf("order %d: %s limit %v filled at %v in trade %d", p.id, p.side, p.price, t.Price, t.ID)
+		}
+	}
+
+	if filled != models.ToUnits(fills.FilledQuantity) {
+		v.failf("order %d: fills sum to %v but filled quantity is %v", p.id, models.FromUnits(filled), fills.FilledQuantity)
+	}
+	if fills.FilledQuantity > p.quantity {
+		v.failf("order %d: filled %v of %v", p.id, fills.FilledQuantity, p.quantity)
+	}
+	return nil
+}
+
+// verify runs every check, looking at the fills of up to sample of the
+// placed orders, and returns the violations found
+func verify(ctx context.Context, t target, symbols []string, placed []placedOrder, sample int, seed int64) ([]string, error) {
+	v := &verifier{target: t}
+
+	for _, symbol := range symbols {
+		if err := v.checkBook(ctx, symbol); err != nil {
+			return nil, err
+		}
+	}
+
+	if sample > len(placed) {
+		sample = len(placed)
+	}
+	rng := rand.New(rand.NewSource(seed))
+	for _, i := range rng.Perm(len(placed))[:sample] {
+		if err := v.checkFills(ctx, placed[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return v.violations, nil
+}
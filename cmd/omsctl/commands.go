@@ -0,0 +1,436 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"order-matching-system/pkg/client"
+)
+
+// parseFlags parses fs from args, allowing flags after positional
+// arguments, and returns the positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args) // Exits on error
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+func parseOrderID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, usagef("invalid order ID %q", arg)
+	}
+	return id, nil
+}
+
+func placeCmd(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("place", flag.ExitOnError)
+	symbol := fs.String("symbol", "", "symbol to trade (required)")
+	side := fs.String("side", "", "buy or sell (required)")
+	orderType := fs.String("type", "", "limit or market (default limit with -price, otherwise market)")
+	price := fs.Float64("price", 0, "limit price")
+	quantity := fs.Float64("qty", 0, "quantity (required)")
+	account := fs.String("account", "", "account ID")
+	maxSlippage := fs.Float64("max-slippage", 0, "market orders: cancel any remainder beyond this distance from the best price")
+	key := fs.String("idempotency-key", "", "key making the order safe to resubmit (default random)")
+	parseFlags(fs, args)
+
+	if *symbol == "" || *side == "" || *quantity <= 0 {
+		return usagef("place requires -symbol, -side and -qty")
+	}
+	if *orderType == "" {
+		*orderType = string(client.Market)
+		if *price > 0 {
+			*orderType = string(client.Limit)
+		}
+	}
+	if *key == "" {
+		*key = client.NewIdempotencyKey()
+	}
+
+	ctx, cancel := a.call(ctx)
+	defer cancel()
+
+	order, err := a.client.PlaceOrderWithKey(ctx, *key, &client.PlaceOrderRequest{
+		AccountID:   *account,
+		Symbol:      *symbol,
+		Side:        client.OrderSide(*side),
+		Type:        client.OrderType(*orderType),
+		Price:       *price,
+		Quantity:    *quantity,
+		MaxSlippage: *maxSlippage,
+	})
+	var rejected *client.RejectedError
+	if errors.As(err, &rejected) {
+		if err := a.out.order(rejected.Order); err != nil {
+			return err
+		}
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	return a.out.order(order)
+}
+
+func amendCmd(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("amend", flag.ExitOnError)
+	price := fs.Float64("price", 0, "new limit price")
+	quantity := fs.Float64("qty", 0, "new total quantity, including any part already filled")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: omsctl amend [-price P] [-qty Q] ORDER_ID")
+		fs.PrintDefaults()
+	}
+	positional := parseFlags(fs, args)
+
+	if len(positional) != 1 || (*price <= 0 && *quantity <= 0) {
+		return usagef("amend requires an order ID and -price or -qty")
+	}
+	id, err := parseOrderID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.call(ctx)
+	defer cancel()
+
+	order, err := a.client.AmendOrder(ctx, id, &client.AmendOrderRequest{Price: *price, Quantity: *quantity})
+	if err != nil {
+		return err
+	}
+	return a.out.order(order)
+}
+
+// cancelResult is one order's outcome in JSON output
+type cancelResult struct {
+	OrderID  int    `json:"order_id"`
+	Canceled bool   `json:"canceled"`
+	Error    string `json:"error,omitempty"`
+}
+
+func cancelCmd(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("cancel", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: omsctl cancel ORDER_ID...")
+	}
+	positional := parseFlags(fs, args)
+	if len(positional) == 0 {
+		return usagef("cancel requires at least one order ID")
+	}
+
+	ids := make([]int, len(positional))
+	for i, arg := range positional {
+		id, err := parseOrderID(arg)
+		if err != nil {
+			return err
+		}
+		ids[i] = id
+	}
+
+	// Cancel every order even if some fail, then report the failures
+	results := make([]cancelResult, len(ids))
+	failed := 0
+	for i, id := range ids {
+		callCtx, cancel := a.call(ctx)
+		err := a.client.CancelOrder(callCtx, id)
+		cancel()
+
+		results[i] = cancelResult{OrderID: id, Canceled: err == nil}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			results[i].Error = err.Error()
+			failed++
+		}
+		if !a.out.json {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "order %d: %v\n", id, err)
+			} else {
+				fmt.Fprintf(a.out.w, "order %d canceled\n", id)
+			}
+		}
+	}
+
+	if a.out.json {
+		if err := a.out.print(results); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d cancels failed", failed, len(ids))
+	}
+	return nil
+}
+
+func orderCmd(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("order", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: omsctl order ORDER_ID")
+	}
+	positional := parseFlags(fs, args)
+	if len(positional) != 1 {
+		return usagef("order requires one order ID")
+	}
+	id, err := parseOrderID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := a.call(ctx)
+	defer cancel()
+
+	order, err := a.client.GetOrder(ctx, id)
+	if err != nil {
+		return err
+	}
+	history, err := a.client.GetOrderHistory(ctx, id)
+	if err != nil {
+		return err
+	}
+	fills, err := a.client.GetOrderFills(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if a.out.json {
+		return a.out.print(map[string]interface{}{
+			"order":   order,
+			"history": history,
+			"fills":   fills.Fills,
+		})
+	}
+
+	if err := a.out.order(order); err != nil {
+		return err
+	}
+	fmt.Fprintln(a.out.w, "\nHistory:")
+	if err := a.out.events(history); err != nil {
+		return err
+	}
+	if len(fills.Fills) > 0 {
+		fmt.Fprintln(a.out.w, "\nFills:")
+		return a.out.trades(fills.Fills)
+	}
+	return nil
+}
+
+func ordersCmd(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("orders", flag.ExitOnError)
+	var filter client.OrderFilter
+	fs.StringVar(&filter.Symbol, "symbol", "", "only orders in this symbol")
+	fs.StringVar(&filter.AccountID, "account", "", "only orders for this account")
+	side := fs.String("side", "", "only buy or sell orders")
+	status := fs.String("status", "", "only orders in this status: open, filled, canceled or rejected")
+	fs.IntVar(&filter.Limit, "limit", 100, "maximum number of orders, newest first (at most 1000)")
+	parseFlags(fs, args)
+
+	filter.Side = client.OrderSide(*side)
+	filter.Status = client.OrderStatus(*status)
+
+	ctx, cancel := a.call(ctx)
+	defer cancel()
+
+	orders, err := a.client.ListOrders(ctx, filter)
+	if err != nil {
+		return err
+	}
+	return a.out.orders(orders)
+}
+
+func bookCmd(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("book", flag.ExitOnError)
+	depth := fs.Int("depth", 10, "price levels (or orders, at level 3) per side")
+	level := fs.Int("level", 2, "2 for aggregated price levels, 3 for individual orders")
+	watch := fs.Bool("watch", false, "redraw the book on every change until interrupted")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: omsctl book [-depth N] [-level 2|3] [-watch] SYMBOL")
+		fs.PrintDefaults()
+	}
+	positional := parseFlags(fs, args)
+	if len(positional) != 1 {
+		return usagef("book requires a symbol")
+	}
+	symbol := positional[0]
+	opts := client.BookOptions{Level: client.BookLevel(*level), Depth: *depth}
+
+	if !*watch {
+		ctx, cancel := a.call(ctx)
+		defer cancel()
+
+		book, err := a.client.GetOrderBook(ctx, symbol, opts)
+		if err != nil {
+			return err
+		}
+		if a.out.json {
+			return a.out.print(book)
+		}
+		return a.out.book(book)
+	}
+
+	return a.client.SubscribeOrderBook(ctx, symbol, opts, func(book *client.OrderBook) error {
+		if a.out.json {
+			return a.out.line(book)
+		}
+		// Clear the terminal and draw from the top left
+		fmt.Fprint(a.out.w, "\033[H\033[2J")
+		return a.out.book(book)
+	})
+}
+
+func tradesCmd(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("trades", flag.ExitOnError)
+	count := fs.Int("n", 20, "number of recent trades to show")
+	follow := fs.Bool("follow", false, "keep printing trades as they happen until interrupted")
+	fs.BoolVar(follow, "f", false, "shorthand for -follow")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: omsctl trades [-n N] [-follow] [SYMBOL]")
+		fs.PrintDefaults()
+	}
+	positional := parseFlags(fs, args)
+	if len(positional) > 1 {
+		return usagef("trades takes at most one symbol")
+	}
+	var symbol string
+	if len(positional) == 1 {
+		symbol = positional[0]
+	}
+
+	callCtx, cancel := a.call(ctx)
+	trades, err := a.client.ListTrades(callCtx, symbol)
+	cancel()
+	if err != nil {
+		return err
+	}
+	if len(trades) > *count {
+		trades = trades[:*count]
+	}
+
+	if !*follow {
+		return a.out.trades(trades)
+	}
+
+	// Oldest first, so that new trades continue below
+	if !a.out.json {
+		fmt.Fprintf(a.out.w, tradeFormat, toArgs(tradeHeader)...)
+	}
+	for i := len(trades) - 1; i >= 0; i-- {
+		if err := a.out.streamTrade(trades[i]); err != nil {
+			return err
+		}
+	}
+	return a.client.SubscribeTrades(ctx, symbol, a.out.streamTrade)
+}
+
+func haltCmd(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("halt", flag.ExitOnError)
+	reason := fs.String("reason", "", "reason recorded with the halt and given to rejected orders")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: omsctl halt [-reason R] SYMBOL")
+		fs.PrintDefaults()
+	}
+	positional := parseFlags(fs, args)
+	if len(positional) != 1 {
+		return usagef("halt requires a symbol")
+	}
+
+	ctx, cancel := a.call(ctx)
+	defer cancel()
+
+	halt, err := a.client.HaltTrading(ctx, positional[0], *reason)
+	if err != nil {
+		return err
+	}
+	return a.out.halts([]*client.TradingHalt{halt})
+}
+
+func resumeCmd(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("resume", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: omsctl resume SYMBOL")
+	}
+	positional := parseFlags(fs, args)
+	if len(positional) != 1 {
+		return usagef("resume requires a symbol")
+	}
+
+	ctx, cancel := a.call(ctx)
+	defer cancel()
+
+	if err := a.client.ResumeTrading(ctx, positional[0]); err != nil {
+		return err
+	}
+	if a.out.json {
+		return a.out.print(map[string]string{"symbol": positional[0], "status": "resumed"})
+	}
+	fmt.Fprintf(a.out.w, "trading in %s resumed\n", positional[0])
+	return nil
+}
+
+func haltsCmd(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("halts", flag.ExitOnError)
+	parseFlags(fs, args)
+
+	ctx, cancel := a.call(ctx)
+	defer cancel()
+
+	halts, err := a.client.ListHalts(ctx)
+	if err != nil {
+		return err
+	}
+	return a.out.halts(halts)
+}
+
+func massCancelCmd(ctx context.Context, a *app, args []string) error {
+	fs := flag.NewFlagSet("mass-cancel", flag.ExitOnError)
+	var filter client.OrderFilter
+	fs.StringVar(&filter.Symbol, "symbol", "", "cancel orders in this symbol")
+	fs.StringVar(&filter.AccountID, "account", "", "cancel orders for this account")
+	side := fs.String("side", "", "cancel only buy or sell orders")
+	yes := fs.Bool("yes", false, "cancel without first showing how many orders match")
+	parseFlags(fs, args)
+
+	if filter.Symbol == "" && filter.AccountID == "" {
+		return usagef("mass-cancel requires -symbol or -account")
+	}
+	filter.Side = client.OrderSide(*side)
+
+	ctx, cancel := a.call(ctx)
+	defer cancel()
+
+	if !*yes {
+		preview := filter
+		preview.Status = client.Open
+		preview.Limit = 1000
+		orders, err := a.client.ListOrders(ctx, preview)
+		if err != nil {
+			return err
+		}
+		count := strconv.Itoa(len(orders))
+		if len(orders) == preview.Limit {
+			count = "at least " + count
+		}
+		return fmt.Errorf("%s open orders match; rerun with -yes to cancel them", count)
+	}
+
+	result, err := a.client.MassCancel(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if a.out.json {
+		return a.out.print(result)
+	}
+	fmt.Fprintf(a.out.w, "canceled %d orders\n", result.Canceled)
+	return nil
+}
@@ -0,0 +1,149 @@
+// Command omsctl is a command-line client for the order matching API.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"order-matching-system/pkg/client"
+)
+
+const usage = `Usage: omsctl [flags] <command> [arguments]
+
+Orders:
+  place        Place an order
+  amend        Change the price or quantity of a resting order
+  cancel       Cancel orders by ID
+  order        Show an order, its history and fills
+  orders       List orders with filters
+
+Market data:
+  book         Show the order book, or a live ladder with -watch
+  trades       List trades, or tail them with -follow
+
+Admin:
+  halt         Halt trading in a symbol
+  resume       Resume trading in a symbol
+  halts        List halted symbols
+  mass-cancel  Cancel every open order for a symbol or account
+
+Run 'omsctl <command> -h' for a command's flags.
+
+Flags:
+`
+
+// command runs one subcommand with its arguments
+type command func(ctx context.Context, app *app, args []string) error
+
+var commands = map[string]command{
+	"place":       placeCmd,
+	"amend":       amendCmd,
+	"cancel":      cancelCmd,
+	"order":       orderCmd,
+	"orders":      ordersCmd,
+	"book":        bookCmd,
+	"trades":      tradesCmd,
+	"halt":        haltCmd,
+	"resume":      resumeCmd,
+	"halts":       haltsCmd,
+	"mass-cancel": massCancelCmd,
+}
+
+// app is the state shared by every command
+type app struct {
+	client  *client.Client
+	out     *printer
+	timeout time.Duration // Deadline for one-shot requests; streams run until interrupted
+}
+
+// call returns a context for a single request
+func (a *app) call(ctx context.Context) (context.Context, context.CancelFunc) {
+	if a.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, a.timeout)
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+
+	baseURL := flag.String("url", getEnv("OMS_URL", "http://localhost:8080"), "API base URL (env OMS_URL)")
+	keyID := flag.String("key", os.Getenv("OMS_API_KEY"), "API key ID for signed requests (env OMS_API_KEY)")
+	secret := flag.String("secret", os.Getenv("OMS_API_SECRET"), "API key secret (env OMS_API_SECRET)")
+	output := flag.String("o", "table", "output format: table or json")
+	timeout := flag.Duration("timeout", 10*time.Second, "deadline for each request")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	run, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "omsctl: unknown command %q\n\n", flag.Arg(0))
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	out, err := newPrinter(os.Stdout, *output)
+	if err != nil {
+		fatal(err)
+	}
+
+	opts := []client.Option{}
+	if *keyID != "" {
+		opts = append(opts, client.WithAPIKey(*keyID, *secret))
+	}
+	c, err := client.New(*baseURL, opts...)
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = run(ctx, &app{client: c, out: out, timeout: *timeout}, flag.Args()[1:])
+	if err != nil && !(errors.Is(err, context.Canceled) && ctx.Err() != nil) {
+		stop()
+		fatal(err)
+	}
+}
+
+// fatal reports err and exits, with status 2 for usage errors
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "omsctl: %v\n", err)
+	var usageErr *usageError
+	if errors.As(err, &usageErr) {
+		os.Exit(2)
+	}
+	os.Exit(1)
+}
+
+// usageError reports arguments a command cannot run with
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string {
+	return e.msg
+}
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
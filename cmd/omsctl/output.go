@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"order-matching-system/pkg/client"
+)
+
+// printer writes command results as aligned tables or as JSON
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+func newPrinter(w io.Writer, format string) (*printer, error) {
+	switch format {
+	case "table":
+		return &printer{w: w}, nil
+	case "json":
+		return &printer{w: w, json: true}, nil
+	default:
+		return nil, usagef("unknown output format %q, expected table or json", format)
+	}
+}
+
+// print writes v as indented JSON
+func (p *printer) print(v interface{}) error {
+	enc := json.NewEncoder(p.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// line writes v as a single line of JSON, for streams
+func (p *printer) line(v interface{}) error {
+	return json.NewEncoder(p.w).Encode(v)
+}
+
+// table writes rows under header with aligned columns
+func (p *printer) table(header []string, rows [][]string) error {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+// fields writes name/value pairs, one per line
+func (p *printer) fields(pairs [][2]string) error {
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	for _, pair := range pairs {
+		if pair[1] != "" {
+			fmt.Fprintf(tw, "%s:\t%s\n", pair[0], pair[1])
+		}
+	}
+	return tw.Flush()
+}
+
+func (p *printer) orders(orders []*client.Order) error {
+	if p.json {
+		return p.print(orders)
+	}
+
+	rows := make([][]string, len(orders))
+	for i, o := range orders {
+		rows[i] = []string{
+			strconv.Itoa(o.ID), o.Symbol, string(o.Side), string(o.Type), price(o.Price),
+			num(o.InitialQuantity), num(o.FilledQuantity), num(o.RemainingQuantity),
+			string(o.Status), o.AccountID, timestamp(o.CreatedAt),
+		}
+	}
+	return p.table([]string{"ID", "SYMBOL", "SIDE", "TYPE", "PRICE", "QTY", "FILLED", "REMAINING", "STATUS", "ACCOUNT", "CREATED"}, rows)
+}
+
+func (p *printer) order(o *client.Order) error {
+	if p.json {
+		return p.print(o)
+	}
+
+	filled := num(o.FilledQuantity)
+	if o.AverageFillPrice > 0 {
+		filled += " at average " + num(o.AverageFillPrice)
+	}
+	var lastFill string
+	if o.LastFillAt != nil {
+		lastFill = num(o.LastFillPrice) + " at " + timestamp(*o.LastFillAt)
+	}
+	return p.fields([][2]string{
+		{"ID", strconv.Itoa(o.ID)},
+		{"Account", o.AccountID},
+		{"Symbol", o.Symbol},
+		{"Side", string(o.Side)},
+		{"Type", string(o.Type)},
+		{"Price", price(o.Price)},
+		{"Max slippage", price(o.MaxSlippage)},
+		{"Quantity", num(o.InitialQuantity)},
+		{"Filled", filled},
+		{"Remaining", num(o.RemainingQuantity)},
+		{"Last fill", lastFill},
+		{"Status", string(o.Status)},
+		{"Reason", o.StatusReason},
+		{"Reject code", string(o.RejectCode)},
+		{"Created", timestamp(o.CreatedAt)},
+	})
+}
+
+func (p *printer) events(events []*client.OrderEvent) error {
+	rows := make([][]string, len(events))
+	for i, e := range events {
+		var tradeID string
+		if e.TradeID != nil {
+			tradeID = strconv.Itoa(*e.TradeID)
+		}
+		rows[i] = []string{
+			timestamp(e.CreatedAt), string(e.Type), string(e.StatusAfter),
+			num(e.RemainingBefore) + " -> " + num(e.RemainingAfter), tradeID, e.Actor, e.Cause,
+		}
+	}
+	return p.table([]string{"TIME", "EVENT", "STATUS", "REMAINING", "TRADE", "ACTOR", "CAUSE"}, rows)
+}
+
+func (p *printer) trades(trades []*client.Trade) error {
+	if p.json {
+		return p.print(trades)
+	}
+
+	rows := make([][]string, len(trades))
+	for i, t := range trades {
+		rows[i] = tradeRow(t)
+	}
+	return p.table(tradeHeader, rows)
+}
+
+var tradeHeader = []string{"ID", "TIME", "SYMBOL", "PRICE", "QTY", "BUY ORDER", "SELL ORDER", "NOTE"}
+
+// tradeFormat lays out streamed trades, which cannot be aligned by a
+// tabwriter because rows arrive one at a time
+const tradeFormat = "%-8s  %-19s  %-8s  %12s  %12s  %-9s  %-10s  %s\n"
+
+func tradeRow(t *client.Trade) []string {
+	var note string
+	switch {
+	case t.Busted:
+		note = "busted"
+	case t.Corrected:
+		note = "corrected"
+	}
+	return []string{
+		strconv.Itoa(t.ID), timestamp(t.CreatedAt), t.Symbol, num(t.Price), num(t.Quantity),
+		strconv.Itoa(t.BuyOrderID), strconv.Itoa(t.SellOrderID), note,
+	}
+}
+
+// streamTrade writes one trade as it arrives
+func (p *printer) streamTrade(t *client.Trade) error {
+	if p.json {
+		return p.line(t)
+	}
+	_, err := fmt.Fprintf(p.w, tradeFormat, toArgs(tradeRow(t))...)
+	return err
+}
+
+func (p *printer) halts(halts []*client.TradingHalt) error {
+	if p.json {
+		return p.print(halts)
+	}
+
+	rows := make([][]string, len(halts))
+	for i, h := range halts {
+		rows[i] = []string{h.Symbol, timestamp(h.HaltedAt), h.HaltedBy, h.Reason}
+	}
+	return p.table([]string{"SYMBOL", "HALTED AT", "BY", "REASON"}, rows)
+}
+
+// book writes the order book as a price ladder: asks above bids, each side's
+// best price nearest the spread. Level 3 books list individual orders.
+func (p *printer) book(book *client.OrderBook) error {
+	fmt.Fprintf(p.w, "%s  %s\n\n", book.Symbol, bookSummary(book))
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	if book.Level == client.BookLevel3 {
+		fmt.Fprintln(tw, "BID ORDER\tBID QTY\tPRICE\tASK QTY\tASK ORDER\t")
+		for i := len(book.Asks) - 1; i >= 0; i-- {
+			a := book.Asks[i]
+			fmt.Fprintf(tw, "\t\t%s\t%s\t%d\t\n", num(a.Price), num(a.Quantity), a.OrderID)
+		}
+		for _, b := range book.Bids {
+			fmt.Fprintf(tw, "%d\t%s\t%s\t\t\t\n", b.OrderID, num(b.Quantity), num(b.Price))
+		}
+		return tw.Flush()
+	}
+
+	fmt.Fprintln(tw, "ORDERS\tBID QTY\tPRICE\tASK QTY\tORDERS\t")
+	for i := len(book.Asks) - 1; i >= 0; i-- {
+		a := book.Asks[i]
+		fmt.Fprintf(tw, "\t\t%s\t%s\t%d\t\n", num(a.Price), num(a.Quantity), a.Orders)
+	}
+	for _, b := range book.Bids {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t\t\t\n", b.Orders, num(b.Quantity), num(b.Price))
+	}
+	return tw.Flush()
+}
+
+func bookSummary(book *client.OrderBook) string {
+	if book.Spread == nil {
+		return "no spread"
+	}
+	return "spread " + num(*book.Spread) + "  mid " + num(*book.MidPrice)
+}
+
+// num formats a quantity or price without trailing zeros
+func num(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// price formats an optional price, leaving it blank when unset
+func price(v float64) string {
+	if v == 0 {
+		return ""
+	}
+	return num(v)
+}
+
+func timestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+func toArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
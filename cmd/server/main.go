@@ -1,18 +1,37 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 
 	"order-matching-system/internal/api"
 	"order-matching-system/internal/database"
+	"order-matching-system/internal/events"
+	"order-matching-system/internal/grpcapi"
+	"order-matching-system/internal/logging"
+	"order-matching-system/internal/metrics"
+	"order-matching-system/internal/models"
+	"order-matching-system/internal/service"
+	"order-matching-system/internal/tracing"
 
 	"github.com/joho/godotenv"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Println("Loading configuration from system environment variables")
+	envErr := godotenv.Load()
+	setupLogging()
+	if envErr != nil {
+		slog.Info("loading configuration from system environment variables")
 	}
 
 	dbConfig := database.Config{
@@ -23,28 +42,350 @@ func main() {
 		Database: getRequiredEnv("DB_NAME"),
 	}
 
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(dbConfig, os.Args[2:])
+		return
+	}
+
 	serverPort := getRequiredEnv("SERVER_PORT")
+	grpcPort := os.Getenv("GRPC_PORT")
+	shutdownTimeout := getDurationEnv("SHUTDOWN_TIMEOUT", 30*time.Second)
+	requestTimeout := getDurationEnv("REQUEST_TIMEOUT", 10*time.Second)
+	orderTimeout := getDurationEnv("ORDER_TIMEOUT", 5*time.Second)
+	snapshotInterval := getDurationEnv("SNAPSHOT_INTERVAL", time.Minute)
+	snapshotRetention := getDurationEnv("SNAPSHOT_RETENTION", 0)
+
+	costMethod := models.CostMethod(os.Getenv("POSITION_COST_METHOD"))
+	switch costMethod {
+	case "":
+		costMethod = models.CostMethodAverage
+	case models.CostMethodAverage, models.CostMethodFIFO:
+	default:
+		fatal("unknown POSITION_COST_METHOD, expected average or fifo", "value", costMethod)
+	}
+
+	shutdownTracing := setupTracing()
 
-	log.Println("Connecting to database...")
+	slog.Info("connecting to database", "host", dbConfig.Host, "port", dbConfig.Port, "database", dbConfig.Database)
 	if err := database.Initialize(dbConfig); err != nil {
-		log.Fatal("Failed to initialize database:", err)
+		fatal("failed to initialize database", "error", err)
+	}
+
+	slog.Info("database connected")
+
+	metrics.RegisterDB(database.DB)
+
+	// Background workers run until shutdown, after the engine has drained
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+
+	relay, sink := setupEventRelay()
+	if relay != nil {
+		go relay.Run(workersCtx)
+	}
+
+	var snapshotter *service.Snapshotter
+	if snapshotInterval > 0 {
+		snapshotter = service.NewSnapshotter(database.DB, snapshotInterval)
+		snapshotter.SetRetention(snapshotRetention)
+		go snapshotter.Run(workersCtx)
+	}
+
+	engine := service.NewMatchingEngine(database.DB)
+	engine.SetOrderTimeout(orderTimeout)
+	engine.SetCostMethod(costMethod)
+	engine.SetRiskLimits(service.RiskLimits{
+		MaxOrderQuantity:   getFloatEnv("RISK_MAX_ORDER_QUANTITY"),
+		MaxOrderNotional:   getFloatEnv("RISK_MAX_ORDER_NOTIONAL"),
+		PriceCollarPercent: getFloatEnv("RISK_PRICE_COLLAR_PERCENT"),
+		MaxOpenOrders:      getIntEnv("RISK_MAX_OPEN_ORDERS"),
+		MaxPosition:        getFloatEnv("RISK_MAX_POSITION"),
+		MaxDailyLoss:       getFloatEnv("RISK_MAX_DAILY_LOSS"),
+		MaxOrderRate:       getIntEnv("RISK_MAX_ORDER_RATE"),
+		OrderRateWindow:    getDurationEnv("RISK_ORDER_RATE_WINDOW", time.Second),
+	})
+
+	apiKeys := getAPIKeysEnv("API_KEYS")
+	maxClockSkew := getDurationEnv("API_MAX_CLOCK_SKEW", 5*time.Minute)
+	router := api.SetupRouter(database.DB, engine, api.Config{
+		RequestTimeout: requestTimeout,
+		APIKeys:        apiKeys,
+		MaxClockSkew:   maxClockSkew,
+		OperatorKeys:   getOperatorKeysEnv("OPERATOR_KEYS", apiKeys),
+	})
+
+	srv := &http.Server{
+		Addr:    ":" + serverPort,
+		Handler: router,
 	}
-	defer database.Close()
 
-	log.Println("Database connected successfully")
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
 
-	router := api.SetupRouter(database.DB)
+	serverErr := make(chan error, 2)
+	go func() {
+		slog.Info("starting HTTP server", "port", serverPort)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
 
-	log.Printf("Starting server on port %s...", serverPort)
-	if err := router.Run(":" + serverPort); err != nil {
-		log.Fatal("Failed to start server:", err)
+	var grpcSrv *grpc.Server
+	if grpcPort != "" {
+		lis, err := net.Listen("tcp", ":"+grpcPort)
+		if err != nil {
+			fatal("failed to listen for gRPC", "port", grpcPort, "error", err)
+		}
+		var opts []grpc.ServerOption
+		if len(apiKeys) > 0 {
+			opts = grpcapi.SignatureAuth(apiKeys, maxClockSkew)
+		}
+		grpcSrv = grpcapi.NewGRPCServer(database.DB, engine, opts...)
+		go func() {
+			slog.Info("starting gRPC server", "port", grpcPort)
+			if err := grpcSrv.Serve(lis); err != nil {
+				serverErr <- err
+			}
+		}()
+	}
+
+	select {
+	case err := <-serverErr:
+		if err != nil {
+			fatal("failed to start server", "error", err)
+		}
+	case <-ctx.Done():
+		stop()
+		slog.Info("shutdown signal received, draining in-flight orders")
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Refuse new orders first, then stop accepting connections and wait for
+	// the handlers already running to write their responses. Ending the feed
+	// first closes the HTTP and gRPC streams, which would otherwise hold both
+	// servers open until the deadline.
+	if err := engine.Shutdown(shutdownCtx); err != nil {
+		slog.Error("matching engine did not drain cleanly", "error", err)
+	}
+	engine.Feed().Close()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("HTTP server did not shut down cleanly", "error", err)
 	}
+	if grpcSrv != nil {
+		stopGRPC(shutdownCtx, grpcSrv)
+	}
+
+	// Stop background workers, then publish whatever the final orders committed
+	stopWorkers()
+	if snapshotter != nil {
+		select {
+		case <-snapshotter.Done():
+		case <-shutdownCtx.Done():
+		}
+	}
+
+	// Flushing gets a deadline of its own, so that a slow drain does not drop
+	// committed events or spans
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelFlush()
+
+	if relay != nil {
+		select {
+		case <-relay.Done():
+		case <-shutdownCtx.Done():
+		}
+		if n, err := relay.Flush(flushCtx); err != nil {
+			slog.Error("failed to flush pending events", "error", err)
+		} else if n > 0 {
+			slog.Info("flushed pending events", "count", n)
+		}
+		if err := sink.Close(); err != nil {
+			slog.Error("failed to close event sink", "error", err)
+		}
+	}
+
+	if err := database.Close(); err != nil {
+		slog.Error("failed to close database", "error", err)
+	}
+	if err := shutdownTracing(flushCtx); err != nil {
+		slog.Error("failed to flush pending spans", "error", err)
+	}
+	slog.Info("server stopped")
+}
+
+// setupEventRelay builds the outbox relay for the sink named by EVENT_SINK.
+// With no sink configured events accumulate in the outbox table until one is.
+func setupEventRelay() (*events.Relay, events.Sink) {
+	var sink events.Sink
+	switch kind := os.Getenv("EVENT_SINK"); kind {
+	case "", "none":
+		slog.Info("event relay disabled; events will remain in the outbox")
+		return nil, nil
+	case "file":
+		path := os.Getenv("EVENT_FILE")
+		if path == "" {
+			path = "events.jsonl"
+		}
+		fileSink, err := events.NewFileSink(path)
+		if err != nil {
+			fatal("failed to open event sink", "path", path, "error", err)
+		}
+		sink = fileSink
+	default:
+		fatal("unknown EVENT_SINK, expected file or none", "value", kind)
+	}
+
+	return events.NewRelay(database.NewOutboxRepository(database.DB), sink), sink
+}
+
+// setupTracing exports spans over OTLP when OTEL_EXPORTER_OTLP_ENDPOINT or
+// OTEL_EXPORTER_OTLP_TRACES_ENDPOINT is set, using the protocol named by
+// OTEL_EXPORTER_OTLP_PROTOCOL (grpc by default). The returned function
+// flushes pending spans.
+func setupTracing() func(context.Context) error {
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		slog.Info("tracing disabled")
+		return func(context.Context) error { return nil }
+	}
+
+	protocol := tracing.Protocol(os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"))
+	if protocol == "" {
+		protocol = tracing.ProtocolGRPC
+	}
+	shutdown, err := tracing.Setup(context.Background(), protocol)
+	if err != nil {
+		fatal("failed to set up tracing", "error", err)
+	}
+	slog.Info("tracing enabled", "protocol", protocol)
+	return shutdown
+}
+
+// stopGRPC drains in-flight calls, cutting them off if ctx expires first
+func stopGRPC(ctx context.Context, srv *grpc.Server) {
+	done := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Error("gRPC server did not shut down cleanly", "error", ctx.Err())
+		srv.Stop()
+	}
+}
+
+// setupLogging makes the default slog logger write LOG_FORMAT records (json
+// or text) at LOG_LEVEL and above to stderr. Output from the log package goes
+// through it too.
+func setupLogging() {
+	level := slog.LevelInfo
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		var err error
+		if level, err = logging.ParseLevel(value); err != nil {
+			fatal(err.Error())
+		}
+	}
+
+	format := logging.Format(os.Getenv("LOG_FORMAT"))
+	if format == "" {
+		format = logging.FormatJSON
+	}
+	logger, err := logging.New(os.Stderr, format, level)
+	if err != nil {
+		fatal(err.Error())
+	}
+	slog.SetDefault(logger)
+}
+
+// fatal logs msg at error level and exits
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
 }
 
 func getRequiredEnv(key string) string {
 	value := os.Getenv(key)
 	if value == "" {
-		log.Fatalf("%s environment variable is required", key)
+		fatal("required environment variable is not set", "key", key)
 	}
 	return value
 }
+
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		fatal("environment variable must be a duration such as 30s", "key", key, "error", err)
+	}
+	return d
+}
+
+// getFloatEnv reads an optional numeric limit, where unset means zero
+func getFloatEnv(key string) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		fatal("environment variable must be a non-negative number", "key", key, "value", value)
+	}
+	return f
+}
+
+// getIntEnv reads an optional count limit, where unset means zero
+func getIntEnv(key string) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		fatal("environment variable must be a non-negative integer", "key", key, "value", value)
+	}
+	return n
+}
+
+// getAPIKeysEnv reads comma-separated key_id:secret pairs, where unset leaves
+// the API open
+func getAPIKeysEnv(key string) map[string]string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	keys := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		id, secret, ok := strings.Cut(strings.TrimSpace(pair), ":")
+		if !ok || id == "" || secret == "" {
+			fatal("environment variable must be comma-separated key_id:secret pairs", "key", key)
+		}
+		keys[id] = secret
+	}
+	return keys
+}
+
+// getOperatorKeysEnv reads the comma-separated IDs of the API keys allowed
+// to use the operator routes, each of which must be one of apiKeys
+func getOperatorKeysEnv(key string, apiKeys map[string]string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		id = strings.TrimSpace(id)
+		if _, ok := apiKeys[id]; !ok {
+			fatal("operator key is not one of API_KEYS", "key", key, "key_id", id)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"text/tabwriter"
+
+	"order-matching-system/internal/database"
+)
+
+// runMigrate implements `server migrate up|down|status`
+func runMigrate(cfg database.Config, args []string) {
+	if len(args) != 1 {
+		fatal("usage: server migrate up|down|status")
+	}
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		fatal("failed to connect to database", "error", err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		ran, err := database.MigrateUp(ctx, db)
+		for _, m := range ran {
+			slog.Info("applied migration", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			fatal("migration failed", "error", err)
+		}
+		if len(ran) == 0 {
+			slog.Info("schema is up to date")
+		}
+	case "down":
+		m, err := database.MigrateDown(ctx, db)
+		if err != nil {
+			fatal("migration failed", "error", err)
+		}
+		if m == nil {
+			slog.Info("no migrations to revert")
+			return
+		}
+		slog.Info("reverted migration", "version", m.Version, "name", m.Name)
+	case "status":
+		states, err := database.MigrationStatus(ctx, db)
+		if err != nil {
+			fatal("failed to get migration status", "error", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, state := range states {
+			appliedAt := "pending"
+			if state.AppliedAt != nil {
+				appliedAt = state.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", state.Version, state.Name, appliedAt)
+		}
+		w.Flush()
+	default:
+		fatal("unknown migrate command, expected up, down or status", "command", args[0])
+	}
+}
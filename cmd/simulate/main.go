@@ -0,0 +1,159 @@
+// Command simulate runs the matching engine against agent-based trading bots
+// over simulated time, on an in-memory store, and writes the trades, book
+// snapshots and per-agent PnL as CSV files for analysis.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"order-matching-system/internal/simulator"
+)
+
+func main() {
+	symbol := flag.String("symbol", "SIM", "symbol traded in the simulation")
+	steps := flag.Int("steps", 10000, "number of simulated steps")
+	step := flag.Duration("step", time.Second, "simulated time between steps")
+	start := flag.String("start", "2024-01-02T09:30:00Z", "simulated time of the first step, RFC 3339")
+	price := flag.Float64("price", 100, "initial fair value")
+	volatility := flag.Float64("volatility", 0.0005, "standard deviation of the fair value's log return per step")
+	tick := flag.Float64("tick", 0.01, "tick size")
+	seed := flag.Int64("seed", 1, "random seed; runs with the same flags are identical")
+	snapshotEvery := flag.Int("snapshot-every", 100, "steps between book and PnL snapshots")
+	depth := flag.Int("depth", 5, "price levels per side in book snapshots")
+	out := flag.String("out", "simulation", "directory the CSV files are written to")
+
+	noise := flag.Int("noise", 20, "number of noise traders")
+	noiseRate := flag.Float64("noise-rate", 0.2, "chance a noise trader places an order each step")
+	noiseMarketRatio := flag.Float64("noise-market-ratio", 0.2, "share of noise orders that are market orders")
+	noiseOffset := flag.Int("noise-offset", 20, "ticks either side of fair value noise limit orders are priced within")
+	noiseMaxQty := flag.Float64("noise-max-qty", 20, "largest noise order quantity")
+	noiseLifetime := flag.Int("noise-lifetime", 50, "steps before a resting noise order is canceled")
+
+	makers := flag.Int("makers", 2, "number of market makers")
+	makerSpread := flag.Int("maker-half-spread", 5, "ticks from fair value market makers quote")
+	makerSize := flag.Float64("maker-size", 10, "quantity market makers quote on each side")
+	makerInventory := flag.Float64("maker-max-inventory", 200, "absolute position beyond which market makers stop quoting a side")
+	makerSkew := flag.Float64("maker-skew", 5, "ticks market makers shade their quotes at full inventory")
+
+	momentum := flag.Int("momentum", 3, "number of momentum traders")
+	momentumShort := flag.Int("momentum-short", 10, "steps in the momentum traders' short average")
+	momentumLong := flag.Int("momentum-long", 50, "steps in the momentum traders' long average")
+	momentumThreshold := flag.Float64("momentum-threshold", 0.001, "relative gap between the averages needed to trade")
+	momentumSize := flag.Float64("momentum-size", 5, "quantity of each momentum order")
+	momentumMax := flag.Float64("momentum-max-position", 100, "largest absolute position of a momentum trader")
+	flag.Parse()
+
+	// The engine logs every order it handles; only its failures matter here
+	slog.SetLogLoggerLevel(slog.LevelError)
+
+	startTime, err := time.Parse(time.RFC3339, *start)
+	if err != nil {
+		log.Fatalf("invalid -start: %v", err)
+	}
+	if *momentumShort < 1 || *momentumShort > *momentumLong {
+		log.Fatal("-momentum-short must be between 1 and -momentum-long")
+	}
+
+	var agents []simulator.Agent
+	for i := 1; i <= *noise; i++ {
+		a := simulator.NewNoiseTrader(fmt.Sprintf("noise-%d", i))
+		a.Rate = *noiseRate
+		a.MarketRatio = *noiseMarketRatio
+		a.MaxOffset = *noiseOffset
+		a.MaxQuantity = *noiseMaxQty
+		a.Lifetime = *noiseLifetime
+		agents = append(agents, a)
+	}
+	for i := 1; i <= *makers; i++ {
+		a := simulator.NewMarketMaker(fmt.Sprintf("maker-%d", i))
+		a.HalfSpread = *makerSpread
+		a.Size = *makerSize
+		a.MaxInventory = *makerInventory
+		a.SkewTicks = *makerSkew
+		agents = append(agents, a)
+	}
+	for i := 1; i <= *momentum; i++ {
+		a := simulator.NewMomentumTrader(fmt.Sprintf("momentum-%d", i))
+		a.Short = *momentumShort
+		a.Long = *momentumLong
+		a.Threshold = *momentumThreshold
+		a.Size = *momentumSize
+		a.MaxPosition = *momentumMax
+		agents = append(agents, a)
+	}
+
+	sim, err := simulator.New(simulator.Config{
+		Symbol:        *symbol,
+		Start:         startTime,
+		Steps:         *steps,
+		StepDuration:  *step,
+		InitialPrice:  *price,
+		Volatility:    *volatility,
+		TickSize:      *tick,
+		SnapshotEvery: *snapshotEvery,
+		BookDepth:     *depth,
+		Seed:          *seed,
+	}, agents...)
+	if err != nil {
+		log.Fatalf("invalid simulation: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Simulating %d steps of %s with %d agents (seed %d)", *steps, *symbol, len(agents), *seed)
+	began := time.Now()
+	result, err := sim.Run(ctx)
+	if err != nil {
+		log.Fatalf("simulation failed: %v", err)
+	}
+	log.Printf("Simulation finished in %v", time.Since(began).Round(time.Millisecond))
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		log.Fatalf("failed to create output directory: %v", err)
+	}
+	for name, write := range map[string]func(io.Writer, *simulator.Result) error{
+		"trades.csv": writeTrades,
+		"book.csv":   writeBook,
+		"pnl.csv":    writePnL,
+	} {
+		if err := writeFile(filepath.Join(*out, name), result, write); err != nil {
+			log.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	writeSummary(os.Stdout, result)
+	fmt.Printf("\nwrote trades.csv, book.csv and pnl.csv to %s\n", *out)
+}
+
+// writeSummary prints the final PnL of every agent, best first
+func writeSummary(w io.Writer, result *simulator.Result) {
+	var volume float64
+	for _, trade := range result.Trades {
+		volume += trade.Quantity
+	}
+	fmt.Fprintf(w, "\n%d trades, volume %g\n\n", len(result.Trades), volume)
+
+	agents := append([]*simulator.AgentPnL(nil), result.Agents...)
+	sort.SliceStable(agents, func(i, j int) bool { return agents[i].TotalPnL > agents[j].TotalPnL })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "AGENT\tORDERS\tTRADES\tVOLUME\tPOSITION\tREALIZED\tUNREALIZED\tTOTAL\t")
+	for _, a := range agents {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%g\t%g\t%.2f\t%.2f\t%.2f\t\n",
+			a.Agent, a.Orders, a.Trades, a.Volume, a.Position, a.RealizedPnL, a.UnrealizedPnL, a.TotalPnL)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"order-matching-system/internal/models"
+	"order-matching-system/internal/simulator"
+)
+
+func writeFile(path string, result *simulator.Result, write func(io.Writer, *simulator.Result) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f, result); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeTrades writes one row per trade
+func writeTrades(w io.Writer, result *simulator.Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"step", "time", "trade_id", "price", "quantity", "buyer", "seller", "buy_order_id", "sell_order_id"})
+	for _, t := range result.Trades {
+		cw.Write([]string{
+			strconv.Itoa(t.Step), formatTime(t.CreatedAt), strconv.Itoa(t.ID),
+			formatFloat(t.Price), formatFloat(t.Quantity), t.Buyer, t.Seller,
+			strconv.Itoa(t.BuyOrderID), strconv.Itoa(t.SellOrderID),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeBook writes one row per price level of every snapshot, bids then asks,
+// with level 1 at the top of the book
+func writeBook(w io.Writer, result *simulator.Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"step", "time", "fair_value", "last_price", "side", "level", "price", "quantity", "orders"})
+	for _, s := range result.Snapshots {
+		for _, side := range []struct {
+			name   string
+			levels []models.OrderBookEntry
+		}{{"buy", s.Book.Bids}, {"sell", s.Book.Asks}} {
+			for i, level := range side.levels {
+				cw.Write([]string{
+					strconv.Itoa(s.Step), formatTime(s.Time), formatFloat(s.FairValue), formatFloat(s.LastPrice),
+					side.name, strconv.Itoa(i + 1), formatFloat(level.Price), formatFloat(level.Quantity), strconv.Itoa(level.Orders),
+				})
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writePnL writes one row per agent for every snapshot
+func writePnL(w io.Writer, result *simulator.Result) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"step", "time", "agent", "position", "average_cost", "realized_pnl", "unrealized_pnl", "total_pnl", "orders", "trades", "volume"})
+	for _, s := range result.Snapshots {
+		for _, a := range s.PnL {
+			cw.Write([]string{
+				strconv.Itoa(s.Step), formatTime(s.Time), a.Agent, formatFloat(a.Position), formatFloat(a.AverageCost),
+				formatFloat(a.RealizedPnL), formatFloat(a.UnrealizedPnL), formatFloat(a.TotalPnL),
+				strconv.Itoa(a.Orders), strconv.Itoa(a.Trades), formatFloat(a.Volume),
+			})
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
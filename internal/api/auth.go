@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"order-matching-system/pkg/signing"
+)
+
+// keyIDContextKey holds the ID of the API key a request was signed with
+const keyIDContextKey = "api_key_id"
+
+// SignatureAuth rejects requests that are not signed with one of keys, a map
+// of key ID to secret, or whose timestamp is more than maxSkew from now. See
+// package signing for the signature scheme.
+func SignatureAuth(keys map[string]string, maxSkew time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secret, ok := keys[c.GetHeader(signing.HeaderKeyID)]
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unknown or missing API key"})
+			return
+		}
+
+		timestamp, err := strconv.ParseInt(c.GetHeader(signing.HeaderTimestamp), 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing timestamp"})
+			return
+		}
+		if skew := time.Since(time.Unix(timestamp, 0)); skew > maxSkew || skew < -maxSkew {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "request timestamp is too far from server time"})
+			return
+		}
+
+		// Read the body for hashing and put it back for the handler
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		signature := c.GetHeader(signing.HeaderSignature)
+		if !signing.Verify(secret, signature, timestamp, c.Request.Method, c.Request.URL.RequestURI(), body) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid signature"})
+			return
+		}
+
+		c.Set(keyIDContextKey, c.GetHeader(signing.HeaderKeyID))
+		c.Next()
+	}
+}
+
+// requireOperator refuses requests not signed with one of the operator key
+// IDs. With no operator keys every request is refused.
+func requireOperator(keyIDs []string) gin.HandlerFunc {
+	operators := make(map[string]bool, len(keyIDs))
+	for _, id := range keyIDs {
+		operators[id] = true
+	}
+	return func(c *gin.Context) {
+		if !operators[c.GetString(keyIDContextKey)] {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "operator API key required"})
+			return
+		}
+		c.Next()
+	}
+}
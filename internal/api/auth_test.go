@@ -0,0 +1,93 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+	"order-matching-system/internal/service"
+	"order-matching-system/pkg/signing"
+)
+
+func signedRequest(keyID, secret, method, path, body string) *http.Request {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	timestamp := time.Now().Unix()
+	req.Header.Set(signing.HeaderKeyID, keyID)
+	req.Header.Set(signing.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(signing.HeaderSignature, signing.Sign(secret, timestamp, method, path, []byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestAdminRequiresOperator(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := service.NewMatchingEngineWithStore(memory.NewStore(nil))
+	router := SetupRouter(nil, engine, Config{
+		APIKeys:      map[string]string{"desk": "desk-secret", "ops": "ops-secret"},
+		MaxClockSkew: time.Minute,
+		OperatorKeys: []string{"ops"},
+	})
+
+	const path = "/admin/symbols/HALT/halt"
+	const body = `{"reason":"pending news"}`
+	tests := []struct {
+		name string
+		req  *http.Request
+		want int
+	}{
+		{"unsigned", httptest.NewRequest(http.MethodPost, path, strings.NewReader(body)), http.StatusUnauthorized},
+		{"client key", signedRequest("desk", "desk-secret", http.MethodPost, path, body), http.StatusForbidden},
+		{"forged actor", func() *http.Request {
+			req := signedRequest("desk", "desk-secret", http.MethodPost, path, body)
+			req.Header.Set("X-Actor", "ops")
+			return req
+		}(), http.StatusForbidden},
+		{"operator key", signedRequest("ops", "ops-secret", http.MethodPost, path, body), http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, tt.req)
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+
+	// Instrument configuration is an operator tool too
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest("desk", "desk-secret", http.MethodPut, "/admin/instruments/ESZ5", `{"lmm_percentage":20}`))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("client key configured an instrument: status %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, signedRequest("ops", "ops-secret", http.MethodPost, "/admin/symbols/OTHER/halt", body))
+	var halt models.TradingHalt
+	if err := json.Unmarshal(w.Body.Bytes(), &halt); err != nil {
+		t.Fatal(err)
+	}
+	if halt.HaltedBy != "ops" {
+		t.Fatalf("halted by %q, want the operator key ID", halt.HaltedBy)
+	}
+}
+
+func TestAdminClosedWithoutOperatorKeys(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := service.NewMatchingEngineWithStore(memory.NewStore(nil))
+	router := SetupRouter(nil, engine, Config{})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/symbols/HALT/halt", nil))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("status %d, want 403", w.Code)
+	}
+}
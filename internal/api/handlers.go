@@ -1,9 +1,14 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
@@ -13,18 +18,31 @@ import (
 )
 
 type Handler struct {
+	db             *sql.DB
 	orderRepo      *database.OrderRepository
 	tradeRepo      *database.TradeRepository
 	orderBookRepo  *database.OrderBookRepository
+	candleRepo     *database.CandleRepository
+	eventRepo      *database.OrderEventRepository
+	instrumentRepo *database.InstrumentRepository
+	positionRepo   *database.PositionRepository
+	haltRepo       *database.HaltRepository
 	matchingEngine *service.MatchingEngine
 }
 
-func NewHandler(db *sql.DB) *Handler {
+func NewHandler(db *sql.DB, engine *service.MatchingEngine) *Handler {
+	dbtx := database.Instrument(db)
 	return &Handler{
-		orderRepo:      database.NewOrderRepository(db),
-		tradeRepo:      database.NewTradeRepository(db),
-		orderBookRepo:  database.NewOrderBookRepository(db),
-		matchingEngine: service.NewMatchingEngine(db),
+		db:             db,
+		orderRepo:      database.NewOrderRepository(dbtx),
+		tradeRepo:      database.NewTradeRepository(dbtx),
+		orderBookRepo:  database.NewOrderBookRepository(dbtx),
+		candleRepo:     database.NewCandleRepository(dbtx),
+		eventRepo:      database.NewOrderEventRepository(dbtx),
+		instrumentRepo: database.NewInstrumentRepository(dbtx),
+		positionRepo:   database.NewPositionRepository(dbtx),
+		haltRepo:       database.NewHaltRepository(dbtx),
+		matchingEngine: engine,
 	}
 }
 
@@ -35,30 +53,42 @@ func (h *Handler) PlaceOrder(c *gin.Context) {
 		return
 	}
 
-	// Validate price for limit orders
-	if req.Type == models.OrderTypeLimit && req.Price <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "price must be greater than 0 for limit orders"})
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Validate quantity
-	if req.Quantity <= 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "quantity must be greater than 0"})
+	// Create order
+	order := req.NewOrder()
+	order.IdempotencyKey = c.GetHeader("Idempotency-Key")
+	if len(order.IdempotencyKey) > 64 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be at most 64 characters"})
 		return
 	}
 
-	// Create order
-	order := &models.Order{
-		Symbol:          req.Symbol,
-		Side:            req.Side,
-		Type:            req.Type,
-		Price:           req.Price,
-		InitialQuantity: req.Quantity,
+	// Process order through matching engine
+	err := h.matchingEngine.ProcessOrder(c.Request.Context(), order)
+	switch {
+	case errors.Is(err, service.ErrDuplicateOrder):
+		// Answer a retry as the original request was answered
+		c.Header("Idempotent-Replayed", "true")
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrEngineStopped):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		writeServerError(c, err)
+		return
 	}
 
-	// Process order through matching engine
-	if err := h.matchingEngine.ProcessOrder(order); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if order.Status == models.OrderStatusRejected {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error": order.StatusReason,
+			"code":  order.RejectCode,
+			"order": order,
+		})
 		return
 	}
 
@@ -74,18 +104,82 @@ func (h *Handler) CancelOrder(c *gin.Context) {
 	}
 
 	// Cancel order
-	if err := h.orderRepo.CancelOrder(orderID); err != nil {
-		if err.Error() == "order not found or already filled/canceled" {
+	if _, err := h.matchingEngine.CancelOrder(c.Request.Context(), orderID, requestActor(c)); err != nil {
+		if errors.Is(err, service.ErrOrderNotCancelable) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServerError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "order canceled successfully"})
 }
 
+func (h *Handler) AmendOrder(c *gin.Context) {
+	orderID, err := strconv.Atoi(c.Param("orderId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	var req models.AmendOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	order, err := h.matchingEngine.AmendOrder(c.Request.Context(), orderID, req, requestActor(c))
+	var rejected *service.AmendRejectedError
+	switch {
+	case errors.As(err, &rejected):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": rejected.Reason, "code": rejected.Code})
+		return
+	case errors.Is(err, service.ErrOrderNotAmendable):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrInvalidAmendment):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrTradingHalted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrEngineStopped):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ListOrders returns orders newest first, filtered by the symbol, account_id,
+// side and status query parameters, up to limit (default 100)
+func (h *Handler) ListOrders(c *gin.Context) {
+	var filter models.OrderFilter
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.Limit == 0 {
+		filter.Limit = 100
+	}
+
+	orders, err := h.orderRepo.ListOrders(c.Request.Context(), filter)
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
 func (h *Handler) GetOrderBook(c *gin.Context) {
 	symbol := c.Query("symbol")
 	if symbol == "" {
@@ -93,15 +187,51 @@ func (h *Handler) GetOrderBook(c *gin.Context) {
 		return
 	}
 
-	orderBook, err := h.orderBookRepo.GetOrderBook(symbol)
+	level, depth, ok := bookParams(c)
+	if !ok {
+		return
+	}
+
+	orderBook, err := h.orderBookRepo.GetOrderBook(c.Request.Context(), symbol, level, depth)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServerError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, orderBook)
 }
 
+func (h *Handler) GetOrderBookAt(c *gin.Context) {
+	symbol := c.Query("symbol")
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol parameter is required"})
+		return
+	}
+
+	atStr := c.Query("at")
+	if atStr == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at parameter is required"})
+		return
+	}
+	at, err := parseTimeParam(atStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid at parameter"})
+		return
+	}
+
+	level, depth, ok := bookParams(c)
+	if !ok {
+		return
+	}
+
+	orderBook, err := service.ReconstructBook(c.Request.Context(), h.db, symbol, at, level, depth)
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orderBook)
+}
 
 func (h *Handler) ListTrades(c *gin.Context) {
 	symbol := c.Query("symbol")
@@ -110,13 +240,13 @@ func (h *Handler) ListTrades(c *gin.Context) {
 	var err error
 
 	if symbol != "" {
-		trades, err = h.tradeRepo.GetTradesBySymbol(symbol)
+		trades, err = h.tradeRepo.GetTradesBySymbol(c.Request.Context(), symbol)
 	} else {
-		trades, err = h.tradeRepo.GetAllTrades()
+		trades, err = h.tradeRepo.GetAllTrades(c.Request.Context())
 	}
 
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServerError(c, err)
 		return
 	}
 
@@ -131,15 +261,448 @@ func (h *Handler) GetOrderStatus(c *gin.Context) {
 		return
 	}
 
-	order, err := h.orderRepo.GetOrderByID(orderID)
+	order, err := h.orderRepo.GetOrderByID(c.Request.Context(), orderID)
 	if err != nil {
-		if err.Error() == "order not found" {
+		if errors.Is(err, database.ErrOrderNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		writeServerError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, order)
 }
+
+func (h *Handler) GetOrderHistory(c *gin.Context) {
+	orderIDStr := c.Param("orderId")
+	orderID, err := strconv.Atoi(orderIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	if _, err := h.orderRepo.GetOrderByID(c.Request.Context(), orderID); err != nil {
+		if errors.Is(err, database.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		writeServerError(c, err)
+		return
+	}
+
+	events, err := h.eventRepo.GetEventsByOrderID(c.Request.Context(), orderID)
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
+
+func (h *Handler) GetOrderFills(c *gin.Context) {
+	orderIDStr := c.Param("orderId")
+	orderID, err := strconv.Atoi(orderIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid order ID"})
+		return
+	}
+
+	order, err := h.orderRepo.GetOrderByID(c.Request.Context(), orderID)
+	if err != nil {
+		if errors.Is(err, database.ErrOrderNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		writeServerError(c, err)
+		return
+	}
+
+	trades, err := h.tradeRepo.GetTradesByOrderID(c.Request.Context(), orderID)
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.OrderFills{
+		OrderID:          order.ID,
+		FilledQuantity:   order.FilledQuantity,
+		AverageFillPrice: order.AverageFillPrice,
+		Fills:            trades,
+	})
+}
+
+func (h *Handler) GetCandles(c *gin.Context) {
+	symbol := c.Query("symbol")
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol parameter is required"})
+		return
+	}
+
+	interval := models.CandleInterval(c.DefaultQuery("interval", string(models.CandleInterval1m)))
+	if _, ok := interval.Duration(); !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "interval must be one of 1m, 5m, 1h, 1d"})
+		return
+	}
+
+	to := time.Now()
+	if toStr := c.Query("to"); toStr != "" {
+		t, err := parseTimeParam(toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to parameter"})
+			return
+		}
+		to = t
+	}
+
+	from := to.Add(-24 * time.Hour)
+	if fromStr := c.Query("from"); fromStr != "" {
+		t, err := parseTimeParam(fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from parameter"})
+			return
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to"})
+		return
+	}
+
+	candles, err := h.candleRepo.GetCandles(c.Request.Context(), symbol, interval, from, to)
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, candles)
+}
+
+func (h *Handler) GetTicker(c *gin.Context) {
+	var symbols []string
+	if symbol := c.Query("symbol"); symbol != "" {
+		symbols = []string{symbol}
+	} else {
+		var err error
+		symbols, err = h.tradeRepo.GetSymbols(c.Request.Context())
+		if err != nil {
+			writeServerError(c, err)
+			return
+		}
+	}
+
+	since := time.Now().Add(-24 * time.Hour)
+	tickers := []*models.Ticker{}
+	for _, symbol := range symbols {
+		ticker, err := h.candleRepo.GetTicker(c.Request.Context(), symbol, since)
+		if err != nil {
+			writeServerError(c, err)
+			return
+		}
+		if ticker == nil {
+			continue
+		}
+
+		top, err := h.orderBookRepo.GetOrderBook(c.Request.Context(), symbol, models.BookLevel1, 1)
+		if err != nil {
+			writeServerError(c, err)
+			return
+		}
+		if len(top.Bids) > 0 {
+			ticker.BestBid = &top.Bids[0].Price
+		}
+		if len(top.Asks) > 0 {
+			ticker.BestAsk = &top.Asks[0].Price
+		}
+
+		tickers = append(tickers, ticker)
+	}
+
+	c.JSON(http.StatusOK, tickers)
+}
+
+func (h *Handler) ListInstruments(c *gin.Context) {
+	instruments, err := h.instrumentRepo.GetAllInstruments(c.Request.Context())
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, instruments)
+}
+
+func (h *Handler) GetInstrument(c *gin.Context) {
+	instrument, err := h.instrumentRepo.GetInstrument(c.Request.Context(), c.Param("symbol"))
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, instrument)
+}
+
+func (h *Handler) SaveInstrument(c *gin.Context) {
+	var instrument models.Instrument
+	if err := c.ShouldBindJSON(&instrument); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	instrument.Symbol = c.Param("symbol")
+
+	defaults := models.DefaultInstrument(instrument.Symbol)
+	if instrument.Algorithm == "" {
+		instrument.Algorithm = defaults.Algorithm
+	}
+	if instrument.LotSize == 0 {
+		instrument.LotSize = defaults.LotSize
+	}
+	if instrument.TickSize == 0 {
+		instrument.TickSize = defaults.TickSize
+	}
+
+	if instrument.LMMPercentage < 0 || instrument.LMMPercentage > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lmm_percentage must be between 0 and 100"})
+		return
+	}
+	if instrument.ProtectionBandPercent < 0 || instrument.ProtectionBandTicks < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "protection bands must not be negative"})
+		return
+	}
+
+	// An LMM allocation needs to know who the LMM is
+	if instrument.Algorithm == models.MatchingFIFOLMM && instrument.LMMAccountID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lmm_account_id is required for fifo_lmm matching"})
+		return
+	}
+
+	if err := h.instrumentRepo.SaveInstrument(c.Request.Context(), &instrument); err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	saved, err := h.instrumentRepo.GetInstrument(c.Request.Context(), instrument.Symbol)
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+func (h *Handler) BustTrade(c *gin.Context) {
+	tradeID, err := strconv.Atoi(c.Param("tradeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid trade ID"})
+		return
+	}
+
+	var req models.BustTradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trade, correction, err := h.matchingEngine.BustTrade(c.Request.Context(), tradeID, req.Reason, requestActor(c))
+	if err != nil {
+		writeTradeCorrectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trade": trade, "correction": correction})
+}
+
+func (h *Handler) CorrectTrade(c *gin.Context) {
+	tradeID, err := strconv.Atoi(c.Param("tradeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid trade ID"})
+		return
+	}
+
+	var req models.CorrectTradeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Price == 0 && req.Quantity == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "price or quantity is required"})
+		return
+	}
+
+	trade, correction, err := h.matchingEngine.CorrectTrade(c.Request.Context(), tradeID, req.Price, req.Quantity, req.Reason, requestActor(c))
+	if err != nil {
+		writeTradeCorrectionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"trade": trade, "correction": correction})
+}
+
+func (h *Handler) GetTradeCorrections(c *gin.Context) {
+	tradeID, err := strconv.Atoi(c.Param("tradeId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid trade ID"})
+		return
+	}
+
+	if _, err := h.tradeRepo.GetTradeByID(c.Request.Context(), tradeID); err != nil {
+		if errors.Is(err, database.ErrTradeNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "trade not found"})
+			return
+		}
+		writeServerError(c, err)
+		return
+	}
+
+	corrections, err := h.tradeRepo.GetCorrectionsByTradeID(c.Request.Context(), tradeID)
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, corrections)
+}
+
+func (h *Handler) ListHalts(c *gin.Context) {
+	halts, err := h.haltRepo.GetAllHalts(c.Request.Context())
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, halts)
+}
+
+func (h *Handler) HaltTrading(c *gin.Context) {
+	var req models.HaltRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	halt, err := h.matchingEngine.HaltTrading(c.Request.Context(), c.Param("symbol"), req.Reason, requestActor(c))
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, halt)
+}
+
+func (h *Handler) ResumeTrading(c *gin.Context) {
+	if err := h.matchingEngine.ResumeTrading(c.Request.Context(), c.Param("symbol")); err != nil {
+		if errors.Is(err, service.ErrNotHalted) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "trading resumed"})
+}
+
+// MassCancel cancels every open order matching the symbol, account_id and
+// side in the body. A symbol or account is required so that a mistaken
+// empty request cannot clear every book.
+func (h *Handler) MassCancel(c *gin.Context) {
+	var filter models.OrderFilter
+	if err := c.ShouldBindJSON(&filter); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.Symbol == "" && filter.AccountID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol or account_id is required"})
+		return
+	}
+
+	result, err := h.matchingEngine.MassCancel(c.Request.Context(), filter, requestActor(c))
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) GetAccountPositions(c *gin.Context) {
+	positions, err := h.positionRepo.GetPositionsByAccount(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		writeServerError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, positions)
+}
+
+// parseTimeParam accepts either an RFC 3339 timestamp or Unix seconds
+func parseTimeParam(value string) (time.Time, error) {
+	if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// writeServerError reports an unexpected failure, distinguishing requests
+// that ran out of time from other internal errors
+func writeServerError(c *gin.Context, err error) {
+	c.Error(err)
+	if errors.Is(err, context.DeadlineExceeded) {
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "request timed out"})
+		return
+	}
+	if errors.Is(err, service.ErrEngineStopped) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// writeTradeCorrectionError maps bust and correction failures to responses
+func writeTradeCorrectionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrTradeNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrTradeBusted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidCorrection):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		writeServerError(c, err)
+	}
+}
+
+// requestActor identifies who initiated a request for the audit trail: the
+// ID of the API key it was signed with, if any
+func requestActor(c *gin.Context) string {
+	if keyID := c.GetString(keyIDContextKey); keyID != "" {
+		return keyID
+	}
+	return models.ActorAPI
+}
+
+// bookParams parses the level and depth query parameters, writing a 400
+// response and returning false if either is invalid
+func bookParams(c *gin.Context) (models.BookLevel, int, bool) {
+	level := models.BookLevel2
+	if levelStr := c.Query("level"); levelStr != "" {
+		n, err := strconv.Atoi(levelStr)
+		if err != nil || n < int(models.BookLevel1) || n > int(models.BookLevel3) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "level must be 1, 2 or 3"})
+			return 0, 0, false
+		}
+		level = models.BookLevel(n)
+	}
+
+	depth := models.DefaultBookDepth
+	if depthStr := c.Query("depth"); depthStr != "" {
+		n, err := strconv.Atoi(depthStr)
+		if err != nil || n <= 0 || n > models.MaxBookDepth {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("depth must be between 1 and %d", models.MaxBookDepth)})
+			return 0, 0, false
+		}
+		depth = n
+	}
+
+	return level, depth, true
+}
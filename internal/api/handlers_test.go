@@ -0,0 +1,162 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+	"order-matching-system/internal/service"
+)
+
+func TestOrderEntryLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := service.NewMatchingEngineWithStore(memory.NewStore(nil))
+	resting := (&models.PlaceOrderRequest{Symbol: "VAL", Side: models.OrderSideBuy, Type: models.OrderTypeLimit, Price: 100, Quantity: 1}).NewOrder()
+	if err := engine.ProcessOrder(context.Background(), resting); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := NewHandler(nil, engine)
+	router := gin.New()
+	router.POST("/orders", handler.PlaceOrder)
+	router.PATCH("/orders/:orderId", handler.AmendOrder)
+
+	amendPath := "/orders/" + strconv.Itoa(resting.ID)
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		want   int
+		errMsg string
+	}{
+		{"eight decimals", http.MethodPost, "/orders", `{"symbol":"VAL","side":"sell","type":"limit","price":200,"quantity":0.12345678}`, http.StatusCreated, ""},
+		{"nine decimals", http.MethodPost, "/orders", `{"symbol":"VAL","side":"sell","type":"limit","price":200,"quantity":0.123456789}`, http.StatusBadRequest, "8 decimal places"},
+		{"below one unit", http.MethodPost, "/orders", `{"symbol":"VAL","side":"sell","type":"market","quantity":1e-9}`, http.StatusBadRequest, "8 decimal places"},
+		{"quantity too large", http.MethodPost, "/orders", `{"symbol":"VAL","side":"sell","type":"limit","price":200,"quantity":1e10}`, http.StatusBadRequest, "less than 10000000000"},
+		{"price too large", http.MethodPost, "/orders", `{"symbol":"VAL","side":"sell","type":"limit","price":1e10,"quantity":1}`, http.StatusBadRequest, "less than 10000000000"},
+		{"slippage too large", http.MethodPost, "/orders", `{"symbol":"VAL","side":"sell","type":"market","quantity":1,"max_slippage":1e10}`, http.StatusBadRequest, "less than 10000000000"},
+		{"amend nine decimals", http.MethodPatch, amendPath, `{"quantity":2.000000001}`, http.StatusBadRequest, "8 decimal places"},
+		{"amend price too large", http.MethodPatch, amendPath, `{"price":1e10}`, http.StatusBadRequest, "less than 10000000000"},
+		{"amend eight decimals", http.MethodPatch, amendPath, `{"quantity":2.00000001}`, http.StatusOK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if tt.errMsg != "" && !strings.Contains(w.Body.String(), tt.errMsg) {
+				t.Fatalf("body %s, want an error mentioning %q", w.Body.String(), tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestSaveInstrumentValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := NewHandler(nil, service.NewMatchingEngineWithStore(memory.NewStore(nil)))
+	router := gin.New()
+	router.PUT("/admin/instruments/:symbol", handler.SaveInstrument)
+
+	tests := []struct {
+		name   string
+		body   string
+		errMsg string
+	}{
+		{"lmm percentage above 100", `{"matching_algorithm":"fifo_lmm","lmm_account_id":"mm","lmm_percentage":101}`, "LMMPercentage"},
+		{"negative lmm percentage", `{"matching_algorithm":"fifo_lmm","lmm_account_id":"mm","lmm_percentage":-1}`, "LMMPercentage"},
+		{"negative band percent", `{"protection_band_percent":-5}`, "ProtectionBandPercent"},
+		{"negative band ticks", `{"protection_band_ticks":-1}`, "ProtectionBandTicks"},
+		{"lmm without account", `{"matching_algorithm":"fifo_lmm","lmm_percentage":20}`, "lmm_account_id"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/admin/instruments/VAL", strings.NewReader(tt.body)))
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status %d, want 400: %s", w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.errMsg) {
+				t.Fatalf("body %s, want an error mentioning %q", w.Body.String(), tt.errMsg)
+			}
+		})
+	}
+}
+
+// FuzzPlaceOrder posts arbitrary bodies to the order entry handler, backed by
+// an in-memory store holding a small book, and checks that every body is
+// either refused as a client error or produces a consistent order
+func FuzzPlaceOrder(f *testing.F) {
+	f.Add(`{"symbol":"FUZZ","side":"buy","type":"limit","price":101,"quantity":3}`)
+	f.Add(`{"symbol":"FUZZ","side":"sell","type":"market","quantity":2.5,"max_slippage":1}`)
+	f.Add(`{"symbol":"FUZZ","side":"buy","type":"market","quantity":100}`)
+	f.Add(`{"symbol":"FUZZ","side":"sell","type":"limit","price":0,"quantity":1}`)
+	f.Add(`{"symbol":"FUZZ","side":"hold","type":"limit","price":1,"quantity":1}`)
+	f.Add(`{"account_id":"acct","symbol":"OTHER","side":"sell","type":"limit","price":1e-9,"quantity":1e-9}`)
+	f.Add(`{"symbol":"FUZZ","side":"buy","type":"limit","price":-1,"quantity":-1}`)
+	f.Add(`[]`)
+	f.Add(`{"symbol":"FUZZ","side":"buy","type":"market","quantity":1e300}`)
+	f.Add(`{"symbol":"FUZZ","side":"sell","type":"limit","price":1e300,"quantity":1e15}`)
+
+	gin.SetMode(gin.TestMode)
+
+	f.Fuzz(func(t *testing.T, body string) {
+		store := memory.NewStore(nil)
+		engine := service.NewMatchingEngineWithStore(store)
+		for _, req := range []*models.PlaceOrderRequest{
+			{Symbol: "FUZZ", Side: models.OrderSideBuy, Type: models.OrderTypeLimit, Price: 99, Quantity: 5},
+			{Symbol: "FUZZ", Side: models.OrderSideSell, Type: models.OrderTypeLimit, Price: 101, Quantity: 5},
+			{Symbol: "FUZZ", Side: models.OrderSideSell, Type: models.OrderTypeLimit, Price: 102, Quantity: 5},
+		} {
+			if err := engine.ProcessOrder(context.Background(), req.NewOrder()); err != nil {
+				t.Fatalf("failed to seed book: %v", err)
+			}
+		}
+
+		router := gin.New()
+		router.POST("/orders", NewHandler(nil, engine).PlaceOrder)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body)))
+
+		switch w.Code {
+		case http.StatusBadRequest:
+			return
+		case http.StatusCreated:
+		default:
+			t.Fatalf("status %d for body %q: %s", w.Code, body, w.Body.String())
+		}
+
+		var order models.Order
+		if err := json.Unmarshal(w.Body.Bytes(), &order); err != nil {
+			t.Fatalf("failed to decode order: %v", err)
+		}
+		if order.Side != models.OrderSideBuy && order.Side != models.OrderSideSell {
+			t.Fatalf("accepted side %q", order.Side)
+		}
+		if order.Type != models.OrderTypeLimit && order.Type != models.OrderTypeMarket {
+			t.Fatalf("accepted type %q", order.Type)
+		}
+		if order.InitialQuantity <= 0 || (order.Type == models.OrderTypeLimit && order.Price <= 0) {
+			t.Fatalf("accepted quantity %v at price %v", order.InitialQuantity, order.Price)
+		}
+		if order.FilledQuantity < 0 || order.RemainingQuantity < 0 ||
+			models.ToUnits(order.FilledQuantity) > models.ToUnits(order.InitialQuantity) {
+			t.Fatalf("order of %v has filled %v, remaining %v", order.InitialQuantity, order.FilledQuantity, order.RemainingQuantity)
+		}
+
+		book := store.Book(order.Symbol, models.BookLevel2, 1)
+		if len(book.Bids) > 0 && len(book.Asks) > 0 && book.Bids[0].Price >= book.Asks[0].Price {
+			t.Fatalf("book crossed after %q: best bid %v, best ask %v", body, book.Bids[0].Price, book.Asks[0].Price)
+		}
+	})
+}
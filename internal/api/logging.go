@@ -0,0 +1,78 @@
+package api
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"order-matching-system/internal/logging"
+)
+
+// RequestIDHeader carries a request's correlation ID. A valid ID sent by the
+// client is kept, otherwise one is generated; either way it is echoed in the
+// response.
+const RequestIDHeader = "X-Request-ID"
+
+// requestID tags the request context and span with a correlation ID, so
+// every line logged while serving the request carries it
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !logging.ValidRequestID(id) {
+			id = logging.NewRequestID()
+		}
+		c.Header(RequestIDHeader, id)
+
+		ctx := logging.With(c.Request.Context(), slog.String(logging.RequestIDKey, id))
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String(logging.RequestIDKey, id))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// requestLogger logs one line per request once it has been served: client
+// errors at warn level and server errors at error level
+func requestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("bytes", c.Writer.Size()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", c.Errors.String()))
+		}
+		slog.LogAttrs(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// recoverPanics turns a panicking handler into a 500, logging the panic and
+// its stack with the request's correlation ID
+func recoverPanics() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, err any) {
+		slog.ErrorContext(c.Request.Context(), "handler panicked", "panic", err, "stack", string(debug.Stack()))
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
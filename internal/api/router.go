@@ -1,21 +1,96 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+	"order-matching-system/internal/metrics"
+	"order-matching-system/internal/service"
+	"order-matching-system/internal/tracing"
 )
 
-func SetupRouter(db *sql.DB) *gin.Engine {
-	router := gin.Default()
+type Config struct {
+	RequestTimeout time.Duration     // Deadline applied to each request's context, zero for none
+	APIKeys        map[string]string // Key ID to secret; when set every request must be signed
+	MaxClockSkew   time.Duration     // Allowed distance of a signed request's timestamp from now
+	OperatorKeys   []string          // IDs of the API keys allowed on the /admin routes; none closes them
+}
+
+func SetupRouter(db *sql.DB, engine *service.MatchingEngine, cfg Config) *gin.Engine {
+	router := gin.New()
+	router.Use(traceRequests(), requestID(), requestLogger(), recoverPanics(), metrics.GinMiddleware())
+
+	handler := NewHandler(db, engine)
+
+	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
+	authed := router.Group("/")
+	if len(cfg.APIKeys) > 0 {
+		authed.Use(SignatureAuth(cfg.APIKeys, cfg.MaxClockSkew))
+	}
+
+	// Streams stay open, so only the other routes get a deadline
+	stream := authed.Group("/stream")
+	stream.GET("/trades", handler.StreamTrades)
+	stream.GET("/orderbook", handler.StreamOrderBook)
 
-	handler := NewHandler(db)
+	api := authed.Group("/")
+	if cfg.RequestTimeout > 0 {
+		api.Use(requestTimeout(cfg.RequestTimeout))
+	}
 
-	router.POST("/orders", handler.PlaceOrder)
-	router.DELETE("/orders/:orderId", handler.CancelOrder)
-	router.GET("/orders/:orderId", handler.GetOrderStatus)
-	router.GET("/orderbook", handler.GetOrderBook)
-	router.GET("/trades", handler.ListTrades)
+	api.POST("/orders", handler.PlaceOrder)
+	api.GET("/orders", handler.ListOrders)
+	api.PATCH("/orders/:orderId", handler.AmendOrder)
+	api.DELETE("/orders/:orderId", handler.CancelOrder)
+	api.GET("/orders/:orderId", handler.GetOrderStatus)
+	api.GET("/orders/:orderId/history", handler.GetOrderHistory)
+	api.GET("/orders/:orderId/fills", handler.GetOrderFills)
+	api.GET("/orderbook", handler.GetOrderBook)
+	api.GET("/orderbook/history", handler.GetOrderBookAt)
+	api.GET("/trades", handler.ListTrades)
+	api.GET("/candles", handler.GetCandles)
+	api.GET("/ticker", handler.GetTicker)
+	api.GET("/instruments", handler.ListInstruments)
+	api.GET("/instruments/:symbol", handler.GetInstrument)
+	api.GET("/accounts/:id/positions", handler.GetAccountPositions)
+
+	// Operator tools
+	admin := api.Group("/admin", requireOperator(cfg.OperatorKeys))
+	admin.POST("/trades/:tradeId/bust", handler.BustTrade)
+	admin.POST("/trades/:tradeId/correct", handler.CorrectTrade)
+	admin.GET("/trades/:tradeId/corrections", handler.GetTradeCorrections)
+	admin.GET("/halts", handler.ListHalts)
+	admin.POST("/symbols/:symbol/halt", handler.HaltTrading)
+	admin.POST("/symbols/:symbol/resume", handler.ResumeTrading)
+	admin.POST("/orders/mass-cancel", handler.MassCancel)
+	admin.PUT("/instruments/:symbol", handler.SaveInstrument)
 
 	return router
 }
+
+// traceRequests starts a server span per request, continuing any trace context
+// sent in the request headers. Prometheus scrapes are not traced.
+func traceRequests() gin.HandlerFunc {
+	return otelgin.Middleware(tracing.ServiceName, otelgin.WithFilter(func(r *http.Request) bool {
+		return r.URL.Path != "/metrics"
+	}))
+}
+
+// requestTimeout gives every request a context deadline so that slow queries
+// are cancelled; client disconnects already cancel the request context
+func requestTimeout(d time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
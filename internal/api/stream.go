@@ -0,0 +1,90 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// keepAliveInterval is how often an idle stream sends a comment line so that
+// proxies do not close it
+const keepAliveInterval = 15 * time.Second
+
+// StreamTrades sends each committed trade as a server-sent "trade" event,
+// optionally filtered by the symbol query parameter
+func (h *Handler) StreamTrades(c *gin.Context) {
+	trades, unsubscribe := h.matchingEngine.Feed().SubscribeTrades(c.Query("symbol"))
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Header("Cache-Control", "no-cache")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case trade, ok := <-trades:
+			if !ok {
+				return false
+			}
+			c.SSEvent("trade", trade)
+			return true
+		}
+	})
+}
+
+// StreamOrderBook sends the book as a server-sent "orderbook" event, first
+// as it stands and then after every change. level and depth behave as for
+// GET /orderbook.
+func (h *Handler) StreamOrderBook(c *gin.Context) {
+	symbol := c.Query("symbol")
+	if symbol == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "symbol parameter is required"})
+		return
+	}
+
+	level, depth, ok := bookParams(c)
+	if !ok {
+		return
+	}
+
+	// Subscribe before the first read so no change is missed in between
+	updates, unsubscribe := h.matchingEngine.Feed().SubscribeBook(symbol)
+	defer unsubscribe()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+
+	ctx := c.Request.Context()
+	changed := true
+	c.Header("Cache-Control", "no-cache")
+	c.Stream(func(w io.Writer) bool {
+		if changed {
+			book, err := h.orderBookRepo.GetOrderBook(ctx, symbol, level, depth)
+			if err != nil {
+				c.SSEvent("error", gin.H{"error": err.Error()})
+				return false
+			}
+			c.SSEvent("orderbook", book)
+			changed = false
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case _, ok := <-updates:
+			changed = ok
+			return ok
+		}
+	})
+}
@@ -0,0 +1,454 @@
+// Package backtest replays a recorded order stream through a fresh
+// MatchingEngine on an in-memory store, with a strategy's orders interleaved,
+// and reports how the strategy's orders would have been filled against the
+// recorded queues.
+//
+// The replay is deterministic: events are applied one at a time in time
+// order, on a clock set to each event's time. Recorded orders are matched
+// again rather than taking their recorded fills, so a strategy's orders
+// change what the recorded flow trades against, as they would have live.
+package backtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+	"order-matching-system/internal/service"
+)
+
+// DefaultAccount is the account strategy orders are placed under
+const DefaultAccount = "backtest"
+
+// Config describes a backtest
+type Config struct {
+	// Flow is the recorded order stream. It is replayed in time order; events
+	// at the same time keep their order.
+	Flow []*models.FlowEvent
+	// Orders are strategy orders and cancels placed at fixed times, after any
+	// recorded events at the same time. Amendments are not supported. Their order IDs only need to be
+	// unique among Orders, for cancels to refer to.
+	Orders []*models.FlowEvent
+	// Strategy reacts to the replay as it runs; nil for none
+	Strategy Strategy
+	// Account the strategy's orders are placed under; DefaultAccount if empty
+	Account string
+	// Instruments configures the matching of each symbol; symbols without
+	// one use the defaults
+	Instruments []*models.Instrument
+}
+
+// Strategy places and cancels orders through a Session as the replay runs.
+// A strategy that only reacts to one kind of callback returns nil from the
+// other.
+type Strategy interface {
+	// OnEvent is called after each recorded event has been applied and any
+	// fills it caused have been reported
+	OnEvent(ctx context.Context, s *Session, event *models.FlowEvent) error
+	// OnFill is called for each trade of one of the strategy's orders
+	OnFill(ctx context.Context, s *Session, fill *Fill) error
+}
+
+// Report is the outcome of a backtest
+type Report struct {
+	Orders    []*OrderReport     // Strategy orders, in the order they were placed
+	Fills     []*Fill            // Trades of strategy orders, in execution order
+	Positions []*models.Position // Strategy positions by symbol, marked to the last trade price
+
+	RecordedOrders  int // Recorded orders replayed
+	RecordedCancels int // Recorded cancels replayed
+	MissedCancels   int // Recorded cancels of orders that had already filled in the replay, or were never replayed
+	RecordedAmends  int // Recorded amendments replayed
+	MissedAmends    int // Recorded amendments of orders no longer resting in the replay, or that it refused
+	Trades          int // Trades in the replay, including those between recorded orders
+}
+
+// OrderReport is how one strategy order fared
+type OrderReport struct {
+	Order      *models.Order // State at the end of the replay
+	PlacedAt   time.Time
+	ArrivalMid float64 // Book midpoint when the order arrived; zero if either side was empty
+	// Queue is the order's position when it first rested, for limit orders
+	// that did not fill completely on arrival
+	Queue *QueueEstimate
+	Fills []*Fill
+}
+
+// QueueEstimate is a resting order's place on its side of the book
+type QueueEstimate struct {
+	Ahead  float64 // Quantity at the order's price that trades before it under time priority
+	Better float64 // Quantity resting at better prices
+}
+
+// Fill is one trade of a strategy order
+type Fill struct {
+	OrderID  int
+	TradeID  int
+	Time     time.Time
+	Side     models.OrderSide
+	Price    float64
+	Quantity float64
+	Maker    bool // The strategy order was resting when the trade happened
+}
+
+// Backtest runs one replay
+type Backtest struct {
+	cfg      Config
+	store    *memory.Store
+	engine   *service.MatchingEngine
+	now      time.Time
+	recorded map[int]int // Replay order ID of each recorded order ID
+	orders   map[int]int // Replay order ID of each scheduled strategy order ID
+	reports  map[int]*OrderReport
+	symbols  map[string]bool // Symbols the strategy has traded
+	pending  []*Fill         // Fills not yet passed to the strategy
+	tradeID  int             // Last trade collected
+	report   *Report
+}
+
+// New returns a backtest of cfg with a fresh store and engine
+func New(cfg Config) (*Backtest, error) {
+	if cfg.Account == "" {
+		cfg.Account = DefaultAccount
+	}
+	for i, event := range cfg.Flow {
+		if err := validateEvent(event); err != nil {
+			return nil, fmt.Errorf("invalid flow event %d: %w", i+1, err)
+		}
+		if event.Kind == models.FlowOrder && event.AccountID == cfg.Account {
+			return nil, fmt.Errorf("recorded order %d uses the strategy account %q", event.OrderID, cfg.Account)
+		}
+	}
+	for i, event := range cfg.Orders {
+		if err := validateEvent(event); err != nil {
+			return nil, fmt.Errorf("invalid strategy order %d: %w", i+1, err)
+		}
+		if event.Kind == models.FlowAmend {
+			return nil, fmt.Errorf("invalid strategy order %d: amendments are only replayed from the recorded flow", i+1)
+		}
+	}
+
+	b := &Backtest{
+		cfg:      cfg,
+		recorded: make(map[int]int),
+		orders:   make(map[int]int),
+		reports:  make(map[int]*OrderReport),
+		symbols:  make(map[string]bool),
+		report:   &Report{},
+	}
+	b.store = memory.NewStore(func() time.Time { return b.now })
+	for _, instrument := range cfg.Instruments {
+		b.store.SetInstrument(instrument)
+	}
+	b.engine = service.NewMatchingEngineWithStore(b.store)
+	return b, nil
+}
+
+func validateEvent(event *models.FlowEvent) error {
+	switch event.Kind {
+	case models.FlowCancel:
+		return nil
+	case models.FlowAmend:
+		return event.AmendOrderRequest().Validate()
+	case models.FlowOrder:
+	default:
+		return fmt.Errorf("unknown kind %q", event.Kind)
+	}
+
+	if event.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+	if event.Side != models.OrderSideBuy && event.Side != models.OrderSideSell {
+		return fmt.Errorf("unknown side %q", event.Side)
+	}
+	if event.Type != models.OrderTypeLimit && event.Type != models.OrderTypeMarket {
+		return fmt.Errorf("unknown type %q", event.Type)
+	}
+	return event.PlaceOrderRequest().Validate()
+}
+
+// Run replays the flow to the end, or until ctx is done
+func (b *Backtest) Run(ctx context.Context) (*Report, error) {
+	type step struct {
+		event    *models.FlowEvent
+		strategy bool
+	}
+	steps := make([]step, 0, len(b.cfg.Flow)+len(b.cfg.Orders))
+	for _, event := range b.cfg.Flow {
+		steps = append(steps, step{event: event})
+	}
+	for _, event := range b.cfg.Orders {
+		steps = append(steps, step{event: event, strategy: true})
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].event.Time.Before(steps[j].event.Time) })
+
+	session := &Session{b: b}
+	for _, st := range steps {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		b.now = st.event.Time
+
+		if st.strategy {
+			if err := b.applyStrategyEvent(ctx, session, st.event); err != nil {
+				return nil, err
+			}
+			if err := b.dispatchFills(ctx, session); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if err := b.applyRecorded(ctx, st.event); err != nil {
+			return nil, err
+		}
+		if err := b.dispatchFills(ctx, session); err != nil {
+			return nil, err
+		}
+		if b.cfg.Strategy != nil {
+			if err := b.cfg.Strategy.OnEvent(ctx, session, st.event); err != nil {
+				return nil, fmt.Errorf("strategy failed at %s: %w", b.now.Format(time.RFC3339Nano), err)
+			}
+			if err := b.dispatchFills(ctx, session); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return b.finish(), nil
+}
+
+// applyRecorded replays one recorded event
+func (b *Backtest) applyRecorded(ctx context.Context, event *models.FlowEvent) error {
+	switch event.Kind {
+	case models.FlowOrder:
+		order := event.PlaceOrderRequest().NewOrder()
+		if err := b.engine.ProcessOrder(ctx, order); err != nil {
+			return fmt.Errorf("failed to replay order %d: %w", event.OrderID, err)
+		}
+		b.recorded[event.OrderID] = order.ID
+		b.report.RecordedOrders++
+
+	case models.FlowCancel:
+		b.report.RecordedCancels++
+		id, ok := b.recorded[event.OrderID]
+		if !ok {
+			b.report.MissedCancels++
+			break
+		}
+		_, err := b.engine.CancelOrder(ctx, id, models.ActorAPI)
+		if errors.Is(err, service.ErrOrderNotCancelable) {
+			b.report.MissedCancels++
+		} else if err != nil {
+			return fmt.Errorf("failed to replay cancel of order %d: %w", event.OrderID, err)
+		}
+
+	case models.FlowAmend:
+		b.report.RecordedAmends++
+		id, ok := b.recorded[event.OrderID]
+		if !ok {
+			b.report.MissedAmends++
+			break
+		}
+		_, err := b.engine.AmendOrder(ctx, id, *event.AmendOrderRequest(), models.ActorAPI)
+		var rejected *service.AmendRejectedError
+		if errors.Is(err, service.ErrOrderNotAmendable) || errors.Is(err, service.ErrInvalidAmendment) || errors.As(err, &rejected) {
+			b.report.MissedAmends++
+		} else if err != nil {
+			return fmt.Errorf("failed to replay amendment of order %d: %w", event.OrderID, err)
+		}
+	}
+
+	b.collectTrades()
+	return nil
+}
+
+// applyStrategyEvent places or cancels one of the scheduled strategy orders
+func (b *Backtest) applyStrategyEvent(ctx context.Context, s *Session, event *models.FlowEvent) error {
+	switch event.Kind {
+	case models.FlowOrder:
+		order, err := s.place(ctx, event.PlaceOrderRequest())
+		if err != nil {
+			return fmt.Errorf("failed to place strategy order %d: %w", event.OrderID, err)
+		}
+		b.orders[event.OrderID] = order.ID
+
+	case models.FlowCancel:
+		id, ok := b.orders[event.OrderID]
+		if !ok {
+			return fmt.Errorf("strategy cancel of unknown order %d", event.OrderID)
+		}
+		if err := s.Cancel(ctx, id); err != nil {
+			return fmt.Errorf("failed to cancel strategy order %d: %w", event.OrderID, err)
+		}
+	}
+	return nil
+}
+
+// collectTrades counts the trades made since the last call and queues the
+// strategy's fills
+func (b *Backtest) collectTrades() {
+	for _, trade := range b.store.TradesSince(b.tradeID) {
+		b.tradeID = trade.ID
+		b.report.Trades++
+
+		for _, side := range []struct {
+			id, other int
+			side      models.OrderSide
+		}{
+			{trade.BuyOrderID, trade.SellOrderID, models.OrderSideBuy},
+			{trade.SellOrderID, trade.BuyOrderID, models.OrderSideSell},
+		} {
+			report, ok := b.reports[side.id]
+			if !ok {
+				continue
+			}
+			fill := &Fill{
+				OrderID:  side.id,
+				TradeID:  trade.ID,
+				Time:     trade.CreatedAt,
+				Side:     side.side,
+				Price:    trade.Price,
+				Quantity: trade.Quantity,
+				// Orders are numbered as they arrive, so the older order was
+				// the one resting
+				Maker: side.id < side.other,
+			}
+			report.Fills = append(report.Fills, fill)
+			b.report.Fills = append(b.report.Fills, fill)
+			b.pending = append(b.pending, fill)
+		}
+	}
+}
+
+// dispatchFills passes queued fills to the strategy, including any caused
+// by orders it places in response
+func (b *Backtest) dispatchFills(ctx context.Context, s *Session) error {
+	for len(b.pending) > 0 {
+		fill := b.pending[0]
+		b.pending = b.pending[1:]
+		if b.cfg.Strategy == nil {
+			continue
+		}
+		if err := b.cfg.Strategy.OnFill(ctx, s, fill); err != nil {
+			return fmt.Errorf("strategy failed on fill of order %d: %w", fill.OrderID, err)
+		}
+	}
+	return nil
+}
+
+// finish fills in the final state of the strategy's orders and positions
+func (b *Backtest) finish() *Report {
+	for _, report := range b.report.Orders {
+		report.Order = b.store.Order(report.Order.ID)
+	}
+
+	symbols := make([]string, 0, len(b.symbols))
+	for symbol := range b.symbols {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	for _, symbol := range symbols {
+		b.report.Positions = append(b.report.Positions, b.store.Position(b.cfg.Account, symbol))
+	}
+	return b.report
+}
+
+// Session is the strategy's view of the replay and its way to trade
+type Session struct {
+	b *Backtest
+}
+
+// Now returns the replay clock, the time of the current event
+func (s *Session) Now() time.Time { return s.b.now }
+
+// Account returns the account the strategy trades under
+func (s *Session) Account() string { return s.b.cfg.Account }
+
+// Book returns symbol's order book with up to depth price levels per side
+func (s *Session) Book(symbol string, depth int) *models.OrderBook {
+	return s.b.store.Book(symbol, models.BookLevel2, depth)
+}
+
+// LastPrice returns the price of the latest trade in symbol, or zero
+func (s *Session) LastPrice(symbol string) float64 {
+	return s.b.store.LastPrice(symbol)
+}
+
+// Position returns the strategy's position in symbol marked to the last
+// trade price
+func (s *Session) Position(symbol string) *models.Position {
+	return s.b.store.Position(s.b.cfg.Account, symbol)
+}
+
+// OpenOrders returns the strategy's resting orders in symbol, oldest first
+func (s *Session) OpenOrders(symbol string) []*models.Order {
+	return s.b.store.OpenOrders(s.b.cfg.Account, symbol)
+}
+
+// QueuePosition estimates where a resting strategy order stands; ok is
+// false if it is not resting
+func (s *Session) QueuePosition(orderID int) (estimate QueueEstimate, ok bool) {
+	ahead, better, ok := s.b.store.QueuePosition(orderID)
+	return QueueEstimate{Ahead: ahead, Better: better}, ok
+}
+
+// PlaceLimit places a limit order and returns its state after matching
+func (s *Session) PlaceLimit(ctx context.Context, symbol string, side models.OrderSide, price, quantity float64) (*models.Order, error) {
+	return s.place(ctx, &models.PlaceOrderRequest{
+		Symbol: symbol, Side: side, Type: models.OrderTypeLimit, Price: price, Quantity: quantity,
+	})
+}
+
+// PlaceMarket places a market order and returns its state after matching
+func (s *Session) PlaceMarket(ctx context.Context, symbol string, side models.OrderSide, quantity float64) (*models.Order, error) {
+	return s.place(ctx, &models.PlaceOrderRequest{
+		Symbol: symbol, Side: side, Type: models.OrderTypeMarket, Quantity: quantity,
+	})
+}
+
+func (s *Session) place(ctx context.Context, req *models.PlaceOrderRequest) (*models.Order, error) {
+	b := s.b
+	req.AccountID = b.cfg.Account
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	var arrivalMid float64
+	if mid := b.store.Book(req.Symbol, models.BookLevel2, 1).MidPrice; mid != nil {
+		arrivalMid = *mid
+	}
+
+	order := req.NewOrder()
+	if err := b.engine.ProcessOrder(ctx, order); err != nil {
+		return nil, err
+	}
+
+	report := &OrderReport{Order: order, PlacedAt: b.now, ArrivalMid: arrivalMid}
+	if estimate, ok := s.QueuePosition(order.ID); ok {
+		report.Queue = &estimate
+	}
+	b.reports[order.ID] = report
+	b.report.Orders = append(b.report.Orders, report)
+	b.symbols[req.Symbol] = true
+
+	b.collectTrades()
+	return order, nil
+}
+
+// Cancel cancels a resting strategy order. Canceling an order that has
+// already filled is not an error.
+func (s *Session) Cancel(ctx context.Context, orderID int) error {
+	if _, ok := s.b.reports[orderID]; !ok {
+		return fmt.Errorf("order %d is not a strategy order", orderID)
+	}
+	_, err := s.b.engine.CancelOrder(ctx, orderID, s.b.cfg.Account)
+	if errors.Is(err, service.ErrOrderNotCancelable) {
+		return nil
+	}
+	return err
+}
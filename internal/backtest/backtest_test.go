@@ -0,0 +1,122 @@
+package backtest
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"order-matching-system/internal/models"
+)
+
+var flowStart = time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+
+// randomFlow generates n recorded events around a price of 100: mostly limit
+// orders, with some market orders, amendments and cancels of earlier orders
+func randomFlow(seed int64, n int) []*models.FlowEvent {
+	rng := rand.New(rand.NewSource(seed))
+	var flow []*models.FlowEvent
+	for i := 1; i <= n; i++ {
+		event := &models.FlowEvent{Time: flowStart.Add(time.Duration(i) * time.Second), OrderID: i}
+		switch r := rng.Intn(10); {
+		case r == 0 && i > 1:
+			event.Kind = models.FlowCancel
+			event.OrderID = rng.Intn(i-1) + 1
+		case r == 1 && i > 1:
+			event.Kind = models.FlowAmend
+			event.OrderID = rng.Intn(i-1) + 1
+			event.Price = 99 + float64(rng.Intn(5))*0.5
+			event.Quantity = float64(rng.Intn(10) + 1)
+		default:
+			event.Kind = models.FlowOrder
+			event.AccountID = "acct-" + string(rune('a'+rng.Intn(3)))
+			event.Symbol = "BT"
+			event.Side = models.OrderSideBuy
+			if rng.Intn(2) == 0 {
+				event.Side = models.OrderSideSell
+			}
+			event.Type = models.OrderTypeLimit
+			event.Price = 99 + float64(rng.Intn(5))*0.5
+			if r == 2 {
+				event.Type = models.OrderTypeMarket
+				event.Price = 0
+			}
+			event.Quantity = float64(rng.Intn(10) + 1)
+		}
+		flow = append(flow, event)
+	}
+	return flow
+}
+
+func TestRunIsDeterministic(t *testing.T) {
+	run := func() *Report {
+		b, err := New(Config{
+			Flow:     randomFlow(1, 500),
+			Strategy: &JoinTouch{Symbol: "BT", Side: models.OrderSideBuy, Quantity: 40, Clip: 5},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		report, err := b.Run(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	first, second := run(), run()
+	if len(first.Fills) == 0 || first.RecordedAmends == 0 || first.RecordedCancels == 0 {
+		t.Fatalf("flow exercised too little: %d fills, %d amends, %d cancels",
+			len(first.Fills), first.RecordedAmends, first.RecordedCancels)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatal("two runs of the same flow and strategy produced different reports")
+	}
+}
+
+func TestReplayAmendments(t *testing.T) {
+	at := func(seconds int) time.Time { return flowStart.Add(time.Duration(seconds) * time.Second) }
+	sell := func(seconds, id int, price, quantity float64) *models.FlowEvent {
+		return &models.FlowEvent{Time: at(seconds), Kind: models.FlowOrder, OrderID: id, AccountID: "acct",
+			Symbol: "BT", Side: models.OrderSideSell, Type: models.OrderTypeLimit, Price: price, Quantity: quantity}
+	}
+
+	b, err := New(Config{
+		Flow: []*models.FlowEvent{
+			sell(1, 1, 101, 1),
+			sell(2, 2, 101, 1),
+			{Time: at(3), Kind: models.FlowAmend, OrderID: 1, Quantity: 2}, // Back of the queue
+			sell(4, 3, 102, 1),
+			{Time: at(5), Kind: models.FlowCancel, OrderID: 3},
+			{Time: at(6), Kind: models.FlowAmend, OrderID: 3, Price: 101.5}, // Already canceled
+		},
+		Orders: []*models.FlowEvent{
+			{Time: at(7), Kind: models.FlowOrder, OrderID: 1, Symbol: "BT", Side: models.OrderSideBuy, Type: models.OrderTypeLimit, Price: 101, Quantity: 1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := b.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.RecordedAmends != 2 || report.MissedAmends != 1 {
+		t.Fatalf("replayed %d amendments with %d missed, want 2 with 1 missed", report.RecordedAmends, report.MissedAmends)
+	}
+	if got := b.store.Order(b.recorded[2]); got.Status != models.OrderStatusFilled {
+		t.Fatalf("order 2 is %s, want filled ahead of the amended order 1", got.Status)
+	}
+	if got := b.store.Order(b.recorded[1]); got.Status != models.OrderStatusOpen || got.RemainingQuantity != 2 {
+		t.Fatalf("order 1 is %s with %v remaining, want open with 2", got.Status, got.RemainingQuantity)
+	}
+}
+
+func TestStrategyAmendsAreRefused(t *testing.T) {
+	_, err := New(Config{Orders: []*models.FlowEvent{{Time: flowStart, Kind: models.FlowAmend, OrderID: 1, Price: 100}}})
+	if err == nil {
+		t.Fatal("accepted a scheduled strategy amendment")
+	}
+}
@@ -0,0 +1,221 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"order-matching-system/internal/models"
+)
+
+// Flow files hold one event per CSV row or JSON line. CSV files start with a
+// header naming these columns, in any order; only time, kind and order_id
+// are required.
+var flowColumns = []string{"time", "kind", "order_id", "account_id", "symbol", "side", "type", "price", "max_slippage", "quantity"}
+
+// Format is a flow file encoding
+type Format string
+
+const (
+	FormatCSV   Format = "csv"
+	FormatJSONL Format = "jsonl"
+)
+
+// FormatOf picks the format of a flow file from its extension
+func FormatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".jsonl", ".ndjson":
+		return FormatJSONL, nil
+	}
+	return "", fmt.Errorf("unknown flow file extension %q, expected .csv or .jsonl", filepath.Ext(path))
+}
+
+// LoadFlow reads the flow file at path
+func LoadFlow(path string) ([]*models.FlowEvent, error) {
+	format, err := FormatOf(path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadFlow(f, format)
+}
+
+// SaveFlow writes flow to the file at path, in the format its extension names
+func SaveFlow(path string, flow []*models.FlowEvent) error {
+	format, err := FormatOf(path)
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteFlow(f, format, flow); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// ReadFlow reads events in format from r
+func ReadFlow(r io.Reader, format Format) ([]*models.FlowEvent, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatJSONL:
+		return readJSONL(r)
+	}
+	return nil, fmt.Errorf("unknown flow format %q", format)
+}
+
+// WriteFlow writes events to w in format
+func WriteFlow(w io.Writer, format Format, flow []*models.FlowEvent) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, flow)
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, event := range flow {
+			if err := enc.Encode(event); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown flow format %q", format)
+}
+
+func readJSONL(r io.Reader) ([]*models.FlowEvent, error) {
+	var flow []*models.FlowEvent
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		event := &models.FlowEvent{}
+		if err := json.Unmarshal([]byte(text), event); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		flow = append(flow, event)
+	}
+	return flow, scanner.Err()
+}
+
+func readCSV(r io.Reader) ([]*models.FlowEvent, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	column := make(map[string]int, len(header))
+	for i, name := range header {
+		column[strings.TrimSpace(name)] = i
+	}
+	for _, name := range []string{"time", "kind", "order_id"} {
+		if _, ok := column[name]; !ok {
+			return nil, fmt.Errorf("missing %q column", name)
+		}
+	}
+
+	var flow []*models.FlowEvent
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return flow, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		event, err := parseRecord(record, column)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		flow = append(flow, event)
+	}
+}
+
+func parseRecord(record []string, column map[string]int) (*models.FlowEvent, error) {
+	field := func(name string) string {
+		if i, ok := column[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	number := func(name string) (float64, error) {
+		if field(name) == "" {
+			return 0, nil
+		}
+		v, err := strconv.ParseFloat(field(name), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return v, nil
+	}
+
+	var err error
+	event := &models.FlowEvent{
+		Kind:      models.FlowEventKind(field("kind")),
+		AccountID: field("account_id"),
+		Symbol:    field("symbol"),
+		Side:      models.OrderSide(field("side")),
+		Type:      models.OrderType(field("type")),
+	}
+	if event.Time, err = time.Parse(time.RFC3339Nano, field("time")); err != nil {
+		return nil, fmt.Errorf("invalid time: %w", err)
+	}
+	if event.OrderID, err = strconv.Atoi(field("order_id")); err != nil {
+		return nil, errors.New("invalid order_id")
+	}
+	if event.Price, err = number("price"); err != nil {
+		return nil, err
+	}
+	if event.MaxSlippage, err = number("max_slippage"); err != nil {
+		return nil, err
+	}
+	if event.Quantity, err = number("quantity"); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func writeCSV(w io.Writer, flow []*models.FlowEvent) error {
+	cw := csv.NewWriter(w)
+	cw.Write(flowColumns)
+	for _, e := range flow {
+		cw.Write([]string{
+			e.Time.Format(time.RFC3339Nano), string(e.Kind), strconv.Itoa(e.OrderID), e.AccountID, e.Symbol,
+			string(e.Side), string(e.Type), formatFloat(e.Price), formatFloat(e.MaxSlippage), formatFloat(e.Quantity),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatFloat writes zero as an empty field, as absent prices are read back
+func formatFloat(f float64) string {
+	if f == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,52 @@
+package backtest
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"order-matching-system/internal/models"
+)
+
+func TestFlowRoundTrip(t *testing.T) {
+	start := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+	flow := []*models.FlowEvent{
+		{Time: start, Kind: models.FlowOrder, OrderID: 1, AccountID: "acct-a", Symbol: "BT", Side: models.OrderSideSell, Type: models.OrderTypeLimit, Price: 100.25, Quantity: 3},
+		{Time: start.Add(1500 * time.Microsecond), Kind: models.FlowOrder, OrderID: 2, Symbol: "BT", Side: models.OrderSideBuy, Type: models.OrderTypeMarket, MaxSlippage: 0.5, Quantity: 1e-8},
+		{Time: start.Add(time.Second), Kind: models.FlowAmend, OrderID: 1, Price: 100.5, Quantity: 4},
+		{Time: start.Add(2 * time.Second), Kind: models.FlowCancel, OrderID: 1},
+	}
+
+	for _, format := range []Format{FormatCSV, FormatJSONL} {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteFlow(&buf, format, flow); err != nil {
+				t.Fatal(err)
+			}
+			got, err := ReadFlow(&buf, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, flow) {
+				t.Fatalf("round trip changed the flow:\ngot  %+v\nwant %+v", got, flow)
+			}
+		})
+	}
+}
+
+func TestReadCSVColumns(t *testing.T) {
+	// Columns in any order, optional ones left out
+	csv := "order_id,kind,time\n7,cancel,2024-01-02T09:30:00Z\n"
+	flow, err := ReadFlow(bytes.NewBufferString(csv), FormatCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flow) != 1 || flow[0].OrderID != 7 || flow[0].Kind != models.FlowCancel {
+		t.Fatalf("unexpected flow %+v", flow)
+	}
+
+	if _, err := ReadFlow(bytes.NewBufferString("time,kind\n"), FormatCSV); err == nil {
+		t.Fatal("read a file without an order_id column")
+	}
+}
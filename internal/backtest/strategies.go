@@ -0,0 +1,69 @@
+package backtest
+
+import (
+	"context"
+
+	"order-matching-system/internal/models"
+)
+
+// JoinTouch works a parent order passively. It keeps one limit order for
+// the unfilled quantity at the best price on its own side of the book, and
+// moves it to the new best price when the touch moves away. It never
+// crosses the spread, and places nothing while its side of the book is
+// empty.
+type JoinTouch struct {
+	Symbol   string
+	Side     models.OrderSide
+	Quantity float64 // Total quantity to fill
+	Clip     float64 // Largest child order; zero places the whole remainder
+
+	orderID int
+	filled  float64
+}
+
+func (j *JoinTouch) OnEvent(ctx context.Context, s *Session, event *models.FlowEvent) error {
+	remaining := models.FromUnits(models.ToUnits(j.Quantity) - models.ToUnits(j.filled))
+	if remaining <= 0 {
+		return nil
+	}
+
+	book := s.Book(j.Symbol, 1)
+	levels := book.Bids
+	if j.Side == models.OrderSideSell {
+		levels = book.Asks
+	}
+	if len(levels) == 0 {
+		return nil
+	}
+	touch := levels[0].Price
+
+	if j.orderID != 0 {
+		for _, order := range s.OpenOrders(j.Symbol) {
+			if order.ID == j.orderID && order.Price == touch {
+				return nil
+			}
+		}
+		if err := s.Cancel(ctx, j.orderID); err != nil {
+			return err
+		}
+		j.orderID = 0
+	}
+
+	quantity := remaining
+	if j.Clip > 0 {
+		quantity = min(quantity, j.Clip)
+	}
+	order, err := s.PlaceLimit(ctx, j.Symbol, j.Side, touch, quantity)
+	if err != nil {
+		return err
+	}
+	if order.Status == models.OrderStatusOpen {
+		j.orderID = order.ID
+	}
+	return nil
+}
+
+func (j *JoinTouch) OnFill(ctx context.Context, s *Session, fill *Fill) error {
+	j.filled = models.FromUnits(models.ToUnits(j.filled) + models.ToUnits(fill.Quantity))
+	return nil
+}
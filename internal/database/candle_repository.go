@@ -0,0 +1,205 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"order-matching-system/internal/models"
+)
+
+type CandleRepository struct {
+	db DBTX
+}
+
+func NewCandleRepository(db DBTX) *CandleRepository {
+	return &CandleRepository{db: db}
+}
+
+// ApplyTrade folds a trade into the bar of every maintained interval
+func (r *CandleRepository) ApplyTrade(ctx context.Context, trade *models.Trade) error {
+	query := `
+		INSERT INTO candles (symbol, bar_interval, open_time, open_price, high_price, low_price, close_price, volume, notional, trade_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, 1)
+		ON DUPLICATE KEY UPDATE
+			high_price = GREATEST(high_price, VALUES(high_price)),
+			low_price = LEAST(low_price, VALUES(low_price)),
+			close_price = VALUES(close_price),
+			volume = volume + VALUES(volume),
+			notional = notional + VALUES(notional),
+			trade_count = trade_count + 1
+	`
+
+	for _, interval := range models.CandleIntervals {
+		_, err := r.db.ExecContext(
+			ctx,
+			query,
+			trade.Symbol,
+			interval,
+			interval.BucketStart(trade.CreatedAt),
+			trade.Price,
+			trade.Price,
+			trade.Price,
+			trade.Price,
+			trade.Quantity,
+			trade.Price*trade.Quantity,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update %s candle: %w", interval, err)
+		}
+	}
+
+	return nil
+}
+
+// ReplaceBar recomputes one bar from trades, the complete set of live trades
+// in it, deleting the bar when there are none. Used after a trade is busted
+// or corrected.
+func (r *CandleRepository) ReplaceBar(ctx context.Context, symbol string, interval models.CandleInterval, openTime time.Time, trades []*models.Trade) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM candles WHERE symbol = ? AND bar_interval = ? AND open_time = ?`,
+		symbol, interval, openTime,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete %s candle: %w", interval, err)
+	}
+	if len(trades) == 0 {
+		return nil
+	}
+
+	first, last := trades[0], trades[len(trades)-1]
+	high, low := first.Price, first.Price
+	var volume, notional float64
+	for _, trade := range trades {
+		high = max(high, trade.Price)
+		low = min(low, trade.Price)
+		volume += trade.Quantity
+		notional += trade.Price * trade.Quantity
+	}
+
+	query := `
+		INSERT INTO candles (symbol, bar_interval, open_time, open_price, high_price, low_price, close_price, volume, notional, trade_count)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = r.db.ExecContext(ctx, query, symbol, interval, openTime, first.Price, high, low, last.Price, volume, notional, len(trades))
+	if err != nil {
+		return fmt.Errorf("failed to replace %s candle: %w", interval, err)
+	}
+
+	return nil
+}
+
+// GetCandles returns the bars for a symbol whose open time falls in [from, to)
+func (r *CandleRepository) GetCandles(ctx context.Context, symbol string, interval models.CandleInterval, from, to time.Time) ([]*models.Candle, error) {
+	query := `
+		SELECT open_time, open_price, high_price, low_price, close_price, volume, notional, trade_count
+		FROM candles
+		WHERE symbol = ? AND bar_interval = ? AND open_time >= ? AND open_time < ?
+		ORDER BY open_time ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, interval, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get candles: %w", err)
+	}
+	defer rows.Close()
+
+	candles := []*models.Candle{}
+	for rows.Next() {
+		candle := &models.Candle{Symbol: symbol, Interval: interval}
+		var notional float64
+		err := rows.Scan(
+			&candle.OpenTime,
+			&candle.Open,
+			&candle.High,
+			&candle.Low,
+			&candle.Close,
+			&candle.Volume,
+			&notional,
+			&candle.TradeCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan candle: %w", err)
+		}
+		if candle.Volume > 0 {
+			candle.VWAP = notional / candle.Volume
+		}
+		candles = append(candles, candle)
+	}
+
+	return candles, nil
+}
+
+// GetTicker summarizes trading in a symbol since the given time. It returns
+// nil if the symbol has never traded.
+func (r *CandleRepository) GetTicker(ctx context.Context, symbol string, since time.Time) (*models.Ticker, error) {
+	lastQuery := `
+		SELECT price, created_at
+		FROM trades
+		WHERE symbol = ? AND NOT busted
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`
+	last := &models.Trade{Symbol: symbol}
+	err := r.db.QueryRowContext(ctx, lastQuery, symbol).Scan(&last.Price, &last.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return newTicker(symbol, nil, nil, since), nil
+		}
+		return nil, fmt.Errorf("failed to get last trade: %w", err)
+	}
+
+	// 24h statistics come from the 1m bars rather than raw trades; no bar
+	// opens after the last trade
+	from := models.CandleInterval1m.BucketStart(since)
+	to := models.CandleInterval1m.BucketStart(last.CreatedAt).Add(time.Minute)
+	bars, err := r.GetCandles(ctx, symbol, models.CandleInterval1m, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ticker stats: %w", err)
+	}
+
+	return newTicker(symbol, last, bars, since), nil
+}
+
+// newTicker summarizes the 1m bars that open in the window starting at the
+// bar holding since, in open time order, and the symbol's last trade. It
+// returns nil if there is no last trade.
+func newTicker(symbol string, last *models.Trade, bars []*models.Candle, since time.Time) *models.Ticker {
+	if last == nil {
+		return nil
+	}
+	ticker := &models.Ticker{
+		Symbol:      symbol,
+		LastPrice:   last.Price,
+		LastTradeAt: last.CreatedAt,
+		High:        last.Price,
+		Low:         last.Price,
+	}
+
+	from := models.CandleInterval1m.BucketStart(since)
+	var open *models.Candle
+	for _, bar := range bars {
+		if bar.OpenTime.Before(from) {
+			continue
+		}
+		if open == nil {
+			open = bar
+			ticker.High, ticker.Low = bar.High, bar.Low
+		}
+		ticker.High = max(ticker.High, bar.High)
+		ticker.Low = min(ticker.Low, bar.Low)
+		ticker.Volume += bar.Volume
+	}
+	if open == nil {
+		// No trades in the window; report a flat ticker at the last price
+		return ticker
+	}
+
+	ticker.PriceChange = ticker.LastPrice - open.Open
+	if open.Open != 0 {
+		ticker.PriceChangePercent = ticker.PriceChange / open.Open * 100
+	}
+
+	return ticker
+}
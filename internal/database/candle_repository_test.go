@@ -0,0 +1,62 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"order-matching-system/internal/models"
+)
+
+func TestNewTicker(t *testing.T) {
+	now := time.Date(2024, 1, 3, 9, 30, 20, 0, time.UTC)
+	since := now.Add(-24 * time.Hour)
+	bar := func(openTime time.Time, open, high, low, volume float64) *models.Candle {
+		return &models.Candle{OpenTime: openTime, Open: open, High: high, Low: low, Volume: volume}
+	}
+	last := &models.Trade{Price: 105, CreatedAt: now}
+
+	tests := []struct {
+		name string
+		last *models.Trade
+		bars []*models.Candle
+		want *models.Ticker
+	}{
+		{
+			name: "never traded",
+			want: nil,
+		},
+		{
+			name: "window",
+			last: last,
+			bars: []*models.Candle{
+				bar(since.Add(-time.Minute), 50, 200, 10, 7), // Before the window
+				bar(since.Truncate(time.Minute), 100, 110, 90, 2),
+				bar(now.Truncate(time.Minute), 104, 106, 95, 3),
+			},
+			want: &models.Ticker{LastPrice: 105, High: 110, Low: 90, Volume: 5, PriceChange: 5, PriceChangePercent: 5},
+		},
+		{
+			name: "no trades in the window",
+			last: last,
+			bars: []*models.Candle{bar(since.Add(-time.Hour), 100, 105, 100, 4)},
+			want: &models.Ticker{LastPrice: 105, High: 105, Low: 105},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newTicker("TICK", tt.last, tt.bars, since)
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("got %+v, want nil", got)
+				}
+				return
+			}
+			tt.want.Symbol = "TICK"
+			tt.want.LastTradeAt = now
+			if *got != *tt.want {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
@@ -18,7 +19,28 @@ type Config struct {
 	Database string
 }
 
+// Initialize connects to the database and refuses to start if the schema is
+// missing any migration embedded in this binary
 func Initialize(cfg Config) error {
+	db, err := Open(cfg)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := CheckSchema(ctx, db); err != nil {
+		db.Close()
+		return err
+	}
+
+	DB = db
+	return nil
+}
+
+// Open connects to the database without checking the schema version; used by
+// the migrate command
+func Open(cfg Config) (*sql.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true&loc=Local",
 		cfg.User,
 		cfg.Password,
@@ -29,7 +51,7 @@ func Initialize(cfg Config) error {
 
 	db, err := sql.Open("mysql", dsn)
 	if err != nil {
-		return fmt.Errorf("failed to open database connection: %w", err)
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
 	}
 
 	db.SetMaxOpenConns(25)
@@ -37,11 +59,11 @@ func Initialize(cfg Config) error {
 	db.SetConnMaxLifetime(5 * time.Minute)
 
 	if err := db.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	DB = db
-	return nil
+	return db, nil
 }
 
 func Close() error {
@@ -0,0 +1,92 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"order-matching-system/internal/models"
+)
+
+type HaltRepository struct {
+	db DBTX
+}
+
+func NewHaltRepository(db DBTX) *HaltRepository {
+	return &HaltRepository{db: db}
+}
+
+// GetHalt returns the halt in force for symbol, or nil if it is trading
+func (r *HaltRepository) GetHalt(ctx context.Context, symbol string) (*models.TradingHalt, error) {
+	query := `
+		SELECT symbol, reason, halted_by, halted_at
+		FROM trading_halts
+		WHERE symbol = ?
+	`
+
+	halt := &models.TradingHalt{}
+	err := r.db.QueryRowContext(ctx, query, symbol).Scan(&halt.Symbol, &halt.Reason, &halt.HaltedBy, &halt.HaltedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get trading halt: %w", err)
+	}
+
+	return halt, nil
+}
+
+func (r *HaltRepository) GetAllHalts(ctx context.Context) ([]*models.TradingHalt, error) {
+	query := `
+		SELECT symbol, reason, halted_by, halted_at
+		FROM trading_halts
+		ORDER BY symbol
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trading halts: %w", err)
+	}
+	defer rows.Close()
+
+	halts := []*models.TradingHalt{}
+	for rows.Next() {
+		halt := &models.TradingHalt{}
+		if err := rows.Scan(&halt.Symbol, &halt.Reason, &halt.HaltedBy, &halt.HaltedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trading halt: %w", err)
+		}
+		halts = append(halts, halt)
+	}
+
+	return halts, rows.Err()
+}
+
+// SaveHalt halts a symbol, replacing the reason of an existing halt
+func (r *HaltRepository) SaveHalt(ctx context.Context, halt *models.TradingHalt) error {
+	query := `
+		INSERT INTO trading_halts (symbol, reason, halted_by)
+		VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE reason = VALUES(reason), halted_by = VALUES(halted_by)
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, halt.Symbol, halt.Reason, halt.HaltedBy); err != nil {
+		return fmt.Errorf("failed to save trading halt: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteHalt resumes trading in symbol, reporting whether it was halted
+func (r *HaltRepository) DeleteHalt(ctx context.Context, symbol string) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM trading_halts WHERE symbol = ?`, symbol)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete trading halt: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"runtime"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("order-matching-system/internal/database")
+
+// Instrument wraps db so that every statement run through it gets a span,
+// named after the repository method that ran it, under the span in its
+// context, and a debug log line carrying the context's correlation fields
+func Instrument(db DBTX) DBTX {
+	return &instrumentedDBTX{db: db}
+}
+
+type instrumentedDBTX struct {
+	db DBTX
+}
+
+func (d *instrumentedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	start := time.Now()
+	result, err := d.db.ExecContext(ctx, query, args...)
+	endQuery(ctx, span, start, query, err)
+	return result, err
+}
+
+func (d *instrumentedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	ctx, span := startQuerySpan(ctx, query)
+	start := time.Now()
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	endQuery(ctx, span, start, query, err)
+	return rows, err
+}
+
+func (d *instrumentedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	ctx, span := startQuerySpan(ctx, query)
+	start := time.Now()
+	row := d.db.QueryRowContext(ctx, query, args...)
+	endQuery(ctx, span, start, query, row.Err())
+	return row
+}
+
+// startSpan starts a client span when ctx is being traced. Calls outside a
+// trace get a no-op span rather than a new root.
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// startQuerySpan starts a span for query, checking for a trace before walking
+// the stack to name it
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx, trace.SpanFromContext(context.Background())
+	}
+	return startSpan(ctx, callerName(), semconv.DBSystemMySQL, semconv.DBQueryText(compactQuery(query)))
+}
+
+func endQuery(ctx context.Context, span trace.Span, start time.Time, query string, err error) {
+	if err != nil && err != sql.ErrNoRows {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+
+	if !slog.Default().Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+	attrs := []any{"query", compactQuery(query), "duration", time.Since(start)}
+	if err != nil {
+		attrs = append(attrs, "error", err)
+	}
+	slog.DebugContext(ctx, "database query", attrs...)
+}
+
+// callerName returns the function that ran a statement, such as
+// database.OrderRepository.CreateOrder, skipping the DBTX wrappers
+func callerName() string {
+	pcs := make([]uintptr, 8)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.Function, "DBTX).") {
+			name := frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+			return strings.NewReplacer("(*", "", ")", "").Replace(name)
+		}
+		if !more {
+			return "sql"
+		}
+	}
+}
+
+// compactQuery folds a statement's whitespace onto one line
+func compactQuery(query string) string {
+	return strings.Join(strings.Fields(query), " ")
+}
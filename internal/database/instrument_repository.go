@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"order-matching-system/internal/models"
+)
+
+const instrumentColumns = `symbol, matching_algorithm, lot_size, tick_size, min_allocation, top_order_priority,
+	lmm_account_id, lmm_percentage, protection_band_percent, protection_band_ticks, created_at, updated_at`
+
+type InstrumentRepository struct {
+	db DBTX
+}
+
+func NewInstrumentRepository(db DBTX) *InstrumentRepository {
+	return &InstrumentRepository{db: db}
+}
+
+// GetInstrument returns the configuration for symbol, falling back to
+// models.DefaultInstrument when none has been stored
+func (r *InstrumentRepository) GetInstrument(ctx context.Context, symbol string) (*models.Instrument, error) {
+	query := `
+		SELECT ` + instrumentColumns + `
+		FROM instruments
+		WHERE symbol = ?
+	`
+
+	instrument, err := scanInstrument(r.db.QueryRowContext(ctx, query, symbol))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.DefaultInstrument(symbol), nil
+		}
+		return nil, fmt.Errorf("failed to get instrument: %w", err)
+	}
+
+	return instrument, nil
+}
+
+func (r *InstrumentRepository) GetAllInstruments(ctx context.Context) ([]*models.Instrument, error) {
+	query := `
+		SELECT ` + instrumentColumns + `
+		FROM instruments
+		ORDER BY symbol
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instruments: %w", err)
+	}
+	defer rows.Close()
+
+	instruments := []*models.Instrument{}
+	for rows.Next() {
+		instrument, err := scanInstrument(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan instrument: %w", err)
+		}
+		instruments = append(instruments, instrument)
+	}
+
+	return instruments, rows.Err()
+}
+
+// SaveInstrument creates or replaces the configuration for instrument.Symbol
+func (r *InstrumentRepository) SaveInstrument(ctx context.Context, instrument *models.Instrument) error {
+	query := `
+		INSERT INTO instruments (symbol, matching_algorithm, lot_size, tick_size, min_allocation, top_order_priority,
+			lmm_account_id, lmm_percentage, protection_band_percent, protection_band_ticks)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			matching_algorithm = VALUES(matching_algorithm),
+			lot_size = VALUES(lot_size),
+			tick_size = VALUES(tick_size),
+			min_allocation = VALUES(min_allocation),
+			top_order_priority = VALUES(top_order_priority),
+			lmm_account_id = VALUES(lmm_account_id),
+			lmm_percentage = VALUES(lmm_percentage),
+			protection_band_percent = VALUES(protection_band_percent),
+			protection_band_ticks = VALUES(protection_band_ticks)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		instrument.Symbol,
+		instrument.Algorithm,
+		instrument.LotSize,
+		instrument.TickSize,
+		instrument.MinAllocation,
+		instrument.TopOrderPriority,
+		nullIfEmpty(instrument.LMMAccountID),
+		instrument.LMMPercentage,
+		instrument.ProtectionBandPercent,
+		instrument.ProtectionBandTicks,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save instrument: %w", err)
+	}
+
+	return nil
+}
+
+func scanInstrument(row rowScanner) (*models.Instrument, error) {
+	instrument := &models.Instrument{}
+	var lmmAccountID sql.NullString
+
+	err := row.Scan(
+		&instrument.Symbol,
+		&instrument.Algorithm,
+		&instrument.LotSize,
+		&instrument.TickSize,
+		&instrument.MinAllocation,
+		&instrument.TopOrderPriority,
+		&lmmAccountID,
+		&instrument.LMMPercentage,
+		&instrument.ProtectionBandPercent,
+		&instrument.ProtectionBandTicks,
+		&instrument.CreatedAt,
+		&instrument.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lmmAccountID.Valid {
+		instrument.LMMAccountID = lmmAccountID.String
+	}
+
+	return instrument, nil
+}
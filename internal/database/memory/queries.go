@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"sort"
+	"time"
+
+	"order-matching-system/internal/models"
+)
+
+// Read-only views of committed state. They take the store's lock, so they
+// must not be called while a transaction is open on the same goroutine.
+
+// Order returns a copy of the order with id, or nil if there is none
+func (s *Store) Order(id int) *models.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	o, ok := s.orders[id]
+	if !ok {
+		return nil
+	}
+	copied := o.Order
+	return &copied
+}
+
+// OpenOrders returns accountID's resting orders in symbol, oldest first
+func (s *Store) OpenOrders(accountID, symbol string) []*models.Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var orders []*models.Order
+	for id := range s.open[symbol] {
+		if o := s.orders[id]; o.AccountID == accountID {
+			copied := o.Order
+			orders = append(orders, &copied)
+		}
+	}
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders
+}
+
+// Events returns the history of the order with id, oldest first
+func (s *Store) Events(orderID int) []*models.OrderEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var events []*models.OrderEvent
+	for _, e := range s.events {
+		if e.OrderID == orderID {
+			copied := *e
+			events = append(events, &copied)
+		}
+	}
+	return events
+}
+
+// Book returns symbol's order book at the given level of detail, with at
+// most depth entries per side
+func (s *Store) Book(symbol string, level models.BookLevel, depth int) *models.OrderBook {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resting []models.BookOrder
+	for id := range s.open[symbol] {
+		o := s.orders[id]
+		if o.Type != models.OrderTypeLimit {
+			continue
+		}
+		resting = append(resting, models.BookOrder{
+			OrderID:   o.ID,
+			Side:      o.Side,
+			Price:     o.Price,
+			Quantity:  o.RemainingQuantity,
+			CreatedAt: o.priorityAt,
+		})
+	}
+	return models.BuildOrderBook(symbol, level, depth, resting)
+}
+
+// TradesSince returns the trades with IDs above afterID, oldest first
+func (s *Store) TradesSince(afterID int) []*models.Trade {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if afterID < 0 {
+		afterID = 0
+	}
+	var trades []*models.Trade
+	for _, trade := range s.trades[min(afterID, len(s.trades)):] {
+		copied := *trade
+		trades = append(trades, &copied)
+	}
+	return trades
+}
+
+// Candle returns symbol's bar for interval opening at openTime, or nil if no
+// live trade falls in it
+func (s *Store) Candle(symbol string, interval models.CandleInterval, openTime time.Time) *models.Candle {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bar, ok := s.candles[candleKey{symbol, interval, openTime}]
+	if !ok {
+		return nil
+	}
+	return &models.Candle{
+		Symbol: symbol, Interval: interval, OpenTime: openTime,
+		Open: bar.open, High: bar.high, Low: bar.low, Close: bar.close,
+		Volume: bar.volume, VWAP: bar.notional / bar.volume, TradeCount: bar.trades,
+	}
+}
+
+// LastPrice returns the price of the latest live trade in symbol, or zero if
+// it has not traded
+func (s *Store) LastPrice(symbol string) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastPrice(symbol)
+}
+
+// Position returns accountID's position in symbol marked to the last trade
+// price
+func (s *Store) Position(accountID, symbol string) *models.Position {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	position := s.position(accountID, symbol)
+	position.MarkToMarket(s.lastPrice(symbol))
+	return position
+}
+
+// QueuePosition estimates where resting order id stands on its side of the
+// book under price-time priority: the quantity at its price that would trade
+// before it, and the quantity resting at better prices. ok is false if the
+// order is not resting.
+func (s *Store) QueuePosition(id int) (ahead, better float64, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, found := s.orders[id]
+	if !found || target.Status != models.OrderStatusOpen || target.Type != models.OrderTypeLimit {
+		return 0, 0, false
+	}
+
+	for otherID := range s.open[target.Symbol] {
+		o := s.orders[otherID]
+		if o.ID == id || o.Side != target.Side {
+			continue
+		}
+		switch {
+		case sortPrice(o) == target.Price:
+			if o.priorityAt.Before(target.priorityAt) || (o.priorityAt.Equal(target.priorityAt) && o.ID < id) {
+				ahead += o.RemainingQuantity
+			}
+		case (target.Side == models.OrderSideBuy) == (sortPrice(o) > target.Price):
+			better += o.RemainingQuantity
+		}
+	}
+	return models.FromUnits(models.ToUnits(ahead)), models.FromUnits(models.ToUnits(better)), true
+}
@@ -0,0 +1,799 @@
+// Package memory implements database.Store in process, for running the
+// matching engine in simulations and tests without MySQL.
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/models"
+)
+
+// Store keeps the engine's data in maps. One transaction runs at a time:
+// Begin blocks until the previous transaction has committed or rolled back.
+// Records are stamped by the store's clock, which a simulation can drive.
+type Store struct {
+	mu  sync.Mutex // Held by the open transaction
+	now func() time.Time
+
+	orders      map[int]*order
+	open        map[string]map[int]bool // Open order IDs by symbol
+	byAccount   map[string][]int        // Order IDs by account, oldest first
+	idempotency map[string]int
+	nextOrderID int
+
+	trades         []*models.Trade // Indexed by ID - 1
+	tradesBySymbol map[string][]int
+	fillsByAccount map[string][]accountFill
+	corrections    []*models.TradeCorrection
+
+	candles   map[candleKey]*candle
+	positions map[positionKey]*models.Position
+	lots      map[positionKey][]*models.PositionLot
+	nextLotID int64
+
+	events      []*models.OrderEvent
+	outbox      []*models.OutboxEvent
+	published   map[int64]bool // Outbox sequences delivered by a relay
+	halts       map[string]*models.TradingHalt
+	instruments map[string]*models.Instrument
+}
+
+// order is a stored order with the time priority that amendments can reset
+type order struct {
+	models.Order
+	priorityAt time.Time
+}
+
+// accountFill is one side of a trade belonging to an account
+type accountFill struct {
+	tradeID int
+	side    models.OrderSide
+}
+
+type candleKey struct {
+	symbol   string
+	interval models.CandleInterval
+	openTime time.Time
+}
+
+type candle struct {
+	open, high, low, close float64
+	volume, notional       float64
+	trades                 int
+}
+
+type positionKey struct {
+	accountID string
+	symbol    string
+}
+
+// NewStore returns an empty store whose records are stamped by now, or by
+// the wall clock if now is nil
+func NewStore(now func() time.Time) *Store {
+	if now == nil {
+		now = time.Now
+	}
+	return &Store{
+		now:            now,
+		orders:         make(map[int]*order),
+		open:           make(map[string]map[int]bool),
+		byAccount:      make(map[string][]int),
+		idempotency:    make(map[string]int),
+		tradesBySymbol: make(map[string][]int),
+		fillsByAccount: make(map[string][]accountFill),
+		candles:        make(map[candleKey]*candle),
+		positions:      make(map[positionKey]*models.Position),
+		lots:           make(map[positionKey][]*models.PositionLot),
+		published:      make(map[int64]bool),
+		halts:          make(map[string]*models.TradingHalt),
+		instruments:    make(map[string]*models.Instrument),
+	}
+}
+
+func (s *Store) Begin(ctx context.Context) (database.Tx, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	s.mu.Lock()
+	return &tx{store: s}, nil
+}
+
+// SetInstrument configures a symbol's matching algorithm and sizes. Symbols
+// without one use models.DefaultInstrument.
+func (s *Store) SetInstrument(instrument *models.Instrument) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *instrument
+	s.instruments[instrument.Symbol] = &copied
+}
+
+// tx records how to undo each change so that Rollback can restore the store
+type tx struct {
+	store *Store
+	undo  []func()
+	done  bool
+}
+
+func (t *tx) Orders() database.OrderStore           { return (*orderRepo)(t) }
+func (t *tx) Trades() database.TradeStore           { return (*tradeRepo)(t) }
+func (t *tx) Candles() database.CandleStore         { return (*candleRepo)(t) }
+func (t *tx) Positions() database.PositionStore     { return (*positionRepo)(t) }
+func (t *tx) Events() database.OrderEventStore      { return (*eventRepo)(t) }
+func (t *tx) Outbox() database.OutboxStore          { return (*outboxRepo)(t) }
+func (t *tx) Halts() database.HaltStore             { return (*haltRepo)(t) }
+func (t *tx) Instruments() database.InstrumentStore { return (*instrumentRepo)(t) }
+func (t *tx) Risk() database.RiskStore              { return (*riskRepo)(t) }
+
+func (t *tx) Commit() error {
+	if t.done {
+		return fmt.Errorf("failed to commit transaction: transaction already finished")
+	}
+	t.done = true
+	t.undo = nil
+	t.store.mu.Unlock()
+	return nil
+}
+
+func (t *tx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+	t.undo = nil
+	t.store.mu.Unlock()
+	return nil
+}
+
+// Elapsed is always zero: the store never waits on I/O
+func (t *tx) Elapsed() time.Duration {
+	return 0
+}
+
+// put sets m[k], remembering how to restore the previous entry
+func put[K comparable, V any](t *tx, m map[K]V, k K, v V) {
+	prev, had := m[k]
+	m[k] = v
+	t.undo = append(t.undo, func() {
+		if had {
+			m[k] = prev
+		} else {
+			delete(m, k)
+		}
+	})
+}
+
+// remove deletes m[k], remembering how to restore it
+func remove[K comparable, V any](t *tx, m map[K]V, k K) bool {
+	prev, had := m[k]
+	if !had {
+		return false
+	}
+	delete(m, k)
+	t.undo = append(t.undo, func() { m[k] = prev })
+	return true
+}
+
+// push appends v to *s, remembering how to truncate it again
+func push[V any](t *tx, s *[]V, v V) {
+	n := len(*s)
+	*s = append(*s, v)
+	t.undo = append(t.undo, func() { *s = (*s)[:n] })
+}
+
+type orderRepo tx
+
+func (r *orderRepo) CreateOrder(ctx context.Context, o *models.Order) error {
+	t, s := (*tx)(r), r.store
+	if o.IdempotencyKey != "" {
+		if _, taken := s.idempotency[o.IdempotencyKey]; taken {
+			return fmt.Errorf("failed to create order: duplicate idempotency key %q", o.IdempotencyKey)
+		}
+	}
+
+	s.nextOrderID++
+	o.ID = s.nextOrderID
+	o.CreatedAt = s.now()
+	o.UpdatedAt = o.CreatedAt
+
+	put(t, s.orders, o.ID, &order{Order: *o, priorityAt: o.CreatedAt})
+	if o.IdempotencyKey != "" {
+		put(t, s.idempotency, o.IdempotencyKey, o.ID)
+	}
+	if o.AccountID != "" {
+		put(t, s.byAccount, o.AccountID, append(s.byAccount[o.AccountID], o.ID))
+	}
+	if o.Status == models.OrderStatusOpen {
+		r.setOpen(o.Symbol, o.ID, true)
+	}
+	return nil
+}
+
+// setOpen adds or removes an order from its symbol's open set
+func (r *orderRepo) setOpen(symbol string, id int, open bool) {
+	t, s := (*tx)(r), r.store
+	ids := s.open[symbol]
+	if ids == nil {
+		ids = make(map[int]bool)
+		s.open[symbol] = ids
+	}
+	if open {
+		put(t, ids, id, true)
+	} else {
+		remove(t, ids, id)
+	}
+}
+
+// update replaces a stored order, keeping the open set in step
+func (r *orderRepo) update(o *order) {
+	t, s := (*tx)(r), r.store
+	prev := s.orders[o.ID]
+	o.UpdatedAt = s.now()
+	put(t, s.orders, o.ID, o)
+
+	wasOpen := prev.Status == models.OrderStatusOpen
+	isOpen := o.Status == models.OrderStatusOpen
+	if wasOpen != isOpen {
+		r.setOpen(o.Symbol, o.ID, isOpen)
+	}
+}
+
+func (r *orderRepo) GetOrderByID(ctx context.Context, id int) (*models.Order, error) {
+	o, ok := r.store.orders[id]
+	if !ok {
+		return nil, database.ErrOrderNotFound
+	}
+	copied := o.Order
+	return &copied, nil
+}
+
+func (r *orderRepo) GetOrderByIdempotencyKey(ctx context.Context, key string) (*models.Order, error) {
+	id, ok := r.store.idempotency[key]
+	if !ok {
+		return nil, nil
+	}
+	return r.GetOrderByID(ctx, id)
+}
+
+// GetMatchingOrders returns the open orders opposite side in price-time
+// priority, as the SQL repository does
+func (r *orderRepo) GetMatchingOrders(ctx context.Context, symbol string, side models.OrderSide) ([]*models.Order, error) {
+	opposite := models.OrderSideSell
+	if side == models.OrderSideSell {
+		opposite = models.OrderSideBuy
+	}
+
+	var resting []*order
+	for id := range r.store.open[symbol] {
+		if o := r.store.orders[id]; o.Side == opposite {
+			resting = append(resting, o)
+		}
+	}
+	sortByPriority(resting)
+
+	orders := make([]*models.Order, len(resting))
+	for i, o := range resting {
+		copied := o.Order
+		orders[i] = &copied
+	}
+	return orders, nil
+}
+
+// sortByPriority orders one side of a book best price first, market orders
+// ahead of any price, then by time priority and ID
+func sortByPriority(orders []*order) {
+	sort.Slice(orders, func(i, j int) bool {
+		a, b := orders[i], orders[j]
+		pa, pb := sortPrice(a), sortPrice(b)
+		if pa != pb {
+			if a.Side == models.OrderSideBuy {
+				return pa > pb
+			}
+			return pa < pb
+		}
+		if !a.priorityAt.Equal(b.priorityAt) {
+			return a.priorityAt.Before(b.priorityAt)
+		}
+		return a.ID < b.ID
+	})
+}
+
+func sortPrice(o *order) float64 {
+	if o.Type != models.OrderTypeMarket {
+		return o.Price
+	}
+	if o.Side == models.OrderSideBuy {
+		return 999999999
+	}
+	return 0
+}
+
+func (r *orderRepo) UpdateOrderExecution(ctx context.Context, o *models.Order) error {
+	stored, ok := r.store.orders[o.ID]
+	if !ok {
+		return nil
+	}
+	updated := *stored
+	updated.Status = o.Status
+	updated.StatusReason = o.StatusReason
+	updated.RemainingQuantity = o.RemainingQuantity
+	updated.FilledQuantity = o.FilledQuantity
+	updated.AverageFillPrice = o.AverageFillPrice
+	updated.LastFillPrice = o.LastFillPrice
+	updated.LastFillAt = o.LastFillAt
+	r.update(&updated)
+	return nil
+}
+
+func (r *orderRepo) AmendOrder(ctx context.Context, o *models.Order, resetPriority bool) error {
+	stored, ok := r.store.orders[o.ID]
+	if !ok || stored.Status != models.OrderStatusOpen {
+		return nil
+	}
+	updated := *stored
+	updated.Price = o.Price
+	updated.InitialQuantity = o.InitialQuantity
+	updated.RemainingQuantity = o.RemainingQuantity
+	if resetPriority {
+		updated.priorityAt = r.store.now()
+	}
+	r.update(&updated)
+	return nil
+}
+
+// ListOrders returns the orders matching filter, newest first
+func (r *orderRepo) ListOrders(ctx context.Context, filter models.OrderFilter) ([]*models.Order, error) {
+	s := r.store
+
+	var candidates []int
+	switch {
+	case filter.AccountID != "":
+		candidates = s.byAccount[filter.AccountID]
+	case filter.Symbol != "" && filter.Status == models.OrderStatusOpen:
+		for id := range s.open[filter.Symbol] {
+			candidates = append(candidates, id)
+		}
+		sort.Ints(candidates)
+	default:
+		candidates = make([]int, 0, len(s.orders))
+		for id := range s.orders {
+			candidates = append(candidates, id)
+		}
+		sort.Ints(candidates)
+	}
+
+	orders := []*models.Order{}
+	for i := len(candidates) - 1; i >= 0; i-- {
+		o := s.orders[candidates[i]]
+		if (filter.Symbol != "" && o.Symbol != filter.Symbol) ||
+			(filter.AccountID != "" && o.AccountID != filter.AccountID) ||
+			(filter.Side != "" && o.Side != filter.Side) ||
+			(filter.Status != "" && o.Status != filter.Status) ||
+			(filter.BeforeID > 0 && o.ID >= filter.BeforeID) {
+			continue
+		}
+		copied := o.Order
+		orders = append(orders, &copied)
+		if filter.Limit > 0 && len(orders) == filter.Limit {
+			break
+		}
+	}
+	return orders, nil
+}
+
+func (r *orderRepo) CancelOrder(ctx context.Context, id int) error {
+	stored, ok := r.store.orders[id]
+	if !ok || stored.Status != models.OrderStatusOpen {
+		return database.ErrOrderNotOpen
+	}
+	updated := *stored
+	updated.Status = models.OrderStatusCanceled
+	r.update(&updated)
+	return nil
+}
+
+type tradeRepo tx
+
+func (r *tradeRepo) CreateTrade(ctx context.Context, trade *models.Trade) error {
+	t, s := (*tx)(r), r.store
+	if trade.CreatedAt.IsZero() {
+		trade.CreatedAt = s.now()
+	}
+	trade.CreatedAt = trade.CreatedAt.Truncate(time.Second)
+	trade.ID = len(s.trades) + 1
+
+	// Stored like a TIMESTAMP(0) column, which rounds fractional seconds
+	copied := *trade
+	copied.CreatedAt = copied.CreatedAt.Round(time.Second)
+	push(t, &s.trades, &copied)
+
+	put(t, s.tradesBySymbol, trade.Symbol, append(s.tradesBySymbol[trade.Symbol], trade.ID))
+
+	// Index both sides by account, buy first, for positions and risk
+	for _, side := range []struct {
+		orderID int
+		side    models.OrderSide
+	}{{trade.BuyOrderID, models.OrderSideBuy}, {trade.SellOrderID, models.OrderSideSell}} {
+		o, ok := s.orders[side.orderID]
+		if !ok || o.AccountID == "" {
+			continue
+		}
+		put(t, s.fillsByAccount, o.AccountID, append(s.fillsByAccount[o.AccountID], accountFill{tradeID: trade.ID, side: side.side}))
+	}
+	return nil
+}
+
+func (r *tradeRepo) GetTradeByID(ctx context.Context, id int) (*models.Trade, error) {
+	if id < 1 || id > len(r.store.trades) {
+		return nil, database.ErrTradeNotFound
+	}
+	copied := *r.store.trades[id-1]
+	return &copied, nil
+}
+
+func (r *tradeRepo) GetLastPrice(ctx context.Context, symbol string) (float64, error) {
+	return r.store.lastPrice(symbol), nil
+}
+
+func (r *tradeRepo) GetTradesInRange(ctx context.Context, symbol string, from, to time.Time) ([]*models.Trade, error) {
+	var trades []*models.Trade
+	for _, id := range r.store.tradesBySymbol[symbol] {
+		trade := r.store.trades[id-1]
+		if trade.Busted || trade.CreatedAt.Before(from) || !trade.CreatedAt.Before(to) {
+			continue
+		}
+		copied := *trade
+		trades = append(trades, &copied)
+	}
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].CreatedAt.Before(trades[j].CreatedAt)
+	})
+	return trades, nil
+}
+
+func (r *tradeRepo) UpdateTrade(ctx context.Context, trade *models.Trade) error {
+	t, s := (*tx)(r), r.store
+	if trade.ID < 1 || trade.ID > len(s.trades) {
+		return nil
+	}
+	i := trade.ID - 1
+	prev := s.trades[i]
+	updated := *prev
+	updated.Price = trade.Price
+	updated.Quantity = trade.Quantity
+	updated.Busted = trade.Busted
+	updated.Corrected = trade.Corrected
+	s.trades[i] = &updated
+	t.undo = append(t.undo, func() { s.trades[i] = prev })
+	return nil
+}
+
+func (r *tradeRepo) CreateCorrection(ctx context.Context, correction *models.TradeCorrection) error {
+	t, s := (*tx)(r), r.store
+	if correction.CreatedAt.IsZero() {
+		correction.CreatedAt = s.now()
+	}
+	correction.ID = len(s.corrections) + 1
+	copied := *correction
+	push(t, &s.corrections, &copied)
+	return nil
+}
+
+type candleRepo tx
+
+func (r *candleRepo) ApplyTrade(ctx context.Context, trade *models.Trade) error {
+	t, s := (*tx)(r), r.store
+	for _, interval := range models.CandleIntervals {
+		key := candleKey{trade.Symbol, interval, interval.BucketStart(trade.CreatedAt)}
+		bar := &candle{open: trade.Price, high: trade.Price, low: trade.Price}
+		if prev, ok := s.candles[key]; ok {
+			copied := *prev
+			bar = &copied
+		}
+		bar.high = max(bar.high, trade.Price)
+		bar.low = min(bar.low, trade.Price)
+		bar.close = trade.Price
+		bar.volume += trade.Quantity
+		bar.notional += trade.Price * trade.Quantity
+		bar.trades++
+		put(t, s.candles, key, bar)
+	}
+	return nil
+}
+
+func (r *candleRepo) ReplaceBar(ctx context.Context, symbol string, interval models.CandleInterval, openTime time.Time, trades []*models.Trade) error {
+	t, s := (*tx)(r), r.store
+	key := candleKey{symbol, interval, openTime}
+	if len(trades) == 0 {
+		remove(t, s.candles, key)
+		return nil
+	}
+
+	first, last := trades[0], trades[len(trades)-1]
+	bar := &candle{open: first.Price, high: first.Price, low: first.Price, close: last.Price, trades: len(trades)}
+	for _, trade := range trades {
+		bar.high = max(bar.high, trade.Price)
+		bar.low = min(bar.low, trade.Price)
+		bar.volume += trade.Quantity
+		bar.notional += trade.Price * trade.Quantity
+	}
+	put(t, s.candles, key, bar)
+	return nil
+}
+
+type positionRepo tx
+
+func (r *positionRepo) GetPosition(ctx context.Context, accountID, symbol string) (*models.Position, error) {
+	return r.store.position(accountID, symbol), nil
+}
+
+func (r *positionRepo) SavePosition(ctx context.Context, position *models.Position) error {
+	copied := *position
+	copied.UpdatedAt = r.store.now()
+	put((*tx)(r), r.store.positions, positionKey{position.AccountID, position.Symbol}, &copied)
+	return nil
+}
+
+func (r *positionRepo) GetLots(ctx context.Context, accountID, symbol string) ([]*models.PositionLot, error) {
+	var lots []*models.PositionLot
+	for _, lot := range r.store.lots[positionKey{accountID, symbol}] {
+		copied := *lot
+		lots = append(lots, &copied)
+	}
+	return lots, nil
+}
+
+// setLots replaces the open lots of one position
+func (r *positionRepo) setLots(key positionKey, lots []*models.PositionLot) {
+	if len(lots) == 0 {
+		remove((*tx)(r), r.store.lots, key)
+		return
+	}
+	put((*tx)(r), r.store.lots, key, lots)
+}
+
+// editLot copies the lots of the position holding lot id, lets edit change
+// them and stores the result
+func (r *positionRepo) editLot(id int64, edit func(lots []*models.PositionLot, i int) []*models.PositionLot) {
+	for key, lots := range r.store.lots {
+		for i, lot := range lots {
+			if lot.ID == id {
+				copied := append([]*models.PositionLot(nil), lots...)
+				r.setLots(key, edit(copied, i))
+				return
+			}
+		}
+	}
+}
+
+func (r *positionRepo) CreateLot(ctx context.Context, lot *models.PositionLot) error {
+	s := r.store
+	s.nextLotID++
+	lot.ID = s.nextLotID
+	lot.CreatedAt = s.now()
+
+	key := positionKey{lot.AccountID, lot.Symbol}
+	copied := *lot
+	lots := append(append([]*models.PositionLot(nil), s.lots[key]...), &copied)
+	r.setLots(key, lots)
+	return nil
+}
+
+func (r *positionRepo) UpdateLotQuantity(ctx context.Context, id int64, quantity float64) error {
+	r.editLot(id, func(lots []*models.PositionLot, i int) []*models.PositionLot {
+		updated := *lots[i]
+		updated.Quantity = quantity
+		lots[i] = &updated
+		return lots
+	})
+	return nil
+}
+
+func (r *positionRepo) DeleteLot(ctx context.Context, id int64) error {
+	r.editLot(id, func(lots []*models.PositionLot, i int) []*models.PositionLot {
+		return append(lots[:i], lots[i+1:]...)
+	})
+	return nil
+}
+
+func (r *positionRepo) DeleteLots(ctx context.Context, accountID, symbol string) error {
+	r.setLots(positionKey{accountID, symbol}, nil)
+	return nil
+}
+
+func (r *positionRepo) GetAccountFills(ctx context.Context, accountID, symbol string) ([]*models.AccountFill, error) {
+	var fills []*models.AccountFill
+	for _, f := range r.store.fillsByAccount[accountID] {
+		trade := r.store.trades[f.tradeID-1]
+		if trade.Symbol != symbol || trade.Busted {
+			continue
+		}
+		quantity := trade.Quantity
+		if f.side == models.OrderSideSell {
+			quantity = -quantity
+		}
+		fills = append(fills, &models.AccountFill{TradeID: trade.ID, Quantity: quantity, Price: trade.Price})
+	}
+	return fills, nil
+}
+
+type eventRepo tx
+
+func (r *eventRepo) CreateEvent(ctx context.Context, event *models.OrderEvent) error {
+	s := r.store
+	event.ID = int64(len(s.events) + 1)
+	event.CreatedAt = s.now()
+	copied := *event
+	push((*tx)(r), &s.events, &copied)
+	return nil
+}
+
+type outboxRepo tx
+
+func (r *outboxRepo) Append(ctx context.Context, eventType, aggregateType string, aggregateID int, symbol string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	s := r.store
+	push((*tx)(r), &s.outbox, &models.OutboxEvent{
+		Sequence:      int64(len(s.outbox) + 1),
+		Type:          eventType,
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		Symbol:        symbol,
+		Payload:       body,
+		CreatedAt:     s.now(),
+	})
+	return nil
+}
+
+// Outbox lets an events.Relay deliver the store's committed outbox events.
+// Like the read-only views it takes the store's lock.
+type Outbox Store
+
+func (s *Store) Outbox() *Outbox {
+	return (*Outbox)(s)
+}
+
+func (o *Outbox) GetUnpublished(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var events []*models.OutboxEvent
+	for _, event := range o.outbox {
+		if len(events) == limit {
+			break
+		}
+		if !o.published[event.Sequence] {
+			copied := *event
+			events = append(events, &copied)
+		}
+	}
+	return events, nil
+}
+
+func (o *Outbox) MarkPublished(ctx context.Context, sequences []int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	for _, sequence := range sequences {
+		o.published[sequence] = true
+	}
+	return nil
+}
+
+type haltRepo tx
+
+func (r *haltRepo) GetHalt(ctx context.Context, symbol string) (*models.TradingHalt, error) {
+	halt, ok := r.store.halts[symbol]
+	if !ok {
+		return nil, nil
+	}
+	copied := *halt
+	return &copied, nil
+}
+
+func (r *haltRepo) SaveHalt(ctx context.Context, halt *models.TradingHalt) error {
+	copied := *halt
+	copied.HaltedAt = r.store.now()
+	if prev, ok := r.store.halts[halt.Symbol]; ok {
+		copied.HaltedAt = prev.HaltedAt
+	}
+	put((*tx)(r), r.store.halts, halt.Symbol, &copied)
+	return nil
+}
+
+func (r *haltRepo) DeleteHalt(ctx context.Context, symbol string) (bool, error) {
+	return remove((*tx)(r), r.store.halts, symbol), nil
+}
+
+type instrumentRepo tx
+
+func (r *instrumentRepo) GetInstrument(ctx context.Context, symbol string) (*models.Instrument, error) {
+	instrument, ok := r.store.instruments[symbol]
+	if !ok {
+		return models.DefaultInstrument(symbol), nil
+	}
+	copied := *instrument
+	return &copied, nil
+}
+
+type riskRepo tx
+
+func (r *riskRepo) CountOpenOrders(ctx context.Context, accountID, symbol string) (int, error) {
+	count := 0
+	for id := range r.store.open[symbol] {
+		if r.store.orders[id].AccountID == accountID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *riskRepo) CountOrdersSince(ctx context.Context, accountID string, since time.Time) (int, error) {
+	ids := r.store.byAccount[accountID]
+	count := 0
+	for i := len(ids) - 1; i >= 0; i-- {
+		o := r.store.orders[ids[i]]
+		if o.CreatedAt.Before(since) {
+			break
+		}
+		if o.Status != models.OrderStatusRejected {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *riskRepo) GetNetPosition(ctx context.Context, accountID, symbol string) (float64, error) {
+	return r.store.position(accountID, symbol).Quantity, nil
+}
+
+func (r *riskRepo) GetTradingPnLSince(ctx context.Context, accountID string, since time.Time) (float64, error) {
+	var pnl float64
+	for _, f := range r.store.fillsByAccount[accountID] {
+		trade := r.store.trades[f.tradeID-1]
+		if trade.Busted || trade.CreatedAt.Before(since) {
+			continue
+		}
+		last := r.store.lastPrice(trade.Symbol)
+		if f.side == models.OrderSideBuy {
+			pnl += trade.Quantity * (last - trade.Price)
+		} else {
+			pnl += trade.Quantity * (trade.Price - last)
+		}
+	}
+	return pnl, nil
+}
+
+// lastPrice returns the price of the latest live trade in symbol, or zero
+func (s *Store) lastPrice(symbol string) float64 {
+	ids := s.tradesBySymbol[symbol]
+	for i := len(ids) - 1; i >= 0; i-- {
+		if trade := s.trades[ids[i]-1]; !trade.Busted {
+			return trade.Price
+		}
+	}
+	return 0
+}
+
+// position returns a copy of accountID's position in symbol, flat if none
+func (s *Store) position(accountID, symbol string) *models.Position {
+	position, ok := s.positions[positionKey{accountID, symbol}]
+	if !ok {
+		return &models.Position{AccountID: accountID, Symbol: symbol}
+	}
+	copied := *position
+	return &copied
+}
@@ -0,0 +1,240 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/models"
+)
+
+// newClockedStore returns a store whose clock is read from *now
+func newClockedStore() (*Store, *time.Time) {
+	now := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+	return NewStore(func() time.Time { return now }), &now
+}
+
+func begin(t *testing.T, s *Store) database.Tx {
+	t.Helper()
+	tx, err := s.Begin(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+func restingOrder(side models.OrderSide, price float64) *models.Order {
+	order := &models.Order{
+		Symbol:            "MEM",
+		Side:              side,
+		Type:              models.OrderTypeLimit,
+		Price:             price,
+		InitialQuantity:   1,
+		RemainingQuantity: 1,
+		Status:            models.OrderStatusOpen,
+	}
+	if price == 0 {
+		order.Type = models.OrderTypeMarket
+	}
+	return order
+}
+
+func ids(orders []*models.Order) []int {
+	ids := make([]int, len(orders))
+	for i, o := range orders {
+		ids[i] = o.ID
+	}
+	return ids
+}
+
+func TestMatchingOrdersPriority(t *testing.T) {
+	s, now := newClockedStore()
+	ctx := context.Background()
+	tx := begin(t, s)
+	orders := tx.Orders()
+
+	create := func(o *models.Order) int {
+		t.Helper()
+		if err := orders.CreateOrder(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+		*now = now.Add(time.Second)
+		return o.ID
+	}
+	sell101 := create(restingOrder(models.OrderSideSell, 101))
+	sell100a := create(restingOrder(models.OrderSideSell, 100))
+	sell100b := create(restingOrder(models.OrderSideSell, 100))
+	sellMarket := create(restingOrder(models.OrderSideSell, 0))
+	at := *now
+	buy99 := create(restingOrder(models.OrderSideBuy, 99))
+	buy98 := create(restingOrder(models.OrderSideBuy, 98))
+
+	// Orders placed at the same time keep the order they were created in
+	*now = at
+	buy99b := create(restingOrder(models.OrderSideBuy, 99))
+
+	tests := []struct {
+		name string
+		side models.OrderSide
+		want []int
+	}{
+		{"asks for a buy", models.OrderSideBuy, []int{sellMarket, sell100a, sell100b, sell101}},
+		{"bids for a sell", models.OrderSideSell, []int{buy99, buy99b, buy98}},
+	}
+	for _, tt := range tests {
+		got, err := orders.GetMatchingOrders(ctx, "MEM", tt.side)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !slices.Equal(ids(got), tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, ids(got), tt.want)
+		}
+	}
+
+	// Resetting priority sends an order to the back of its level
+	first, _ := orders.GetOrderByID(ctx, sell100a)
+	first.RemainingQuantity = 2
+	first.InitialQuantity = 2
+	if err := orders.AmendOrder(ctx, first, true); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := orders.GetMatchingOrders(ctx, "MEM", models.OrderSideBuy)
+	if want := []int{sellMarket, sell100b, sell100a, sell101}; !slices.Equal(ids(got), want) {
+		t.Fatalf("after amendment got %v, want %v", ids(got), want)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRollbackDiscardsWrites(t *testing.T) {
+	s, _ := newClockedStore()
+	ctx := context.Background()
+
+	tx := begin(t, s)
+	sell := restingOrder(models.OrderSideSell, 100)
+	sell.AccountID = "acct-s"
+	if err := tx.Orders().CreateOrder(ctx, sell); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	tx = begin(t, s)
+	buy := restingOrder(models.OrderSideBuy, 100)
+	buy.AccountID = "acct-b"
+	buy.IdempotencyKey = "key"
+	if err := tx.Orders().CreateOrder(ctx, buy); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Orders().CancelOrder(ctx, sell.ID); err != nil {
+		t.Fatal(err)
+	}
+	trade := &models.Trade{Symbol: "MEM", BuyOrderID: buy.ID, SellOrderID: sell.ID, Price: 100, Quantity: 1}
+	if err := tx.Trades().CreateTrade(ctx, trade); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Events().CreateEvent(ctx, &models.OrderEvent{OrderID: sell.ID, Type: models.OrderEventCanceled}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Outbox().Append(ctx, models.EventTradeExecuted, models.AggregateTrade, trade.ID, "MEM", trade); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := s.Order(buy.ID); got != nil {
+		t.Fatalf("rolled back order %d is still stored", buy.ID)
+	}
+	if got := s.Order(sell.ID); got.Status != models.OrderStatusOpen {
+		t.Fatalf("sell is %s, want the cancel undone", got.Status)
+	}
+	if open := s.OpenOrders("acct-s", "MEM"); len(open) != 1 {
+		t.Fatalf("%d open sells, want 1", len(open))
+	}
+	if price := s.LastPrice("MEM"); price != 0 {
+		t.Fatalf("last price %v, want no trades", price)
+	}
+	if events := s.Events(sell.ID); len(events) != 0 {
+		t.Fatalf("%d events, want none", len(events))
+	}
+	if pending, _ := s.Outbox().GetUnpublished(ctx, 10); len(pending) != 0 {
+		t.Fatalf("%d outbox events, want none", len(pending))
+	}
+
+	// The idempotency key is free to use again
+	tx = begin(t, s)
+	defer tx.Rollback()
+	again := restingOrder(models.OrderSideBuy, 100)
+	again.IdempotencyKey = "key"
+	if err := tx.Orders().CreateOrder(ctx, again); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMissingRecords(t *testing.T) {
+	s, _ := newClockedStore()
+	ctx := context.Background()
+	tx := begin(t, s)
+	defer tx.Rollback()
+
+	if _, err := tx.Orders().GetOrderByID(ctx, 1); !errors.Is(err, database.ErrOrderNotFound) {
+		t.Fatalf("GetOrderByID got %v, want ErrOrderNotFound", err)
+	}
+	if _, err := tx.Trades().GetTradeByID(ctx, 1); !errors.Is(err, database.ErrTradeNotFound) {
+		t.Fatalf("GetTradeByID got %v, want ErrTradeNotFound", err)
+	}
+
+	order := restingOrder(models.OrderSideBuy, 100)
+	if err := tx.Orders().CreateOrder(ctx, order); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Orders().CancelOrder(ctx, order.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Orders().CancelOrder(ctx, order.ID); !errors.Is(err, database.ErrOrderNotOpen) {
+		t.Fatalf("second cancel got %v, want ErrOrderNotOpen", err)
+	}
+}
+
+func TestListOrdersPages(t *testing.T) {
+	s, _ := newClockedStore()
+	ctx := context.Background()
+	tx := begin(t, s)
+	defer tx.Rollback()
+	orders := tx.Orders()
+
+	for i := 0; i < 5; i++ {
+		if err := orders.CreateOrder(ctx, restingOrder(models.OrderSideBuy, 100)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := orders.CancelOrder(ctx, 4); err != nil {
+		t.Fatal(err)
+	}
+
+	filter := models.OrderFilter{Symbol: "MEM", Status: models.OrderStatusOpen, Limit: 2}
+	var pages [][]int
+	for {
+		page, err := orders.ListOrders(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, ids(page))
+		if len(page) < filter.Limit {
+			break
+		}
+		filter.BeforeID = page[len(page)-1].ID
+	}
+
+	want := [][]int{{5, 3}, {2, 1}, {}}
+	if !slices.EqualFunc(pages, want, slices.Equal[[]int]) {
+		t.Fatalf("pages %v, want %v", pages, want)
+	}
+}
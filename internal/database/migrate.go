@@ -0,0 +1,310 @@
+package database
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migration is one versioned schema change with its up and down scripts
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationState reports whether a migration has been applied to a database
+type MigrationState struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrations returns the embedded migrations sorted by version. Files are
+// named NNNN_description.up.sql and NNNN_description.down.sql.
+func Migrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		name := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(name, "."+direction+".sql")
+		versionStr, label, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s is not named NNNN_description", name)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has invalid version: %w", name, err)
+		}
+
+		body, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: label}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.Up = string(body)
+		} else {
+			m.Down = string(body)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s is missing its up or down script", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+// LatestVersion returns the highest embedded migration version
+func LatestVersion() (int, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return 0, err
+	}
+	if len(migrations) == 0 {
+		return 0, nil
+	}
+	return migrations[len(migrations)-1].Version, nil
+}
+
+func ensureMigrationsTable(ctx context.Context, db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4
+	`
+
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[int]time.Time, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// MigrationStatus lists every embedded migration and when it was applied
+func MigrationStatus(ctx context.Context, db *sql.DB) ([]MigrationState, error) {
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return nil, err
+	}
+
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(migrations))
+	for _, m := range migrations {
+		state := MigrationState{Migration: m}
+		if at, ok := applied[m.Version]; ok {
+			state.AppliedAt = &at
+		}
+		states = append(states, state)
+	}
+
+	return states, nil
+}
+
+// MigrateUp applies every pending migration in version order and returns the
+// migrations it applied. MySQL commits DDL implicitly, so a migration that
+// fails part way must be repaired by hand before retrying.
+func MigrateUp(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	states, err := MigrationStatus(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, state := range states {
+		if state.AppliedAt != nil {
+			continue
+		}
+
+		if err := execScript(ctx, db, state.Up); err != nil {
+			return ran, fmt.Errorf("migration %04d_%s failed: %w", state.Version, state.Name, err)
+		}
+
+		_, err := db.ExecContext(ctx,
+			`INSERT INTO schema_migrations (version, name) VALUES (?, ?)`,
+			state.Version, state.Name,
+		)
+		if err != nil {
+			return ran, fmt.Errorf("failed to record migration %04d: %w", state.Version, err)
+		}
+		ran = append(ran, state.Migration)
+	}
+
+	return ran, nil
+}
+
+// MigrateDown reverts the most recently applied migration. It returns nil if
+// no migrations have been applied.
+func MigrateDown(ctx context.Context, db *sql.DB) (*Migration, error) {
+	states, err := MigrationStatus(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(states) - 1; i >= 0; i-- {
+		state := states[i]
+		if state.AppliedAt == nil {
+			continue
+		}
+
+		if err := execScript(ctx, db, state.Down); err != nil {
+			return nil, fmt.Errorf("reverting migration %04d_%s failed: %w", state.Version, state.Name, err)
+		}
+
+		_, err := db.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, state.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unrecord migration %04d: %w", state.Version, err)
+		}
+		return &state.Migration, nil
+	}
+
+	return nil, nil
+}
+
+// CheckSchema returns an error if the database is missing any migration
+// embedded in this binary
+func CheckSchema(ctx context.Context, db *sql.DB) error {
+	states, err := MigrationStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	for _, state := range states {
+		if state.AppliedAt == nil {
+			pending = append(pending, fmt.Sprintf("%04d_%s", state.Version, state.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is outdated, pending migrations: %s (run `migrate up`)", strings.Join(pending, ", "))
+	}
+
+	return nil
+}
+
+// execScript runs each statement of a migration script in turn, since the
+// driver does not accept multiple statements in a single Exec
+func execScript(ctx context.Context, db *sql.DB, script string) error {
+	for _, stmt := range splitStatements(script) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitStatements splits a script on lines ending in a semicolon, dropping
+// comments
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	for scanner.Scan() {
+		line := stripComment(scanner.Text())
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			stmt := strings.TrimSuffix(strings.TrimSpace(current.String()), ";")
+			statements = append(statements, stmt)
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
+
+// stripComment removes a -- comment from line, ignoring dashes inside quoted
+// strings, so that a statement ending before a comment is still split
+func stripComment(line string) string {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '-' && strings.HasPrefix(line[i:], "--"):
+			return strings.TrimRight(line[:i], " \t")
+		}
+	}
+	return line
+}
@@ -0,0 +1,80 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestSplitStatementsMigrations checks that every embedded migration splits
+// into single statements, since the driver rejects multi-statement Execs
+func TestSplitStatementsMigrations(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range migrations {
+		for direction, script := range map[string]string{"up": m.Up, "down": m.Down} {
+			t.Run(fmt.Sprintf("%04d_%s.%s", m.Version, m.Name, direction), func(t *testing.T) {
+				statements := splitStatements(script)
+				if len(statements) == 0 {
+					t.Fatal("no statements")
+				}
+				for i, stmt := range statements {
+					if n := strings.Count(stripQuoted(stmt), ";"); n != 0 {
+						t.Errorf("chunk %d holds %d statements:\n%s", i, n+1, stmt)
+					}
+					if strings.Contains(stripQuoted(stmt), "--") {
+						t.Errorf("chunk %d keeps a comment:\n%s", i, stmt)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	script := `-- Leading comment
+ALTER TABLE t
+    ADD COLUMN a INT, -- trailing
+    ADD COLUMN b INT; -- ends the statement
+
+INSERT INTO t (s) VALUES ('a -- b;');
+UPDATE t SET a = 1`
+
+	want := []string{
+		"ALTER TABLE t\n    ADD COLUMN a INT,\n    ADD COLUMN b INT",
+		"INSERT INTO t (s) VALUES ('a -- b;')",
+		"UPDATE t SET a = 1",
+	}
+	got := splitStatements(script)
+	if len(got) != len(want) {
+		t.Fatalf("got %d statements %q, want %d", len(got), got, len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("statement %d = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+// stripQuoted removes quoted strings so their contents are not mistaken for
+// SQL syntax
+func stripQuoted(stmt string) string {
+	var b strings.Builder
+	var quote rune
+	for _, r := range stmt {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,49 @@
+-- Create orders table
+CREATE TABLE IF NOT EXISTS orders (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    symbol VARCHAR(20) NOT NULL,
+    side ENUM('buy', 'sell') NOT NULL,
+    type ENUM('limit', 'market') NOT NULL,
+    price DECIMAL(18, 8) NULL, -- NULL for market orders
+    initial_quantity DECIMAL(18, 8) NOT NULL,
+    remaining_quantity DECIMAL(18, 8) NOT NULL,
+    status ENUM('open', 'filled', 'canceled') NOT NULL DEFAULT 'open',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    
+    INDEX idx_symbol_status (symbol, status),
+    INDEX idx_symbol_side_price (symbol, side, price),
+    INDEX idx_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Create trades table
+CREATE TABLE IF NOT EXISTS trades (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    symbol VARCHAR(20) NOT NULL,
+    buy_order_id INT NOT NULL,
+    sell_order_id INT NOT NULL,
+    price DECIMAL(18, 8) NOT NULL,
+    quantity DECIMAL(18, 8) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    
+    INDEX idx_symbol (symbol),
+    INDEX idx_created_at (created_at),
+    FOREIGN KEY (buy_order_id) REFERENCES orders(id),
+    FOREIGN KEY (sell_order_id) REFERENCES orders(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Create candles table (OHLCV bars maintained as trades are created)
+CREATE TABLE IF NOT EXISTS candles (
+    symbol VARCHAR(20) NOT NULL,
+    bar_interval ENUM('1m', '5m', '1h', '1d') NOT NULL,
+    open_time TIMESTAMP NOT NULL,
+    open_price DECIMAL(18, 8) NOT NULL,
+    high_price DECIMAL(18, 8) NOT NULL,
+    low_price DECIMAL(18, 8) NOT NULL,
+    close_price DECIMAL(18, 8) NOT NULL,
+    volume DECIMAL(28, 8) NOT NULL,
+    notional DECIMAL(36, 8) NOT NULL, -- Sum of price * quantity, for VWAP
+    trade_count INT NOT NULL,
+    
+    PRIMARY KEY (symbol, bar_interval, open_time)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,18 @@
+-- Create order_events table (audit trail of order state transitions)
+CREATE TABLE IF NOT EXISTS order_events (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    order_id INT NOT NULL,
+    event_type ENUM('accepted', 'partially_filled', 'filled', 'canceled', 'expired', 'amended', 'rejected') NOT NULL,
+    status_before ENUM('open', 'filled', 'canceled') NULL, -- NULL for the accepted event
+    status_after ENUM('open', 'filled', 'canceled') NOT NULL,
+    remaining_before DECIMAL(18, 8) NOT NULL,
+    remaining_after DECIMAL(18, 8) NOT NULL,
+    trade_id INT NULL, -- Set for fills
+    cause VARCHAR(255) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    
+    INDEX idx_order_id (order_id, id),
+    FOREIGN KEY (order_id) REFERENCES orders(id),
+    FOREIGN KEY (trade_id) REFERENCES trades(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,5 @@
+ALTER TABLE orders
+    DROP COLUMN last_fill_at,
+    DROP COLUMN last_fill_price,
+    DROP COLUMN average_fill_price,
+    DROP COLUMN filled_quantity;
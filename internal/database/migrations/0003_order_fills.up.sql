@@ -0,0 +1,30 @@
+-- Track execution summary on each order
+ALTER TABLE orders
+    ADD COLUMN filled_quantity DECIMAL(18, 8) NOT NULL DEFAULT 0 AFTER remaining_quantity,
+    ADD COLUMN average_fill_price DECIMAL(18, 8) NULL AFTER filled_quantity,
+    ADD COLUMN last_fill_price DECIMAL(18, 8) NULL AFTER average_fill_price,
+    ADD COLUMN last_fill_at TIMESTAMP NULL AFTER last_fill_price;
+
+-- Backfill from existing trades
+UPDATE orders o
+JOIN (
+    SELECT order_id,
+        SUM(quantity) AS filled_quantity,
+        SUM(price * quantity) / SUM(quantity) AS average_fill_price,
+        MAX(created_at) AS last_fill_at
+    FROM (
+        SELECT buy_order_id AS order_id, price, quantity, created_at FROM trades
+        UNION ALL
+        SELECT sell_order_id AS order_id, price, quantity, created_at FROM trades
+    ) fills
+    GROUP BY order_id
+) f ON f.order_id = o.id
+SET o.filled_quantity = f.filled_quantity,
+    o.average_fill_price = f.average_fill_price,
+    o.last_fill_at = f.last_fill_at,
+    o.last_fill_price = (
+        SELECT t.price FROM trades t
+        WHERE t.buy_order_id = o.id OR t.sell_order_id = o.id
+        ORDER BY t.created_at DESC, t.id DESC
+        LIMIT 1
+    );
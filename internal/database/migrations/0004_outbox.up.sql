@@ -0,0 +1,13 @@
+-- Create outbox_events table (transactional outbox for downstream publishing)
+CREATE TABLE IF NOT EXISTS outbox_events (
+    sequence BIGINT AUTO_INCREMENT PRIMARY KEY, -- Consumer-visible, for dedup and ordering
+    event_type VARCHAR(50) NOT NULL,
+    aggregate_type VARCHAR(20) NOT NULL,
+    aggregate_id INT NOT NULL,
+    symbol VARCHAR(20) NOT NULL,
+    payload JSON NOT NULL,
+    created_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+    published_at TIMESTAMP(6) NULL,
+    
+    INDEX idx_published_sequence (published_at, sequence)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,10 @@
+-- Create book_snapshots table (periodic per-symbol copies of the resting book)
+CREATE TABLE IF NOT EXISTS book_snapshots (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    symbol VARCHAR(20) NOT NULL,
+    taken_at TIMESTAMP(6) NOT NULL,
+    last_event_id BIGINT NOT NULL, -- Highest order_events.id reflected in the snapshot
+    orders JSON NOT NULL,
+    
+    INDEX idx_symbol_taken_at (symbol, taken_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS instruments;
+
+ALTER TABLE orders
+    DROP INDEX idx_account_symbol_status,
+    DROP COLUMN account_id;
@@ -0,0 +1,17 @@
+-- Attribute orders to accounts
+ALTER TABLE orders
+    ADD COLUMN account_id VARCHAR(64) NULL AFTER id,
+    ADD INDEX idx_account_symbol_status (account_id, symbol, status);
+
+-- Create instruments table (per-symbol matching configuration)
+CREATE TABLE IF NOT EXISTS instruments (
+    symbol VARCHAR(20) PRIMARY KEY,
+    matching_algorithm ENUM('fifo', 'fifo_lmm', 'pro_rata') NOT NULL DEFAULT 'fifo',
+    lot_size DECIMAL(18, 8) NOT NULL DEFAULT 0.00000001, -- Pro-rata allocations are rounded down to whole lots
+    min_allocation DECIMAL(18, 8) NOT NULL DEFAULT 0, -- Pro-rata allocations below this are reassigned in time priority
+    top_order_priority BOOLEAN NOT NULL DEFAULT FALSE, -- Oldest order at the level is filled before pro-rata
+    lmm_account_id VARCHAR(64) NULL, -- Lead market maker
+    lmm_percentage DECIMAL(5, 2) NOT NULL DEFAULT 0, -- Share of each match reserved for the LMM
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
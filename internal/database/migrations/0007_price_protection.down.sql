@@ -0,0 +1,8 @@
+ALTER TABLE orders
+    DROP COLUMN status_reason,
+    DROP COLUMN max_slippage;
+
+ALTER TABLE instruments
+    DROP COLUMN protection_band_ticks,
+    DROP COLUMN protection_band_percent,
+    DROP COLUMN tick_size;
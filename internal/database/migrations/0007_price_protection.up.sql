@@ -0,0 +1,10 @@
+-- Market order price protection
+ALTER TABLE instruments
+    ADD COLUMN tick_size DECIMAL(18, 8) NOT NULL DEFAULT 0.01 AFTER lot_size,
+    -- Protection bands: 0 disables
+    ADD COLUMN protection_band_percent DECIMAL(7, 4) NOT NULL DEFAULT 0 AFTER lmm_percentage,
+    ADD COLUMN protection_band_ticks INT NOT NULL DEFAULT 0 AFTER protection_band_percent;
+
+ALTER TABLE orders
+    ADD COLUMN max_slippage DECIMAL(18, 8) NULL AFTER price, -- Market orders only: max price distance from best at arrival
+    ADD COLUMN status_reason VARCHAR(255) NULL AFTER status;
@@ -0,0 +1,12 @@
+-- Rejected orders are kept as canceled so their audit trail survives
+UPDATE order_events SET status_after = 'canceled' WHERE status_after = 'rejected';
+UPDATE orders SET status = 'canceled' WHERE status = 'rejected';
+
+ALTER TABLE order_events
+    MODIFY COLUMN status_before ENUM('open', 'filled', 'canceled') NULL,
+    MODIFY COLUMN status_after ENUM('open', 'filled', 'canceled') NOT NULL;
+
+ALTER TABLE orders
+    DROP INDEX idx_account_created_at,
+    DROP COLUMN reject_code,
+    MODIFY COLUMN status ENUM('open', 'filled', 'canceled') NOT NULL DEFAULT 'open';
@@ -0,0 +1,9 @@
+-- Pre-trade risk checks: orders can be rejected before they reach the book
+ALTER TABLE orders
+    MODIFY COLUMN status ENUM('open', 'filled', 'canceled', 'rejected') NOT NULL DEFAULT 'open',
+    ADD COLUMN reject_code VARCHAR(32) NULL AFTER status_reason, -- Set for rejected orders
+    ADD INDEX idx_account_created_at (account_id, created_at);
+
+ALTER TABLE order_events
+    MODIFY COLUMN status_before ENUM('open', 'filled', 'canceled', 'rejected') NULL,
+    MODIFY COLUMN status_after ENUM('open', 'filled', 'canceled', 'rejected') NOT NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS position_lots;
+DROP TABLE IF EXISTS positions;
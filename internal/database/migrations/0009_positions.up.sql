@@ -0,0 +1,24 @@
+-- Create positions table (net holding and PnL per account and symbol)
+CREATE TABLE IF NOT EXISTS positions (
+    account_id VARCHAR(64) NOT NULL,
+    symbol VARCHAR(20) NOT NULL,
+    quantity DECIMAL(18, 8) NOT NULL DEFAULT 0, -- Negative when short
+    average_cost DECIMAL(18, 8) NOT NULL DEFAULT 0,
+    realized_pnl DECIMAL(28, 8) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (account_id, symbol)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+-- Create position_lots table (open lots, oldest first, for FIFO cost)
+CREATE TABLE IF NOT EXISTS position_lots (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    account_id VARCHAR(64) NOT NULL,
+    symbol VARCHAR(20) NOT NULL,
+    quantity DECIMAL(18, 8) NOT NULL, -- Signed like the position
+    price DECIMAL(18, 8) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_account_symbol (account_id, symbol, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,10 @@
+DELETE FROM order_events WHERE event_type IN ('trade_busted', 'trade_corrected');
+
+ALTER TABLE order_events
+    MODIFY COLUMN event_type ENUM('accepted', 'partially_filled', 'filled', 'canceled', 'expired', 'amended', 'rejected') NOT NULL;
+
+DROP TABLE IF EXISTS trade_corrections;
+
+ALTER TABLE trades
+    DROP COLUMN corrected,
+    DROP COLUMN busted;
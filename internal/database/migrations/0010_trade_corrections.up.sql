@@ -0,0 +1,24 @@
+-- Operators can bust (void) or correct trades; originals are kept
+ALTER TABLE trades
+    ADD COLUMN busted BOOLEAN NOT NULL DEFAULT FALSE AFTER quantity,
+    ADD COLUMN corrected BOOLEAN NOT NULL DEFAULT FALSE AFTER busted;
+
+-- Create trade_corrections table (audit of every bust and correction)
+CREATE TABLE IF NOT EXISTS trade_corrections (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    trade_id INT NOT NULL,
+    action ENUM('bust', 'correct') NOT NULL,
+    original_price DECIMAL(18, 8) NOT NULL,
+    original_quantity DECIMAL(18, 8) NOT NULL,
+    new_price DECIMAL(18, 8) NOT NULL, -- 0 for a bust
+    new_quantity DECIMAL(18, 8) NOT NULL, -- 0 for a bust
+    reason VARCHAR(255) NOT NULL,
+    actor VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_trade_id (trade_id, id),
+    FOREIGN KEY (trade_id) REFERENCES trades(id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+ALTER TABLE order_events
+    MODIFY COLUMN event_type ENUM('accepted', 'partially_filled', 'filled', 'canceled', 'expired', 'amended', 'rejected', 'trade_busted', 'trade_corrected') NOT NULL;
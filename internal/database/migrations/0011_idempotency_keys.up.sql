@@ -0,0 +1,4 @@
+-- Clients may retry order submission safely by sending an idempotency key
+ALTER TABLE orders
+    ADD COLUMN idempotency_key VARCHAR(64) NULL AFTER account_id,
+    ADD UNIQUE INDEX uq_idempotency_key (idempotency_key);
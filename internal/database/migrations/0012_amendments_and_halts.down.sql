@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS trading_halts;
+
+ALTER TABLE order_events
+    DROP COLUMN price;
+
+ALTER TABLE orders
+    DROP COLUMN priority_at;
@@ -0,0 +1,18 @@
+-- Amending an order's price or increasing its quantity sends it to the back
+-- of the queue without changing its ID
+ALTER TABLE orders
+    ADD COLUMN priority_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) AFTER reject_code;
+
+UPDATE orders SET priority_at = created_at;
+
+-- Limit price after the event, so historical books can replay amendments
+ALTER TABLE order_events
+    ADD COLUMN price DECIMAL(18, 8) NULL AFTER remaining_after;
+
+-- Symbols halted by an operator; new orders and amendments are refused
+CREATE TABLE IF NOT EXISTS trading_halts (
+    symbol VARCHAR(20) PRIMARY KEY,
+    reason VARCHAR(255) NOT NULL,
+    halted_by VARCHAR(100) NOT NULL,
+    halted_at TIMESTAMP(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
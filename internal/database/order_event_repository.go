@@ -0,0 +1,180 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"order-matching-system/internal/models"
+)
+
+type OrderEventRepository struct {
+	db DBTX
+}
+
+func NewOrderEventRepository(db DBTX) *OrderEventRepository {
+	return &OrderEventRepository{db: db}
+}
+
+func (r *OrderEventRepository) CreateEvent(ctx context.Context, event *models.OrderEvent) error {
+	query := `
+		INSERT INTO order_events (order_id, event_type, status_before, status_after, remaining_before, remaining_after, price, trade_id, cause, actor, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	var statusBefore sql.NullString
+	if event.StatusBefore != "" {
+		statusBefore = sql.NullString{String: string(event.StatusBefore), Valid: true}
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		event.OrderID,
+		event.Type,
+		statusBefore,
+		event.StatusAfter,
+		event.RemainingBefore,
+		event.RemainingAfter,
+		nullIfZero(event.Price),
+		event.TradeID,
+		event.Cause,
+		event.Actor,
+		event.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create order event: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	event.ID = id
+	return nil
+}
+
+// GetEventsByOrderID returns an order's events in the order they occurred
+func (r *OrderEventRepository) GetEventsByOrderID(ctx context.Context, orderID int) ([]*models.OrderEvent, error) {
+	query := `
+		SELECT id, order_id, event_type, status_before, status_after, remaining_before, remaining_after, price, trade_id, cause, actor, created_at
+		FROM order_events
+		WHERE order_id = ?
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []*models.OrderEvent{}
+	for rows.Next() {
+		event := &models.OrderEvent{}
+		var statusBefore sql.NullString
+		var price sql.NullFloat64
+		var tradeID sql.NullInt64
+
+		err := rows.Scan(
+			&event.ID,
+			&event.OrderID,
+			&event.Type,
+			&statusBefore,
+			&event.StatusAfter,
+			&event.RemainingBefore,
+			&event.RemainingAfter,
+			&price,
+			&tradeID,
+			&event.Cause,
+			&event.Actor,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order event: %w", err)
+		}
+
+		if statusBefore.Valid {
+			event.StatusBefore = models.OrderStatus(statusBefore.String)
+		}
+		if price.Valid {
+			event.Price = price.Float64
+		}
+		if tradeID.Valid {
+			id := int(tradeID.Int64)
+			event.TradeID = &id
+		}
+
+		events = append(events, event)
+	}
+
+	return events, nil
+}
+
+// GetLatestEventID returns the highest event ID recorded so far
+func (r *OrderEventRepository) GetLatestEventID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM order_events`).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest event id: %w", err)
+	}
+	return id, nil
+}
+
+// GetBookEvents returns the events for symbol after afterID and no later than
+// until, joined with the order details needed to replay them onto a book.
+// Events recorded before prices were stored carry the order's current price.
+func (r *OrderEventRepository) GetBookEvents(ctx context.Context, symbol string, afterID int64, until time.Time) ([]*models.BookEvent, error) {
+	query := `
+		SELECT e.id, e.order_id, e.event_type, e.status_after, e.remaining_after, e.created_at,
+			o.side, o.type, COALESCE(e.price, o.price), o.created_at
+		FROM order_events e
+		JOIN orders o ON o.id = e.order_id
+		WHERE o.symbol = ? AND e.id > ? AND e.created_at <= ?
+		ORDER BY e.id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, afterID, until)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get book events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.BookEvent
+	for rows.Next() {
+		event := &models.BookEvent{Event: &models.OrderEvent{}}
+		var price sql.NullFloat64
+
+		err := rows.Scan(
+			&event.Event.ID,
+			&event.Event.OrderID,
+			&event.Event.Type,
+			&event.Event.StatusAfter,
+			&event.Event.RemainingAfter,
+			&event.Event.CreatedAt,
+			&event.Order.Side,
+			&event.Type,
+			&price,
+			&event.Order.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan book event: %w", err)
+		}
+
+		event.Order.OrderID = event.Event.OrderID
+		event.Order.Quantity = event.Event.RemainingAfter
+		if price.Valid {
+			event.Order.Price = price.Float64
+		}
+
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
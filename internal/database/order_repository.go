@@ -1,12 +1,25 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"sort"
+	"time"
 
 	"order-matching-system/internal/models"
 )
 
+// orderColumns is the column list read by scanOrder
+const orderColumns = `id, account_id, idempotency_key, symbol, side, type, price, max_slippage, initial_quantity, remaining_quantity,
+	filled_quantity, average_fill_price, last_fill_price, last_fill_at,
+	status, status_reason, reject_code, created_at, updated_at`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 type OrderRepository struct {
 	db DBTX
 }
@@ -15,21 +28,27 @@ func NewOrderRepository(db DBTX) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
-func (r *OrderRepository) CreateOrder(order *models.Order) error {
+func (r *OrderRepository) CreateOrder(ctx context.Context, order *models.Order) error {
 	query := `
-		INSERT INTO orders (symbol, side, type, price, initial_quantity, remaining_quantity, status)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO orders (account_id, idempotency_key, symbol, side, type, price, max_slippage, initial_quantity, remaining_quantity, status, status_reason, reject_code)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(
+	result, err := r.db.ExecContext(
+		ctx,
 		query,
+		nullIfEmpty(order.AccountID),
+		nullIfEmpty(order.IdempotencyKey),
 		order.Symbol,
 		order.Side,
 		order.Type,
 		order.Price,
+		nullIfZero(order.MaxSlippage),
 		order.InitialQuantity,
 		order.RemainingQuantity,
 		order.Status,
+		nullIfEmpty(order.StatusReason),
+		nullIfEmpty(string(order.RejectCode)),
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create order: %w", err)
@@ -44,111 +63,205 @@ func (r *OrderRepository) CreateOrder(order *models.Order) error {
 	return nil
 }
 
-func (r *OrderRepository) GetOrderByID(id int) (*models.Order, error) {
+func (r *OrderRepository) GetOrderByID(ctx context.Context, id int) (*models.Order, error) {
 	query := `
-		SELECT id, symbol, side, type, price, initial_quantity, remaining_quantity, status, created_at, updated_at
+		SELECT ` + orderColumns + `
 		FROM orders
 		WHERE id = ?
 	`
 
-	order := &models.Order{}
-	var price sql.NullFloat64
-
-	err := r.db.QueryRow(query, id).Scan(
-		&order.ID,
-		&order.Symbol,
-		&order.Side,
-		&order.Type,
-		&price,
-		&order.InitialQuantity,
-		&order.RemainingQuantity,
-		&order.Status,
-		&order.CreatedAt,
-		&order.UpdatedAt,
-	)
-
+	order, err := scanOrder(r.db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("order not found")
+			return nil, ErrOrderNotFound
 		}
 		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
 
-	if price.Valid {
-		order.Price = price.Float64
+	return order, nil
+}
+
+// GetOrderByIdempotencyKey returns the order placed with key, or nil if there
+// is none
+func (r *OrderRepository) GetOrderByIdempotencyKey(ctx context.Context, key string) (*models.Order, error) {
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE idempotency_key = ?
+	`
+
+	order, err := scanOrder(r.db.QueryRowContext(ctx, query, key))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get order by idempotency key: %w", err)
 	}
 
 	return order, nil
 }
 
-func (r *OrderRepository) GetOpenOrdersBySymbol(symbol string) ([]*models.Order, error) {
+func (r *OrderRepository) GetOpenOrdersBySymbol(ctx context.Context, symbol string) ([]*models.Order, error) {
 	query := `
-		SELECT id, symbol, side, type, price, initial_quantity, remaining_quantity, status, created_at, updated_at
+		SELECT ` + orderColumns + `
 		FROM orders
 		WHERE symbol = ? AND status = 'open'
-		ORDER BY created_at ASC
+		ORDER BY priority_at ASC, id ASC
 	`
 
-	rows, err := r.db.Query(query, symbol)
+	rows, err := r.db.QueryContext(ctx, query, symbol)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get open orders: %w", err)
 	}
 	defer rows.Close()
 
-	var orders []*models.Order
-	for rows.Next() {
-		order := &models.Order{}
-		var price sql.NullFloat64
+	return scanOrders(rows)
+}
 
-		err := rows.Scan(
-			&order.ID,
-			&order.Symbol,
-			&order.Side,
-			&order.Type,
-			&price,
-			&order.InitialQuantity,
-			&order.RemainingQuantity,
-			&order.Status,
-			&order.CreatedAt,
-			&order.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan order: %w", err)
-		}
+// GetMatchingOrders returns the open orders on the opposite side to side in
+// price-time priority: best price first, then by time priority, which an
+// amendment can reset, with the order ID breaking ties
+func (r *OrderRepository) GetMatchingOrders(ctx context.Context, symbol string, side models.OrderSide) ([]*models.Order, error) {
+	var query string
 
-		if price.Valid {
-			order.Price = price.Float64
-		}
+	if side == models.OrderSideBuy {
+		// For buy orders, get sell orders sorted by price ASC, then by time
+		query = `
+			SELECT ` + orderColumns + `
+			FROM orders
+			WHERE symbol = ? AND side = 'sell' AND status = 'open'
+			ORDER BY
+				CASE WHEN type = 'market' THEN 0 ELSE price END ASC,
+				priority_at ASC, id ASC
+		`
+	} else {
+		// For sell orders, get buy orders sorted by price DESC, then by time
+		query = `
+			SELECT ` + orderColumns + `
+			FROM orders
+			WHERE symbol = ? AND side = 'buy' AND status = 'open'
+			ORDER BY
+				CASE WHEN type = 'market' THEN 999999999 ELSE price END DESC,
+				priority_at ASC, id ASC
+		`
+	}
 
-		orders = append(orders, order)
+	rows, err := r.db.QueryContext(ctx, query, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query matching orders: %w", err)
 	}
+	defer rows.Close()
 
-	return orders, nil
+	return scanOrders(rows)
 }
 
-func (r *OrderRepository) UpdateOrderStatus(id int, status models.OrderStatus, remainingQuantity float64) error {
+// UpdateOrderExecution writes an order's status, remaining quantity and fill
+// summary after it has traded
+func (r *OrderRepository) UpdateOrderExecution(ctx context.Context, order *models.Order) error {
 	query := `
 		UPDATE orders
-		SET status = ?, remaining_quantity = ?
+		SET status = ?, status_reason = ?, remaining_quantity = ?, filled_quantity = ?,
+			average_fill_price = ?, last_fill_price = ?, last_fill_at = ?
 		WHERE id = ?
 	`
 
-	_, err := r.db.Exec(query, status, remainingQuantity, id)
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		order.Status,
+		nullIfEmpty(order.StatusReason),
+		order.RemainingQuantity,
+		order.FilledQuantity,
+		nullIfZero(order.AverageFillPrice),
+		nullIfZero(order.LastFillPrice),
+		order.LastFillAt,
+		order.ID,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to update order status: %w", err)
+		return fmt.Errorf("failed to update order execution: %w", err)
 	}
 
 	return nil
 }
 
-func (r *OrderRepository) CancelOrder(id int) error {
+// AmendOrder writes an order's new price and quantities, moving it to the
+// back of the queue at its price when resetPriority is set
+func (r *OrderRepository) AmendOrder(ctx context.Context, order *models.Order, resetPriority bool) error {
+	query := `
+		UPDATE orders
+		SET price = ?, initial_quantity = ?, remaining_quantity = ?,
+			priority_at = IF(?, CURRENT_TIMESTAMP(6), priority_at)
+		WHERE id = ? AND status = 'open'
+	`
+
+	_, err := r.db.ExecContext(ctx, query, order.Price, order.InitialQuantity, order.RemainingQuantity, resetPriority, order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to amend order: %w", err)
+	}
+
+	return nil
+}
+
+// ListOrders returns the orders matching filter, newest first. A zero
+// filter.Limit returns every match.
+func (r *OrderRepository) ListOrders(ctx context.Context, filter models.OrderFilter) ([]*models.Order, error) {
+	query := `
+		SELECT ` + orderColumns + `
+		FROM orders
+		WHERE 1 = 1`
+	var args []interface{}
+
+	if filter.Symbol != "" {
+		query += ` AND symbol = ?`
+		args = append(args, filter.Symbol)
+	}
+	if filter.AccountID != "" {
+		query += ` AND account_id = ?`
+		args = append(args, filter.AccountID)
+	}
+	if filter.Side != "" {
+		query += ` AND side = ?`
+		args = append(args, filter.Side)
+	}
+	if filter.Status != "" {
+		query += ` AND status = ?`
+		args = append(args, filter.Status)
+	}
+	if filter.BeforeID > 0 {
+		query += ` AND id < ?`
+		args = append(args, filter.BeforeID)
+	}
+
+	query += ` ORDER BY id DESC`
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders, err := scanOrders(rows)
+	if err != nil {
+		return nil, err
+	}
+	if orders == nil {
+		orders = []*models.Order{}
+	}
+	return orders, nil
+}
+
+func (r *OrderRepository) CancelOrder(ctx context.Context, id int) error {
 	query := `
 		UPDATE orders
 		SET status = 'canceled'
 		WHERE id = ? AND status = 'open'
 	`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.db.ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to cancel order: %w", err)
 	}
@@ -159,8 +272,207 @@ func (r *OrderRepository) CancelOrder(id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("order not found or already filled/canceled")
+		return ErrOrderNotOpen
 	}
 
 	return nil
 }
+
+// GetOrderFlow returns the order stream recorded for symbol between from
+// and to, in time order: an order event for each order created in the
+// window, with the price and quantity it arrived with, then an amend event
+// for each amendment and a cancel event for each limit order canceled or
+// expired before to. Rejected orders never reached the book and are left
+// out, as are the cancels of market order remainders, which the replay makes
+// itself.
+func (r *OrderRepository) GetOrderFlow(ctx context.Context, symbol string, from, to time.Time) ([]*models.FlowEvent, error) {
+	// Arrival times come from the accepted event, which keeps microseconds;
+	// orders.created_at only holds whole seconds
+	query := `
+		SELECT o.id, o.account_id, o.side, o.type, COALESCE(a.price, o.price), o.max_slippage,
+			COALESCE(a.remaining_after, o.initial_quantity), COALESCE(a.created_at, o.created_at) AS arrived_at
+		FROM orders o
+		LEFT JOIN order_events a ON a.order_id = o.id AND a.event_type = 'accepted'
+		WHERE o.symbol = ? AND COALESCE(a.created_at, o.created_at) >= ? AND COALESCE(a.created_at, o.created_at) < ?
+			AND o.status <> 'rejected'
+		ORDER BY arrived_at ASC, a.id ASC, o.id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order flow: %w", err)
+	}
+	defer rows.Close()
+
+	var flow []*models.FlowEvent
+	quantities := make(map[int]float64) // Total quantity of each order as of the last event read
+	for rows.Next() {
+		event := &models.FlowEvent{Kind: models.FlowOrder, Symbol: symbol}
+		var accountID sql.NullString
+		var price, maxSlippage sql.NullFloat64
+
+		err := rows.Scan(
+			&event.OrderID,
+			&accountID,
+			&event.Side,
+			&event.Type,
+			&price,
+			&maxSlippage,
+			&event.Quantity,
+			&event.Time,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order flow: %w", err)
+		}
+
+		event.AccountID = accountID.String
+		event.Price = price.Float64
+		event.MaxSlippage = maxSlippage.Float64
+		flow = append(flow, event)
+		quantities[event.OrderID] = event.Quantity
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	changes, err := r.getOrderFlowChanges(ctx, symbol, from, to, quantities)
+	if err != nil {
+		return nil, err
+	}
+
+	// A change sorts after any order created at the same instant, including
+	// its own
+	flow = append(flow, changes...)
+	sort.SliceStable(flow, func(i, j int) bool { return flow[i].Time.Before(flow[j].Time) })
+	return flow, nil
+}
+
+// getOrderFlowChanges returns the amendments and cancels recorded before to
+// of the limit orders created in symbol between from and to. quantities
+// holds each order's quantity on arrival and is updated as amendments
+// change it, since an amendment records the change in remaining quantity.
+func (r *OrderRepository) getOrderFlowChanges(ctx context.Context, symbol string, from, to time.Time, quantities map[int]float64) ([]*models.FlowEvent, error) {
+	query := `
+		SELECT e.order_id, e.event_type, e.price, e.remaining_before, e.remaining_after, e.created_at
+		FROM orders o
+		LEFT JOIN order_events a ON a.order_id = o.id AND a.event_type = 'accepted'
+		JOIN order_events e ON e.order_id = o.id
+		WHERE o.symbol = ? AND COALESCE(a.created_at, o.created_at) >= ? AND COALESCE(a.created_at, o.created_at) < ?
+			AND o.type = 'limit'
+			AND e.event_type IN ('amended', 'canceled', 'expired') AND e.created_at < ?
+		ORDER BY e.id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, from, to, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order flow changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*models.FlowEvent
+	for rows.Next() {
+		event := &models.FlowEvent{Symbol: symbol}
+		var eventType models.OrderEventType
+		var price sql.NullFloat64
+		var remainingBefore, remainingAfter float64
+
+		if err := rows.Scan(&event.OrderID, &eventType, &price, &remainingBefore, &remainingAfter, &event.Time); err != nil {
+			return nil, fmt.Errorf("failed to scan order flow change: %w", err)
+		}
+
+		event.Kind = models.FlowCancel
+		if eventType == models.OrderEventAmended {
+			quantity := quantities[event.OrderID] + remainingAfter - remainingBefore
+			quantities[event.OrderID] = quantity
+			event.Kind = models.FlowAmend
+			event.Price = price.Float64
+			event.Quantity = quantity
+		}
+		changes = append(changes, event)
+	}
+	return changes, rows.Err()
+}
+
+// scanOrder reads a row selected with orderColumns
+func scanOrder(row rowScanner) (*models.Order, error) {
+	order := &models.Order{}
+	var accountID, idempotencyKey, statusReason, rejectCode sql.NullString
+	var price, maxSlippage, averageFillPrice, lastFillPrice sql.NullFloat64 // NULL when not applicable
+	var lastFillAt sql.NullTime
+
+	err := row.Scan(
+		&order.ID,
+		&accountID,
+		&idempotencyKey,
+		&order.Symbol,
+		&order.Side,
+		&order.Type,
+		&price,
+		&maxSlippage,
+		&order.InitialQuantity,
+		&order.RemainingQuantity,
+		&order.FilledQuantity,
+		&averageFillPrice,
+		&lastFillPrice,
+		&lastFillAt,
+		&order.Status,
+		&statusReason,
+		&rejectCode,
+		&order.CreatedAt,
+		&order.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if accountID.Valid {
+		order.AccountID = accountID.String
+	}
+	if idempotencyKey.Valid {
+		order.IdempotencyKey = idempotencyKey.String
+	}
+	if price.Valid {
+		order.Price = price.Float64
+	}
+	if maxSlippage.Valid {
+		order.MaxSlippage = maxSlippage.Float64
+	}
+	if statusReason.Valid {
+		order.StatusReason = statusReason.String
+	}
+	if rejectCode.Valid {
+		order.RejectCode = models.RejectCode(rejectCode.String)
+	}
+	if averageFillPrice.Valid {
+		order.AverageFillPrice = averageFillPrice.Float64
+	}
+	if lastFillPrice.Valid {
+		order.LastFillPrice = lastFillPrice.Float64
+	}
+	if lastFillAt.Valid {
+		order.LastFillAt = &lastFillAt.Time
+	}
+
+	return order, nil
+}
+
+func scanOrders(rows *sql.Rows) ([]*models.Order, error) {
+	var orders []*models.Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+func nullIfZero(v float64) sql.NullFloat64 {
+	return sql.NullFloat64{Float64: v, Valid: v != 0}
+}
+
+func nullIfEmpty(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
+}
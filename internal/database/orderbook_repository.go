@@ -1,7 +1,9 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"order-matching-system/internal/models"
 )
@@ -14,61 +16,113 @@ func NewOrderBookRepository(db DBTX) *OrderBookRepository {
 	return &OrderBookRepository{db: db}
 }
 
-func (r *OrderBookRepository) GetOrderBook(symbol string) (*models.OrderBook, error) {
+// GetOrderBook returns the resting limit orders for a symbol at the requested
+// level of detail, with at most depth entries per side
+func (r *OrderBookRepository) GetOrderBook(ctx context.Context, symbol string, level models.BookLevel, depth int) (*models.OrderBook, error) {
 	orderBook := &models.OrderBook{
 		Symbol: symbol,
+		Level:  level,
 		Bids:   []models.OrderBookEntry{},
 		Asks:   []models.OrderBookEntry{},
 	}
 
-	bidsQuery := `
+	var err error
+	switch level {
+	case models.BookLevel1:
+		if orderBook.Bids, err = r.getLevels(ctx, symbol, models.OrderSideBuy, 1); err != nil {
+			return nil, err
+		}
+		if orderBook.Asks, err = r.getLevels(ctx, symbol, models.OrderSideSell, 1); err != nil {
+			return nil, err
+		}
+	case models.BookLevel2:
+		if orderBook.Bids, err = r.getLevels(ctx, symbol, models.OrderSideBuy, depth); err != nil {
+			return nil, err
+		}
+		if orderBook.Asks, err = r.getLevels(ctx, symbol, models.OrderSideSell, depth); err != nil {
+			return nil, err
+		}
+	case models.BookLevel3:
+		if orderBook.Bids, err = r.getOrders(ctx, symbol, models.OrderSideBuy, depth); err != nil {
+			return nil, err
+		}
+		if orderBook.Asks, err = r.getOrders(ctx, symbol, models.OrderSideSell, depth); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported book level: %d", level)
+	}
+
+	orderBook.SetSpread()
+	return orderBook, nil
+}
+
+// getLevels aggregates open limit orders into price levels, best price first
+func (r *OrderBookRepository) getLevels(ctx context.Context, symbol string, side models.OrderSide, depth int) ([]models.OrderBookEntry, error) {
+	// Bids are sorted by price DESC, asks by price ASC
+	query := `
 		SELECT price, SUM(remaining_quantity) as total_quantity, COUNT(*) as order_count
 		FROM orders
-		WHERE symbol = ? AND side = 'buy' AND status = 'open' AND type = 'limit'
+		WHERE symbol = ? AND side = ? AND status = 'open' AND type = 'limit'
 		GROUP BY price
-		ORDER BY price DESC
-		LIMIT 50
+		ORDER BY price ` + sortDirection(side) + `
+		LIMIT ?
 	`
 
-	bidRows, err := r.db.Query(bidsQuery, symbol)
+	rows, err := r.db.QueryContext(ctx, query, symbol, side, depth)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get bids: %w", err)
+		return nil, fmt.Errorf("failed to get %s levels: %w", side, err)
 	}
-	defer bidRows.Close()
+	defer rows.Close()
 
-	for bidRows.Next() {
+	entries := []models.OrderBookEntry{}
+	for rows.Next() {
 		var entry models.OrderBookEntry
-		err := bidRows.Scan(&entry.Price, &entry.Quantity, &entry.Orders)
+		err := rows.Scan(&entry.Price, &entry.Quantity, &entry.Orders)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan bid: %w", err)
+			return nil, fmt.Errorf("failed to scan %s level: %w", side, err)
 		}
-		orderBook.Bids = append(orderBook.Bids, entry)
+		entries = append(entries, entry)
 	}
 
-	// Get sell orders (asks) - sorted by price ASC
-	asksQuery := `
-		SELECT price, SUM(remaining_quantity) as total_quantity, COUNT(*) as order_count
+	return entries, nil
+}
+
+// getOrders returns individual open limit orders in matching priority order
+func (r *OrderBookRepository) getOrders(ctx context.Context, symbol string, side models.OrderSide, depth int) ([]models.OrderBookEntry, error) {
+	query := `
+		SELECT id, price, remaining_quantity, created_at
 		FROM orders
-		WHERE symbol = ? AND side = 'sell' AND status = 'open' AND type = 'limit'
-		GROUP BY price
-		ORDER BY price ASC
-		LIMIT 50
+		WHERE symbol = ? AND side = ? AND status = 'open' AND type = 'limit'
+		ORDER BY price ` + sortDirection(side) + `, priority_at ASC, id ASC
+		LIMIT ?
 	`
 
-	askRows, err := r.db.Query(asksQuery, symbol)
+	rows, err := r.db.QueryContext(ctx, query, symbol, side, depth)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get asks: %w", err)
+		return nil, fmt.Errorf("failed to get %s orders: %w", side, err)
 	}
-	defer askRows.Close()
+	defer rows.Close()
 
-	for askRows.Next() {
+	entries := []models.OrderBookEntry{}
+	for rows.Next() {
 		var entry models.OrderBookEntry
-		err := askRows.Scan(&entry.Price, &entry.Quantity, &entry.Orders)
+		var createdAt time.Time
+		err := rows.Scan(&entry.OrderID, &entry.Price, &entry.Quantity, &createdAt)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan ask: %w", err)
+			return nil, fmt.Errorf("failed to scan %s order: %w", side, err)
 		}
-		orderBook.Asks = append(orderBook.Asks, entry)
+		entry.CreatedAt = &createdAt
+		entries = append(entries, entry)
 	}
 
-	return orderBook, nil
+	return entries, nil
+}
+
+// sortDirection returns the SQL sort direction that puts the best price first
+func sortDirection(side models.OrderSide) string {
+	if side == models.OrderSideBuy {
+		return "DESC"
+	}
+	return "ASC"
 }
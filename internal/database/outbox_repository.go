@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"order-matching-system/internal/models"
+)
+
+type OutboxRepository struct {
+	db DBTX
+}
+
+func NewOutboxRepository(db DBTX) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Append writes an event to the outbox. It must run in the same transaction
+// as the change the event describes.
+func (r *OutboxRepository) Append(ctx context.Context, eventType, aggregateType string, aggregateID int, symbol string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	query := `
+		INSERT INTO outbox_events (event_type, aggregate_type, aggregate_id, symbol, payload)
+		VALUES (?, ?, ?, ?, ?)
+	`
+
+	_, err = r.db.ExecContext(ctx, query, eventType, aggregateType, aggregateID, symbol, body)
+	if err != nil {
+		return fmt.Errorf("failed to append %s event: %w", eventType, err)
+	}
+
+	return nil
+}
+
+// GetUnpublished returns up to limit events not yet marked published, in
+// sequence order
+func (r *OutboxRepository) GetUnpublished(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	query := `
+		SELECT sequence, event_type, aggregate_type, aggregate_id, symbol, payload, created_at
+		FROM outbox_events
+		WHERE published_at IS NULL
+		ORDER BY sequence ASC
+		LIMIT ?
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unpublished events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*models.OutboxEvent
+	for rows.Next() {
+		event := &models.OutboxEvent{}
+		var payload []byte
+		err := rows.Scan(
+			&event.Sequence,
+			&event.Type,
+			&event.AggregateType,
+			&event.AggregateID,
+			&event.Symbol,
+			&payload,
+			&event.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// MarkPublished records that the given events have been delivered
+func (r *OutboxRepository) MarkPublished(ctx context.Context, sequences []int64) error {
+	if len(sequences) == 0 {
+		return nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(sequences)), ",")
+	query := `
+		UPDATE outbox_events
+		SET published_at = CURRENT_TIMESTAMP(6)
+		WHERE sequence IN (` + placeholders + `)
+	`
+
+	args := make([]interface{}, len(sequences))
+	for i, seq := range sequences {
+		args[i] = seq
+	}
+
+	if _, err := r.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to mark events published: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"order-matching-system/internal/models"
+)
+
+type PositionRepository struct {
+	db DBTX
+}
+
+func NewPositionRepository(db DBTX) *PositionRepository {
+	return &PositionRepository{db: db}
+}
+
+// GetPosition returns accountID's position in symbol, or a flat position if
+// the account has never traded it
+func (r *PositionRepository) GetPosition(ctx context.Context, accountID, symbol string) (*models.Position, error) {
+	query := `
+		SELECT account_id, symbol, quantity, average_cost, realized_pnl, updated_at
+		FROM positions
+		WHERE account_id = ? AND symbol = ?
+	`
+
+	position := &models.Position{}
+	err := r.db.QueryRowContext(ctx, query, accountID, symbol).Scan(
+		&position.AccountID,
+		&position.Symbol,
+		&position.Quantity,
+		&position.AverageCost,
+		&position.RealizedPnL,
+		&position.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &models.Position{AccountID: accountID, Symbol: symbol}, nil
+		}
+		return nil, fmt.Errorf("failed to get position: %w", err)
+	}
+
+	return position, nil
+}
+
+// GetPositionsByAccount returns every position accountID holds or has held,
+// marked to each symbol's last trade price
+func (r *PositionRepository) GetPositionsByAccount(ctx context.Context, accountID string) ([]*models.Position, error) {
+	query := `
+		SELECT p.account_id, p.symbol, p.quantity, p.average_cost, p.realized_pnl, p.updated_at,
+			(SELECT price FROM trades WHERE symbol = p.symbol AND NOT busted ORDER BY id DESC LIMIT 1)
+		FROM positions p
+		WHERE p.account_id = ?
+		ORDER BY p.symbol
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get positions: %w", err)
+	}
+	defer rows.Close()
+
+	positions := []*models.Position{}
+	for rows.Next() {
+		position := &models.Position{}
+		var lastPrice sql.NullFloat64
+		err := rows.Scan(
+			&position.AccountID,
+			&position.Symbol,
+			&position.Quantity,
+			&position.AverageCost,
+			&position.RealizedPnL,
+			&position.UpdatedAt,
+			&lastPrice,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan position: %w", err)
+		}
+		position.MarkToMarket(lastPrice.Float64)
+		positions = append(positions, position)
+	}
+
+	return positions, rows.Err()
+}
+
+// SavePosition creates or replaces a position
+func (r *PositionRepository) SavePosition(ctx context.Context, position *models.Position) error {
+	query := `
+		INSERT INTO positions (account_id, symbol, quantity, average_cost, realized_pnl)
+		VALUES (?, ?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE
+			quantity = VALUES(quantity),
+			average_cost = VALUES(average_cost),
+			realized_pnl = VALUES(realized_pnl)
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		position.AccountID,
+		position.Symbol,
+		position.Quantity,
+		position.AverageCost,
+		position.RealizedPnL,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save position: %w", err)
+	}
+
+	return nil
+}
+
+// GetLots returns the open lots of accountID's position in symbol, oldest first
+func (r *PositionRepository) GetLots(ctx context.Context, accountID, symbol string) ([]*models.PositionLot, error) {
+	query := `
+		SELECT id, account_id, symbol, quantity, price, created_at
+		FROM position_lots
+		WHERE account_id = ? AND symbol = ?
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get position lots: %w", err)
+	}
+	defer rows.Close()
+
+	var lots []*models.PositionLot
+	for rows.Next() {
+		lot := &models.PositionLot{}
+		err := rows.Scan(&lot.ID, &lot.AccountID, &lot.Symbol, &lot.Quantity, &lot.Price, &lot.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan position lot: %w", err)
+		}
+		lots = append(lots, lot)
+	}
+
+	return lots, rows.Err()
+}
+
+// DeleteLots removes every open lot of accountID's position in symbol
+func (r *PositionRepository) DeleteLots(ctx context.Context, accountID, symbol string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM position_lots WHERE account_id = ? AND symbol = ?`, accountID, symbol)
+	if err != nil {
+		return fmt.Errorf("failed to delete position lots: %w", err)
+	}
+	return nil
+}
+
+// GetAccountFills returns accountID's side of every live trade in symbol,
+// oldest first, with sells as negative quantities
+func (r *PositionRepository) GetAccountFills(ctx context.Context, accountID, symbol string) ([]*models.AccountFill, error) {
+	query := `
+		SELECT t.id, CASE WHEN o.side = 'buy' THEN t.quantity ELSE -t.quantity END, t.price
+		FROM trades t
+		JOIN orders o ON o.id = t.buy_order_id OR o.id = t.sell_order_id
+		WHERE o.account_id = ? AND t.symbol = ? AND NOT t.busted
+		ORDER BY t.id ASC, o.side ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, accountID, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account fills: %w", err)
+	}
+	defer rows.Close()
+
+	var fills []*models.AccountFill
+	for rows.Next() {
+		fill := &models.AccountFill{}
+		if err := rows.Scan(&fill.TradeID, &fill.Quantity, &fill.Price); err != nil {
+			return nil, fmt.Errorf("failed to scan account fill: %w", err)
+		}
+		fills = append(fills, fill)
+	}
+
+	return fills, rows.Err()
+}
+
+func (r *PositionRepository) CreateLot(ctx context.Context, lot *models.PositionLot) error {
+	query := `
+		INSERT INTO position_lots (account_id, symbol, quantity, price)
+		VALUES (?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, lot.AccountID, lot.Symbol, lot.Quantity, lot.Price)
+	if err != nil {
+		return fmt.Errorf("failed to create position lot: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	lot.ID = id
+	return nil
+}
+
+func (r *PositionRepository) UpdateLotQuantity(ctx context.Context, id int64, quantity float64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE position_lots SET quantity = ? WHERE id = ?`, quantity, id)
+	if err != nil {
+		return fmt.Errorf("failed to update position lot: %w", err)
+	}
+	return nil
+}
+
+func (r *PositionRepository) DeleteLot(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM position_lots WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete position lot: %w", err)
+	}
+	return nil
+}
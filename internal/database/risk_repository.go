@@ -0,0 +1,102 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// RiskRepository answers the per-account questions asked by the pre-trade
+// risk checks
+type RiskRepository struct {
+	db DBTX
+}
+
+func NewRiskRepository(db DBTX) *RiskRepository {
+	return &RiskRepository{db: db}
+}
+
+// CountOpenOrders returns the number of orders accountID has resting in symbol
+func (r *RiskRepository) CountOpenOrders(ctx context.Context, accountID, symbol string) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM orders
+		WHERE account_id = ? AND symbol = ? AND status = 'open'
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, accountID, symbol).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count open orders: %w", err)
+	}
+
+	return count, nil
+}
+
+// CountOrdersSince returns the number of orders accountID has submitted
+// since the given time, not counting rejected ones
+func (r *RiskRepository) CountOrdersSince(ctx context.Context, accountID string, since time.Time) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM orders
+		WHERE account_id = ? AND created_at >= ? AND status <> 'rejected'
+	`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, accountID, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count recent orders: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetNetPosition returns the quantity accountID has bought minus the quantity
+// it has sold in symbol, as kept in the positions table
+func (r *RiskRepository) GetNetPosition(ctx context.Context, accountID, symbol string) (float64, error) {
+	query := `
+		SELECT quantity
+		FROM positions
+		WHERE account_id = ? AND symbol = ?
+	`
+
+	var position float64
+	err := r.db.QueryRowContext(ctx, query, accountID, symbol).Scan(&position)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get net position: %w", err)
+	}
+
+	return position, nil
+}
+
+// GetTradingPnLSince returns the profit or loss across all symbols of the
+// trades accountID made since the given time, each marked to its symbol's
+// last trade price. Each side is joined separately so both joins can use
+// the foreign key index on the trade's order ID.
+func (r *RiskRepository) GetTradingPnLSince(ctx context.Context, accountID string, since time.Time) (float64, error) {
+	query := `
+		SELECT COALESCE(SUM(pnl), 0)
+		FROM (
+			SELECT t.quantity * (last.price - t.price) AS pnl
+			FROM orders o
+			JOIN trades t ON t.buy_order_id = o.id
+			JOIN trades last ON last.id = (SELECT MAX(id) FROM trades WHERE symbol = t.symbol AND NOT busted)
+			WHERE o.account_id = ? AND t.created_at >= ? AND NOT t.busted
+			UNION ALL
+			SELECT t.quantity * (t.price - last.price) AS pnl
+			FROM orders o
+			JOIN trades t ON t.sell_order_id = o.id
+			JOIN trades last ON last.id = (SELECT MAX(id) FROM trades WHERE symbol = t.symbol AND NOT busted)
+			WHERE o.account_id = ? AND t.created_at >= ? AND NOT t.busted
+		) fills
+	`
+
+	var pnl float64
+	if err := r.db.QueryRowContext(ctx, query, accountID, since, accountID, since).Scan(&pnl); err != nil {
+		return 0, fmt.Errorf("failed to get trading pnl: %w", err)
+	}
+
+	return pnl, nil
+}
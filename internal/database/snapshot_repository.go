@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"order-matching-system/internal/models"
+)
+
+type SnapshotRepository struct {
+	db DBTX
+}
+
+func NewSnapshotRepository(db DBTX) *SnapshotRepository {
+	return &SnapshotRepository{db: db}
+}
+
+func (r *SnapshotRepository) CreateSnapshot(ctx context.Context, snapshot *models.BookSnapshot) error {
+	orders, err := json.Marshal(snapshot.Orders)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot orders: %w", err)
+	}
+
+	query := `
+		INSERT INTO book_snapshots (symbol, taken_at, last_event_id, orders)
+		VALUES (?, ?, ?, ?)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, snapshot.Symbol, snapshot.TakenAt, snapshot.LastEventID, orders)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	snapshot.ID = id
+	return nil
+}
+
+// GetLatestSnapshot returns the most recent snapshot of symbol taken at or
+// before at, or nil if there is none
+func (r *SnapshotRepository) GetLatestSnapshot(ctx context.Context, symbol string, at time.Time) (*models.BookSnapshot, error) {
+	query := `
+		SELECT id, symbol, taken_at, last_event_id, orders
+		FROM book_snapshots
+		WHERE symbol = ? AND taken_at <= ?
+		ORDER BY taken_at DESC, id DESC
+		LIMIT 1
+	`
+
+	snapshot := &models.BookSnapshot{}
+	var orders []byte
+	err := r.db.QueryRowContext(ctx, query, symbol, at).Scan(
+		&snapshot.ID,
+		&snapshot.Symbol,
+		&snapshot.TakenAt,
+		&snapshot.LastEventID,
+		&orders,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	if err := json.Unmarshal(orders, &snapshot.Orders); err != nil {
+		return nil, fmt.Errorf("failed to decode snapshot orders: %w", err)
+	}
+
+	return snapshot, nil
+}
+
+// GetRestingOrders returns every open limit order for symbol, with its time
+// priority as CreatedAt
+func (r *SnapshotRepository) GetRestingOrders(ctx context.Context, symbol string) ([]models.BookOrder, error) {
+	query := `
+		SELECT id, side, price, remaining_quantity, priority_at
+		FROM orders
+		WHERE symbol = ? AND status = 'open' AND type = 'limit'
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resting orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := []models.BookOrder{}
+	for rows.Next() {
+		var order models.BookOrder
+		if err := rows.Scan(&order.OrderID, &order.Side, &order.Price, &order.Quantity, &order.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan resting order: %w", err)
+		}
+		orders = append(orders, order)
+	}
+
+	return orders, rows.Err()
+}
+
+// GetChangedSymbols returns every symbol with order events newer than its
+// latest snapshot, including symbols that have never been snapshotted
+func (r *SnapshotRepository) GetChangedSymbols(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT s.symbol
+		FROM (SELECT DISTINCT symbol FROM orders) s
+		WHERE EXISTS (
+			SELECT 1
+			FROM order_events e
+			JOIN orders o ON o.id = e.order_id
+			WHERE o.symbol = s.symbol AND e.id > (
+				SELECT COALESCE(MAX(b.last_event_id), 0)
+				FROM book_snapshots b
+				WHERE b.symbol = s.symbol
+			)
+		)
+		ORDER BY s.symbol
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order symbols: %w", err)
+	}
+	defer rows.Close()
+
+	var symbols []string
+	for rows.Next() {
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol: %w", err)
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	return symbols, rows.Err()
+}
+
+// DeleteSnapshotsBefore deletes snapshots taken before cutoff, keeping the
+// latest snapshot of each symbol however old, and returns how many it deleted
+func (r *SnapshotRepository) DeleteSnapshotsBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `
+		DELETE b
+		FROM book_snapshots b
+		JOIN (
+			SELECT symbol, MAX(id) AS id
+			FROM book_snapshots
+			GROUP BY symbol
+		) latest ON latest.symbol = b.symbol
+		WHERE b.taken_at < ? AND b.id < latest.id
+	`
+
+	result, err := r.db.ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete snapshots: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return deleted, nil
+}
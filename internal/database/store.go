@@ -0,0 +1,209 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"order-matching-system/internal/models"
+)
+
+var (
+	// ErrOrderNotFound is returned when no order has the requested ID
+	ErrOrderNotFound = errors.New("order not found")
+	// ErrTradeNotFound is returned when no trade has the requested ID
+	ErrTradeNotFound = errors.New("trade not found")
+	// ErrOrderNotOpen is returned when canceling an order that does not
+	// exist or is no longer open
+	ErrOrderNotOpen = errors.New("order not found or already filled/canceled")
+)
+
+// Store opens the transactions the matching engine reads and writes through.
+// SQLStore keeps the data in MySQL; the memory package keeps it in process
+// for simulations.
+type Store interface {
+	Begin(ctx context.Context) (Tx, error)
+}
+
+// Tx gives access to repositories bound to one transaction. Changes made
+// through them are kept only if Commit succeeds, and Rollback after Commit
+// does nothing, so it can always be deferred.
+type Tx interface {
+	Orders() OrderStore
+	Trades() TradeStore
+	Candles() CandleStore
+	Positions() PositionStore
+	Events() OrderEventStore
+	Outbox() OutboxStore
+	Halts() HaltStore
+	Instruments() InstrumentStore
+	Risk() RiskStore
+
+	Commit() error
+	Rollback() error
+
+	// Elapsed returns the time spent waiting on storage so far, including
+	// begin and commit
+	Elapsed() time.Duration
+}
+
+// The operations of each repository used by the matching engine. Every
+// implementation reports missing records with ErrOrderNotFound and
+// ErrTradeNotFound, and refused cancels with ErrOrderNotOpen.
+type (
+	OrderStore interface {
+		CreateOrder(ctx context.Context, order *models.Order) error
+		GetOrderByID(ctx context.Context, id int) (*models.Order, error)
+		GetOrderByIdempotencyKey(ctx context.Context, key string) (*models.Order, error)
+		GetMatchingOrders(ctx context.Context, symbol string, side models.OrderSide) ([]*models.Order, error)
+		UpdateOrderExecution(ctx context.Context, order *models.Order) error
+		AmendOrder(ctx context.Context, order *models.Order, resetPriority bool) error
+		ListOrders(ctx context.Context, filter models.OrderFilter) ([]*models.Order, error)
+		CancelOrder(ctx context.Context, id int) error
+	}
+
+	TradeStore interface {
+		CreateTrade(ctx context.Context, trade *models.Trade) error
+		GetTradeByID(ctx context.Context, id int) (*models.Trade, error)
+		GetLastPrice(ctx context.Context, symbol string) (float64, error)
+		GetTradesInRange(ctx context.Context, symbol string, from, to time.Time) ([]*models.Trade, error)
+		UpdateTrade(ctx context.Context, trade *models.Trade) error
+		CreateCorrection(ctx context.Context, correction *models.TradeCorrection) error
+	}
+
+	CandleStore interface {
+		ApplyTrade(ctx context.Context, trade *models.Trade) error
+		ReplaceBar(ctx context.Context, symbol string, interval models.CandleInterval, openTime time.Time, trades []*models.Trade) error
+	}
+
+	PositionStore interface {
+		GetPosition(ctx context.Context, accountID, symbol string) (*models.Position, error)
+		SavePosition(ctx context.Context, position *models.Position) error
+		GetLots(ctx context.Context, accountID, symbol string) ([]*models.PositionLot, error)
+		CreateLot(ctx context.Context, lot *models.PositionLot) error
+		UpdateLotQuantity(ctx context.Context, id int64, quantity float64) error
+		DeleteLot(ctx context.Context, id int64) error
+		DeleteLots(ctx context.Context, accountID, symbol string) error
+		GetAccountFills(ctx context.Context, accountID, symbol string) ([]*models.AccountFill, error)
+	}
+
+	OrderEventStore interface {
+		CreateEvent(ctx context.Context, event *models.OrderEvent) error
+	}
+
+	OutboxStore interface {
+		Append(ctx context.Context, eventType, aggregateType string, aggregateID int, symbol string, payload interface{}) error
+	}
+
+	HaltStore interface {
+		GetHalt(ctx context.Context, symbol string) (*models.TradingHalt, error)
+		SaveHalt(ctx context.Context, halt *models.TradingHalt) error
+		DeleteHalt(ctx context.Context, symbol string) (bool, error)
+	}
+
+	InstrumentStore interface {
+		GetInstrument(ctx context.Context, symbol string) (*models.Instrument, error)
+	}
+
+	RiskStore interface {
+		CountOpenOrders(ctx context.Context, accountID, symbol string) (int, error)
+		CountOrdersSince(ctx context.Context, accountID string, since time.Time) (int, error)
+		GetNetPosition(ctx context.Context, accountID, symbol string) (float64, error)
+		GetTradingPnLSince(ctx context.Context, accountID string, since time.Time) (float64, error)
+	}
+)
+
+// SQLStore runs transactions on a MySQL database
+type SQLStore struct {
+	db *sql.DB
+}
+
+func NewSQLStore(db *sql.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+func (s *SQLStore) Begin(ctx context.Context) (Tx, error) {
+	start := time.Now()
+	spanCtx, span := startSpan(ctx, "sql.BeginTx", semconv.DBSystemMySQL)
+	tx, err := s.db.BeginTx(spanCtx, nil)
+	span.End()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	t := &sqlTx{tx: tx, ctx: ctx}
+	t.db = &timedDBTX{db: Instrument(tx)}
+	t.db.track(start)
+	return t, nil
+}
+
+// sqlTx binds the SQL repositories to one transaction, timing and tracing
+// every call
+type sqlTx struct {
+	tx  *sql.Tx
+	db  *timedDBTX
+	ctx context.Context // Context the transaction began in, parent of the commit span
+}
+
+func (t *sqlTx) Orders() OrderStore           { return NewOrderRepository(t.db) }
+func (t *sqlTx) Trades() TradeStore           { return NewTradeRepository(t.db) }
+func (t *sqlTx) Candles() CandleStore         { return NewCandleRepository(t.db) }
+func (t *sqlTx) Positions() PositionStore     { return NewPositionRepository(t.db) }
+func (t *sqlTx) Events() OrderEventStore      { return NewOrderEventRepository(t.db) }
+func (t *sqlTx) Outbox() OutboxStore          { return NewOutboxRepository(t.db) }
+func (t *sqlTx) Halts() HaltStore             { return NewHaltRepository(t.db) }
+func (t *sqlTx) Instruments() InstrumentStore { return NewInstrumentRepository(t.db) }
+func (t *sqlTx) Risk() RiskStore              { return NewRiskRepository(t.db) }
+
+func (t *sqlTx) Commit() error {
+	defer t.db.track(time.Now())
+	_, span := startSpan(t.ctx, "sql.Commit", semconv.DBSystemMySQL)
+	defer span.End()
+	if err := t.tx.Commit(); err != nil {
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (t *sqlTx) Rollback() error {
+	err := t.tx.Rollback()
+	if err == sql.ErrTxDone {
+		return nil
+	}
+	return err
+}
+
+func (t *sqlTx) Elapsed() time.Duration {
+	return t.db.elapsed
+}
+
+// timedDBTX accumulates the time spent in database calls made through it
+type timedDBTX struct {
+	db      DBTX
+	elapsed time.Duration
+}
+
+func (t *timedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer t.track(time.Now())
+	return t.db.ExecContext(ctx, query, args...)
+}
+
+func (t *timedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer t.track(time.Now())
+	return t.db.QueryContext(ctx, query, args...)
+}
+
+func (t *timedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer t.track(time.Now())
+	return t.db.QueryRowContext(ctx, query, args...)
+}
+
+func (t *timedDBTX) track(start time.Time) {
+	t.elapsed += time.Since(start)
+}
@@ -1,11 +1,17 @@
 package database
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"time"
 
 	"order-matching-system/internal/models"
 )
 
+// tradeColumns is the column list read by scanTrade
+const tradeColumns = `id, symbol, buy_order_id, sell_order_id, price, quantity, busted, corrected, created_at`
+
 type TradeRepository struct {
 	db DBTX
 }
@@ -14,19 +20,28 @@ func NewTradeRepository(db DBTX) *TradeRepository {
 	return &TradeRepository{db: db}
 }
 
-func (r *TradeRepository) CreateTrade(trade *models.Trade) error {
+func (r *TradeRepository) CreateTrade(ctx context.Context, trade *models.Trade) error {
 	query := `
-		INSERT INTO trades (symbol, buy_order_id, sell_order_id, price, quantity)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO trades (symbol, buy_order_id, sell_order_id, price, quantity, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := r.db.Exec(
+	if trade.CreatedAt.IsZero() {
+		trade.CreatedAt = time.Now()
+	}
+	// created_at holds whole seconds; truncate here rather than let MySQL
+	// round, so that candles built from this trade agree with a rebuild
+	trade.CreatedAt = trade.CreatedAt.Truncate(time.Second)
+
+	result, err := r.db.ExecContext(
+		ctx,
 		query,
 		trade.Symbol,
 		trade.BuyOrderID,
 		trade.SellOrderID,
 		trade.Price,
 		trade.Quantity,
+		trade.CreatedAt,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to create trade: %w", err)
@@ -41,71 +56,270 @@ func (r *TradeRepository) CreateTrade(trade *models.Trade) error {
 	return nil
 }
 
-func (r *TradeRepository) GetTradesBySymbol(symbol string) ([]*models.Trade, error) {
+func (r *TradeRepository) GetTradeByID(ctx context.Context, id int) (*models.Trade, error) {
 	query := `
-		SELECT id, symbol, buy_order_id, sell_order_id, price, quantity, created_at
+		SELECT ` + tradeColumns + `
+		FROM trades
+		WHERE id = ?
+	`
+
+	trade, err := scanTrade(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTradeNotFound
+		}
+		return nil, fmt.Errorf("failed to get trade: %w", err)
+	}
+
+	return trade, nil
+}
+
+func (r *TradeRepository) GetTradesBySymbol(ctx context.Context, symbol string) ([]*models.Trade, error) {
+	query := `
+		SELECT ` + tradeColumns + `
 		FROM trades
 		WHERE symbol = ?
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.db.Query(query, symbol)
+	rows, err := r.db.QueryContext(ctx, query, symbol)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get trades: %w", err)
 	}
 	defer rows.Close()
 
-	var trades []*models.Trade
+	return scanTrades(rows)
+}
+
+func (r *TradeRepository) GetAllTrades(ctx context.Context) ([]*models.Trade, error) {
+	query := `
+		SELECT ` + tradeColumns + `
+		FROM trades
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get all trades: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTrades(rows)
+}
+
+// GetSymbols returns every symbol that has traded at least once
+func (r *TradeRepository) GetSymbols(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT DISTINCT symbol
+		FROM trades
+		ORDER BY symbol
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get traded symbols: %w", err)
+	}
+	defer rows.Close()
+
+	var symbols []string
 	for rows.Next() {
-		trade := &models.Trade{}
-		err := rows.Scan(
-			&trade.ID,
-			&trade.Symbol,
-			&trade.BuyOrderID,
-			&trade.SellOrderID,
-			&trade.Price,
-			&trade.Quantity,
-			&trade.CreatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan trade: %w", err)
+		var symbol string
+		if err := rows.Scan(&symbol); err != nil {
+			return nil, fmt.Errorf("failed to scan symbol: %w", err)
 		}
-		trades = append(trades, trade)
+		symbols = append(symbols, symbol)
 	}
 
-	return trades, nil
+	return symbols, nil
 }
 
-func (r *TradeRepository) GetAllTrades() ([]*models.Trade, error) {
+// GetLastPrice returns the price of the most recent trade in symbol that has
+// not been busted, or zero if there is none
+func (r *TradeRepository) GetLastPrice(ctx context.Context, symbol string) (float64, error) {
 	query := `
-		SELECT id, symbol, buy_order_id, sell_order_id, price, quantity, created_at
+		SELECT price
 		FROM trades
-		ORDER BY created_at DESC
+		WHERE symbol = ? AND NOT busted
+		ORDER BY id DESC
+		LIMIT 1
 	`
 
-	rows, err := r.db.Query(query)
+	var price float64
+	err := r.db.QueryRowContext(ctx, query, symbol).Scan(&price)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all trades: %w", err)
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get last price: %w", err)
+	}
+
+	return price, nil
+}
+
+// GetTradesByOrderID returns the trades an order took part in on either side,
+// oldest first
+func (r *TradeRepository) GetTradesByOrderID(ctx context.Context, orderID int) ([]*models.Trade, error) {
+	query := `
+		SELECT ` + tradeColumns + `
+		FROM trades
+		WHERE buy_order_id = ? OR sell_order_id = ?
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order trades: %w", err)
 	}
 	defer rows.Close()
 
-	var trades []*models.Trade
+	trades, err := scanTrades(rows)
+	if trades == nil && err == nil {
+		trades = []*models.Trade{}
+	}
+	return trades, err
+}
+
+// GetTradesInRange returns the trades in symbol that have not been busted
+// and were created in [from, to), oldest first
+func (r *TradeRepository) GetTradesInRange(ctx context.Context, symbol string, from, to time.Time) ([]*models.Trade, error) {
+	query := `
+		SELECT ` + tradeColumns + `
+		FROM trades
+		WHERE symbol = ? AND NOT busted AND created_at >= ? AND created_at < ?
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, symbol, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trades in range: %w", err)
+	}
+	defer rows.Close()
+
+	return scanTrades(rows)
+}
+
+// UpdateTrade writes a trade's price, quantity and bust/correction flags
+func (r *TradeRepository) UpdateTrade(ctx context.Context, trade *models.Trade) error {
+	query := `
+		UPDATE trades
+		SET price = ?, quantity = ?, busted = ?, corrected = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query, trade.Price, trade.Quantity, trade.Busted, trade.Corrected, trade.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update trade: %w", err)
+	}
+
+	return nil
+}
+
+// CreateCorrection records an operator's bust or correction of a trade
+func (r *TradeRepository) CreateCorrection(ctx context.Context, correction *models.TradeCorrection) error {
+	query := `
+		INSERT INTO trade_corrections (trade_id, action, original_price, original_quantity, new_price, new_quantity, reason, actor, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if correction.CreatedAt.IsZero() {
+		correction.CreatedAt = time.Now()
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		query,
+		correction.TradeID,
+		correction.Action,
+		correction.OriginalPrice,
+		correction.OriginalQuantity,
+		correction.NewPrice,
+		correction.NewQuantity,
+		correction.Reason,
+		correction.Actor,
+		correction.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create trade correction: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	correction.ID = int(id)
+	return nil
+}
+
+// GetCorrectionsByTradeID returns the busts and corrections applied to a
+// trade, oldest first
+func (r *TradeRepository) GetCorrectionsByTradeID(ctx context.Context, tradeID int) ([]*models.TradeCorrection, error) {
+	query := `
+		SELECT id, trade_id, action, original_price, original_quantity, new_price, new_quantity, reason, actor, created_at
+		FROM trade_corrections
+		WHERE trade_id = ?
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, tradeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trade corrections: %w", err)
+	}
+	defer rows.Close()
+
+	corrections := []*models.TradeCorrection{}
 	for rows.Next() {
-		trade := &models.Trade{}
+		correction := &models.TradeCorrection{}
 		err := rows.Scan(
-			&trade.ID,
-			&trade.Symbol,
-			&trade.BuyOrderID,
-			&trade.SellOrderID,
-			&trade.Price,
-			&trade.Quantity,
-			&trade.CreatedAt,
+			&correction.ID,
+			&correction.TradeID,
+			&correction.Action,
+			&correction.OriginalPrice,
+			&correction.OriginalQuantity,
+			&correction.NewPrice,
+			&correction.NewQuantity,
+			&correction.Reason,
+			&correction.Actor,
+			&correction.CreatedAt,
 		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trade correction: %w", err)
+		}
+		corrections = append(corrections, correction)
+	}
+
+	return corrections, rows.Err()
+}
+
+// scanTrade reads a row selected with tradeColumns
+func scanTrade(row rowScanner) (*models.Trade, error) {
+	trade := &models.Trade{}
+	err := row.Scan(
+		&trade.ID,
+		&trade.Symbol,
+		&trade.BuyOrderID,
+		&trade.SellOrderID,
+		&trade.Price,
+		&trade.Quantity,
+		&trade.Busted,
+		&trade.Corrected,
+		&trade.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return trade, nil
+}
+
+func scanTrades(rows *sql.Rows) ([]*models.Trade, error) {
+	var trades []*models.Trade
+	for rows.Next() {
+		trade, err := scanTrade(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan trade: %w", err)
 		}
 		trades = append(trades, trade)
 	}
 
-	return trades, nil
+	return trades, rows.Err()
 }
@@ -0,0 +1,100 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"order-matching-system/internal/models"
+)
+
+const (
+	defaultBatchSize    = 100
+	defaultPollInterval = 200 * time.Millisecond
+)
+
+// Outbox is the relay's view of the outbox: committed events not yet
+// delivered, in sequence order
+type Outbox interface {
+	GetUnpublished(ctx context.Context, limit int) ([]*models.OutboxEvent, error)
+	MarkPublished(ctx context.Context, sequences []int64) error
+}
+
+// Relay moves committed events from the outbox to a Sink in sequence order.
+// Events are marked published only after the sink accepts them, so a crash
+// between the two redelivers rather than loses them.
+type Relay struct {
+	outbox       Outbox
+	sink         Sink
+	batchSize    int
+	pollInterval time.Duration
+	done         chan struct{}
+}
+
+func NewRelay(outbox Outbox, sink Sink) *Relay {
+	return &Relay{
+		outbox:       outbox,
+		sink:         sink,
+		batchSize:    defaultBatchSize,
+		pollInterval: defaultPollInterval,
+		done:         make(chan struct{}),
+	}
+}
+
+// Run publishes events until ctx is canceled. Errors are logged and the
+// failed batch is retried on the next poll.
+func (r *Relay) Run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.Flush(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorContext(ctx, "failed to flush outbox", "component", "relay", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Done is closed once Run has returned
+func (r *Relay) Done() <-chan struct{} {
+	return r.done
+}
+
+// Flush publishes every pending event and returns how many were delivered
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		batch, err := r.outbox.GetUnpublished(ctx, r.batchSize)
+		if err != nil {
+			return published, err
+		}
+		if len(batch) == 0 {
+			return published, nil
+		}
+
+		if err := r.sink.Publish(ctx, batch); err != nil {
+			return published, fmt.Errorf("failed to publish events: %w", err)
+		}
+
+		sequences := make([]int64, len(batch))
+		for i, event := range batch {
+			sequences[i] = event.Sequence
+		}
+		if err := r.outbox.MarkPublished(ctx, sequences); err != nil {
+			return published, err
+		}
+		published += len(batch)
+
+		if len(batch) < r.batchSize {
+			return published, nil
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+	"order-matching-system/internal/service"
+)
+
+// failingSink refuses the batches whose call numbers, counted from one, are
+// in fail
+type failingSink struct {
+	*MemorySink
+	fail  map[int]bool
+	calls int
+}
+
+func (s *failingSink) Publish(ctx context.Context, events []*models.OutboxEvent) error {
+	s.calls++
+	if s.fail[s.calls] {
+		return errors.New("broker unavailable")
+	}
+	return s.MemorySink.Publish(ctx, events)
+}
+
+// trade fills a resting sell with a smaller buy and cancels the rest, leaving
+// six outbox events: two accepted, the trade, a fill for each side and a cancel
+func trade(t *testing.T, store *memory.Store) {
+	t.Helper()
+	me := service.NewMatchingEngineWithStore(store)
+	ctx := context.Background()
+
+	requests := []*models.PlaceOrderRequest{
+		{AccountID: "acct-s", Symbol: "TEST", Side: models.OrderSideSell, Type: models.OrderTypeLimit, Price: 100, Quantity: 2},
+		{AccountID: "acct-b", Symbol: "TEST", Side: models.OrderSideBuy, Type: models.OrderTypeLimit, Price: 100, Quantity: 1},
+	}
+	var sell *models.Order
+	for _, req := range requests {
+		order := req.NewOrder()
+		if err := me.ProcessOrder(ctx, order); err != nil {
+			t.Fatal(err)
+		}
+		if sell == nil {
+			sell = order
+		}
+	}
+	if _, err := me.CancelOrder(ctx, sell.ID, models.ActorAPI); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sequences(events []*models.OutboxEvent) []int64 {
+	seqs := make([]int64, len(events))
+	for i, event := range events {
+		seqs[i] = event.Sequence
+	}
+	return seqs
+}
+
+func TestFlushDeliversInOrder(t *testing.T) {
+	store := memory.NewStore(nil)
+	trade(t, store)
+
+	sink := NewMemorySink()
+	relay := NewRelay(store.Outbox(), sink)
+	relay.batchSize = 2
+
+	n, err := relay.Flush(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := sink.Events()
+	if n != 6 || len(events) != n {
+		t.Fatalf("delivered %d, sink has %d, want 6", n, len(events))
+	}
+	for i, event := range events {
+		if event.Sequence != int64(i+1) {
+			t.Fatalf("sequences %v, want 1 to %d in order", sequences(events), n)
+		}
+	}
+	if events[2].Type != models.EventTradeExecuted {
+		t.Fatalf("third event is %s, want the trade", events[2].Type)
+	}
+
+	// Order events carry the time they were stored
+	var payload models.OrderEventPayload
+	if err := json.Unmarshal(events[0].Payload, &payload); err != nil {
+		t.Fatal(err)
+	}
+	stored := store.Events(payload.Order.ID)[0]
+	if payload.Event.CreatedAt.IsZero() || !payload.Event.CreatedAt.Equal(stored.CreatedAt) {
+		t.Fatalf("published event time %v, stored %v", payload.Event.CreatedAt, stored.CreatedAt)
+	}
+
+	if n, err := relay.Flush(context.Background()); n != 0 || err != nil {
+		t.Fatalf("second flush delivered %d (%v), want nothing", n, err)
+	}
+}
+
+func TestFlushRetriesFailedBatch(t *testing.T) {
+	store := memory.NewStore(nil)
+	trade(t, store)
+
+	sink := &failingSink{MemorySink: NewMemorySink(), fail: map[int]bool{2: true}}
+	relay := NewRelay(store.Outbox(), sink)
+	relay.batchSize = 4
+
+	n, err := relay.Flush(context.Background())
+	if err == nil || n != 4 {
+		t.Fatalf("flush delivered %d (%v), want the first batch and an error", n, err)
+	}
+
+	// Only the refused batch is sent again
+	n, err = relay.Flush(context.Background())
+	if err != nil || n != 2 {
+		t.Fatalf("retry delivered %d (%v), want 2", n, err)
+	}
+	events := sink.Events()
+	if len(events) != 6 {
+		t.Fatalf("sink has sequences %v, want 1 to 6", sequences(events))
+	}
+	for i, event := range events {
+		if event.Sequence != int64(i+1) {
+			t.Fatalf("sequences %v, want each once in order", sequences(events))
+		}
+	}
+}
+
+func TestRunPublishesUntilCanceled(t *testing.T) {
+	store := memory.NewStore(nil)
+	sink := NewMemorySink()
+	relay := NewRelay(store.Outbox(), sink)
+	relay.pollInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go relay.Run(ctx)
+
+	trade(t, store)
+	deadline := time.Now().Add(5 * time.Second)
+	for len(sink.Events()) < 6 {
+		if time.Now().After(deadline) {
+			t.Fatalf("relay delivered %d events, want 6", len(sink.Events()))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-relay.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("relay did not stop")
+	}
+}
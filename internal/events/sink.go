@@ -0,0 +1,127 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"order-matching-system/internal/models"
+)
+
+// Sink delivers outbox events downstream. Publish is called with events in
+// sequence order and must not return until they are durably accepted; on
+// error the relay retries the whole batch, so sinks see at-least-once
+// delivery and consumers should dedup on Sequence.
+type Sink interface {
+	Publish(ctx context.Context, events []*models.OutboxEvent) error
+	Close() error
+}
+
+// MessagePublisher is the shape of a NATS or Kafka producer: a message body
+// published to a subject/topic with a partitioning key and headers
+type MessagePublisher interface {
+	PublishMessage(ctx context.Context, topic string, key, value []byte, headers map[string]string) error
+	Close() error
+}
+
+// BrokerSink adapts a MessagePublisher into a Sink. Each event is sent to
+// TopicPrefix plus its type, keyed by symbol so that a symbol's events stay
+// ordered within a partition.
+type BrokerSink struct {
+	Publisher   MessagePublisher
+	TopicPrefix string
+}
+
+func (s *BrokerSink) Publish(ctx context.Context, events []*models.OutboxEvent) error {
+	for _, event := range events {
+		value, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode event %d: %w", event.Sequence, err)
+		}
+
+		headers := map[string]string{
+			"sequence":       strconv.FormatInt(event.Sequence, 10),
+			"event_type":     event.Type,
+			"aggregate_type": event.AggregateType,
+			"aggregate_id":   strconv.Itoa(event.AggregateID),
+		}
+
+		err = s.Publisher.PublishMessage(ctx, s.TopicPrefix+event.Type, []byte(event.Symbol), value, headers)
+		if err != nil {
+			return fmt.Errorf("failed to publish event %d: %w", event.Sequence, err)
+		}
+	}
+	return nil
+}
+
+func (s *BrokerSink) Close() error {
+	return s.Publisher.Close()
+}
+
+// FileSink appends events as JSON lines to a local file
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Publish(ctx context.Context, events []*models.OutboxEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enc := json.NewEncoder(s.file)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			return fmt.Errorf("failed to write event %d: %w", event.Sequence, err)
+		}
+	}
+
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync event file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// MemorySink keeps published events in memory, for tests and simulations
+type MemorySink struct {
+	mu     sync.Mutex
+	events []*models.OutboxEvent
+}
+
+func NewMemorySink() *MemorySink {
+	return &MemorySink{}
+}
+
+func (s *MemorySink) Publish(ctx context.Context, events []*models.OutboxEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.events = append(s.events, events...)
+	return nil
+}
+
+// Events returns a copy of everything published so far
+func (s *MemorySink) Events() []*models.OutboxEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*models.OutboxEvent(nil), s.events...)
+}
+
+func (s *MemorySink) Close() error {
+	return nil
+}
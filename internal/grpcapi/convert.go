@@ -0,0 +1,131 @@
+package grpcapi
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"order-matching-system/internal/models"
+	"order-matching-system/pkg/omspb"
+)
+
+var sideToProto = map[models.OrderSide]omspb.Side{
+	models.OrderSideBuy:  omspb.Side_SIDE_BUY,
+	models.OrderSideSell: omspb.Side_SIDE_SELL,
+}
+
+var typeToProto = map[models.OrderType]omspb.OrderType{
+	models.OrderTypeLimit:  omspb.OrderType_ORDER_TYPE_LIMIT,
+	models.OrderTypeMarket: omspb.OrderType_ORDER_TYPE_MARKET,
+}
+
+var statusToProto = map[models.OrderStatus]omspb.OrderStatus{
+	models.OrderStatusOpen:     omspb.OrderStatus_ORDER_STATUS_OPEN,
+	models.OrderStatusFilled:   omspb.OrderStatus_ORDER_STATUS_FILLED,
+	models.OrderStatusCanceled: omspb.OrderStatus_ORDER_STATUS_CANCELED,
+	models.OrderStatusRejected: omspb.OrderStatus_ORDER_STATUS_REJECTED,
+}
+
+// fromProtoSide returns the empty side for SIDE_UNSPECIFIED, which request
+// validation then rejects
+func fromProtoSide(side omspb.Side) models.OrderSide {
+	for s, p := range sideToProto {
+		if p == side {
+			return s
+		}
+	}
+	return ""
+}
+
+func fromProtoType(orderType omspb.OrderType) models.OrderType {
+	for t, p := range typeToProto {
+		if p == orderType {
+			return t
+		}
+	}
+	return ""
+}
+
+func toProtoOrder(order *models.Order) *omspb.Order {
+	return &omspb.Order{
+		Id:                int64(order.ID),
+		AccountId:         order.AccountID,
+		Symbol:            order.Symbol,
+		Side:              sideToProto[order.Side],
+		Type:              typeToProto[order.Type],
+		Price:             order.Price,
+		MaxSlippage:       order.MaxSlippage,
+		InitialQuantity:   order.InitialQuantity,
+		RemainingQuantity: order.RemainingQuantity,
+		FilledQuantity:    order.FilledQuantity,
+		AverageFillPrice:  order.AverageFillPrice,
+		LastFillPrice:     order.LastFillPrice,
+		LastFillAt:        toProtoTimePtr(order.LastFillAt),
+		Status:            statusToProto[order.Status],
+		StatusReason:      order.StatusReason,
+		RejectCode:        string(order.RejectCode),
+		CreatedAt:         toProtoTime(order.CreatedAt),
+		UpdatedAt:         toProtoTime(order.UpdatedAt),
+	}
+}
+
+func toProtoTrade(trade *models.Trade) *omspb.Trade {
+	return &omspb.Trade{
+		Id:          int64(trade.ID),
+		Symbol:      trade.Symbol,
+		BuyOrderId:  int64(trade.BuyOrderID),
+		SellOrderId: int64(trade.SellOrderID),
+		Price:       trade.Price,
+		Quantity:    trade.Quantity,
+		Busted:      trade.Busted,
+		Corrected:   trade.Corrected,
+		CreatedAt:   toProtoTime(trade.CreatedAt),
+	}
+}
+
+func toProtoTrades(trades []*models.Trade) []*omspb.Trade {
+	out := make([]*omspb.Trade, len(trades))
+	for i, trade := range trades {
+		out[i] = toProtoTrade(trade)
+	}
+	return out
+}
+
+func toProtoBook(book *models.OrderBook) *omspb.OrderBook {
+	return &omspb.OrderBook{
+		Symbol:   book.Symbol,
+		Level:    int32(book.Level),
+		Bids:     toProtoEntries(book.Bids),
+		Asks:     toProtoEntries(book.Asks),
+		Spread:   book.Spread,
+		MidPrice: book.MidPrice,
+	}
+}
+
+func toProtoEntries(entries []models.OrderBookEntry) []*omspb.BookEntry {
+	out := make([]*omspb.BookEntry, len(entries))
+	for i, entry := range entries {
+		out[i] = &omspb.BookEntry{
+			Price:     entry.Price,
+			Quantity:  entry.Quantity,
+			Orders:    int32(entry.Orders),
+			OrderId:   int64(entry.OrderID),
+			CreatedAt: toProtoTimePtr(entry.CreatedAt),
+		}
+	}
+	return out
+}
+
+func toProtoTime(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toProtoTimePtr(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return toProtoTime(*t)
+}
@@ -0,0 +1,84 @@
+package grpcapi
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"order-matching-system/internal/logging"
+)
+
+// requestIDKey is the metadata key carrying a call's correlation ID, the gRPC
+// counterpart of the HTTP API's X-Request-ID header
+const requestIDKey = "x-request-id"
+
+// withRequestID tags ctx with the caller's request ID, or a new one, and
+// returns it in the call's response header
+func withRequestID(ctx context.Context) context.Context {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if !logging.ValidRequestID(id) {
+		id = logging.NewRequestID()
+	}
+	grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	return logging.With(ctx, slog.String(logging.RequestIDKey, id))
+}
+
+// logCall logs one line per finished call, at warn level for client errors
+// and error level for server errors
+func logCall(ctx context.Context, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK, codes.Canceled:
+	case codes.Internal, codes.Unknown, codes.Unavailable, codes.DataLoss, codes.DeadlineExceeded:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Duration("latency", time.Since(start)),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	slog.LogAttrs(ctx, level, "call", attrs...)
+}
+
+func unaryLogger(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	ctx = withRequestID(ctx)
+	resp, err := handler(ctx, req)
+	logCall(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func streamLogger(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx := withRequestID(ss.Context())
+	err := handler(srv, &loggedStream{ServerStream: ss, ctx: ctx})
+	logCall(ctx, info.FullMethod, start, err)
+	return err
+}
+
+// loggedStream hands handlers the context tagged with the request ID
+type loggedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *loggedStream) Context() context.Context {
+	return s.ctx
+}
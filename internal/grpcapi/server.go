@@ -0,0 +1,235 @@
+package grpcapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin/binding"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/models"
+	"order-matching-system/internal/service"
+	"order-matching-system/pkg/omspb"
+)
+
+// Server implements the OrderMatching gRPC service on top of the same
+// matching engine and repositories as the HTTP API
+type Server struct {
+	omspb.UnimplementedOrderMatchingServer
+
+	orderRepo      *database.OrderRepository
+	tradeRepo      *database.TradeRepository
+	orderBookRepo  *database.OrderBookRepository
+	matchingEngine *service.MatchingEngine
+}
+
+func NewServer(db *sql.DB, engine *service.MatchingEngine) *Server {
+	dbtx := database.Instrument(db)
+	return &Server{
+		orderRepo:      database.NewOrderRepository(dbtx),
+		tradeRepo:      database.NewTradeRepository(dbtx),
+		orderBookRepo:  database.NewOrderBookRepository(dbtx),
+		matchingEngine: engine,
+	}
+}
+
+// NewGRPCServer returns a gRPC server with the OrderMatching service registered,
+// tracing each call and logging it with its request ID
+func NewGRPCServer(db *sql.DB, engine *service.MatchingEngine, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append([]grpc.ServerOption{
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+		grpc.ChainUnaryInterceptor(unaryLogger),
+		grpc.ChainStreamInterceptor(streamLogger),
+	}, opts...)
+	srv := grpc.NewServer(opts...)
+	omspb.RegisterOrderMatchingServer(srv, NewServer(db, engine))
+	return srv
+}
+
+func (s *Server) PlaceOrder(ctx context.Context, req *omspb.PlaceOrderRequest) (*omspb.PlaceOrderResponse, error) {
+	placeReq := &models.PlaceOrderRequest{
+		AccountID:   req.GetAccountId(),
+		Symbol:      req.GetSymbol(),
+		Side:        fromProtoSide(req.GetSide()),
+		Type:        fromProtoType(req.GetType()),
+		Price:       req.GetPrice(),
+		Quantity:    req.GetQuantity(),
+		MaxSlippage: req.GetMaxSlippage(),
+	}
+	// Apply the same binding rules as the HTTP API
+	if err := binding.Validator.ValidateStruct(placeReq); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := placeReq.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	order := placeReq.NewOrder()
+	order.IdempotencyKey = req.GetIdempotencyKey()
+	if len(order.IdempotencyKey) > 64 {
+		return nil, status.Error(codes.InvalidArgument, "idempotency_key must be at most 64 characters")
+	}
+
+	err := s.matchingEngine.ProcessOrder(ctx, order)
+	if err != nil && !errors.Is(err, service.ErrDuplicateOrder) {
+		return nil, toStatusError(err)
+	}
+
+	return &omspb.PlaceOrderResponse{Order: toProtoOrder(order)}, nil
+}
+
+func (s *Server) CancelOrder(ctx context.Context, req *omspb.CancelOrderRequest) (*omspb.CancelOrderResponse, error) {
+	order, err := s.matchingEngine.CancelOrder(ctx, int(req.GetOrderId()), requestActor(ctx))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &omspb.CancelOrderResponse{Order: toProtoOrder(order)}, nil
+}
+
+func (s *Server) GetOrder(ctx context.Context, req *omspb.GetOrderRequest) (*omspb.GetOrderResponse, error) {
+	order, err := s.orderRepo.GetOrderByID(ctx, int(req.GetOrderId()))
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &omspb.GetOrderResponse{Order: toProtoOrder(order)}, nil
+}
+
+func (s *Server) GetOrderBook(ctx context.Context, req *omspb.GetOrderBookRequest) (*omspb.GetOrderBookResponse, error) {
+	level, depth, err := bookParams(req.GetSymbol(), req.GetLevel(), req.GetDepth())
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := s.orderBookRepo.GetOrderBook(ctx, req.GetSymbol(), level, depth)
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &omspb.GetOrderBookResponse{Book: toProtoBook(book)}, nil
+}
+
+func (s *Server) ListTrades(ctx context.Context, req *omspb.ListTradesRequest) (*omspb.ListTradesResponse, error) {
+	var trades []*models.Trade
+	var err error
+
+	if symbol := req.GetSymbol(); symbol != "" {
+		trades, err = s.tradeRepo.GetTradesBySymbol(ctx, symbol)
+	} else {
+		trades, err = s.tradeRepo.GetAllTrades(ctx)
+	}
+	if err != nil {
+		return nil, toStatusError(err)
+	}
+
+	return &omspb.ListTradesResponse{Trades: toProtoTrades(trades)}, nil
+}
+
+func (s *Server) StreamTrades(req *omspb.StreamTradesRequest, stream grpc.ServerStreamingServer[omspb.Trade]) error {
+	trades, unsubscribe := s.matchingEngine.Feed().SubscribeTrades(req.GetSymbol())
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return stream.Context().Err()
+		case trade, ok := <-trades:
+			if !ok {
+				return status.Error(codes.Unavailable, "trade feed closed")
+			}
+			if err := stream.Send(toProtoTrade(trade)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) StreamBookUpdates(req *omspb.StreamBookUpdatesRequest, stream grpc.ServerStreamingServer[omspb.OrderBook]) error {
+	level, depth, err := bookParams(req.GetSymbol(), req.GetLevel(), req.GetDepth())
+	if err != nil {
+		return err
+	}
+
+	// Subscribe before the first read so no change is missed in between
+	updates, unsubscribe := s.matchingEngine.Feed().SubscribeBook(req.GetSymbol())
+	defer unsubscribe()
+
+	ctx := stream.Context()
+	for {
+		book, err := s.orderBookRepo.GetOrderBook(ctx, req.GetSymbol(), level, depth)
+		if err != nil {
+			return toStatusError(err)
+		}
+		if err := stream.Send(toProtoBook(book)); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-updates:
+			if !ok {
+				return status.Error(codes.Unavailable, "book feed closed")
+			}
+		}
+	}
+}
+
+// bookParams applies the HTTP API's defaults and limits to a book request
+func bookParams(symbol string, level, depth int32) (models.BookLevel, int, error) {
+	if symbol == "" {
+		return 0, 0, status.Error(codes.InvalidArgument, "symbol is required")
+	}
+
+	if level == 0 {
+		level = int32(models.BookLevel2)
+	}
+	if level < int32(models.BookLevel1) || level > int32(models.BookLevel3) {
+		return 0, 0, status.Error(codes.InvalidArgument, "level must be 1, 2 or 3")
+	}
+
+	if depth == 0 {
+		depth = models.DefaultBookDepth
+	}
+	if depth < 0 || depth > models.MaxBookDepth {
+		return 0, 0, status.Error(codes.InvalidArgument, fmt.Sprintf("depth must be between 1 and %d", models.MaxBookDepth))
+	}
+
+	return models.BookLevel(level), int(depth), nil
+}
+
+// toStatusError maps engine and repository errors to gRPC status codes
+func toStatusError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrEngineStopped):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, database.ErrOrderNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrOrderNotCancelable):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "request timed out")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+// requestActor identifies who initiated a call for the audit trail: the ID
+// of the API key it was signed with, if any
+func requestActor(ctx context.Context) string {
+	if keyID := authenticatedKey(ctx); keyID != "" {
+		return keyID
+	}
+	return models.ActorAPI
+}
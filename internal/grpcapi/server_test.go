@@ -0,0 +1,250 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+	"order-matching-system/internal/service"
+	"order-matching-system/pkg/omspb"
+	"order-matching-system/pkg/signing"
+)
+
+// newTestClient serves the gRPC API over an in-memory connection, backed by
+// an engine on an in-memory store
+func newTestClient(t *testing.T, serverOpts []grpc.ServerOption, dialOpts ...grpc.DialOption) (omspb.OrderMatchingClient, *memory.Store) {
+	t.Helper()
+
+	store := memory.NewStore(nil)
+	engine := service.NewMatchingEngineWithStore(store)
+	srv := NewGRPCServer(nil, engine, serverOpts...)
+
+	lis := bufconn.Listen(1 << 20)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	dialOpts = append(dialOpts,
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	conn, err := grpc.NewClient("passthrough:///bufnet", dialOpts...)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return omspb.NewOrderMatchingClient(conn), store
+}
+
+func limitRequest(side omspb.Side, price, quantity float64) *omspb.PlaceOrderRequest {
+	return &omspb.PlaceOrderRequest{
+		AccountId: "acct",
+		Symbol:    "GRPC",
+		Side:      side,
+		Type:      omspb.OrderType_ORDER_TYPE_LIMIT,
+		Price:     price,
+		Quantity:  quantity,
+	}
+}
+
+func TestSignatureAuth(t *testing.T) {
+	keys := map[string]string{"desk1": "s3cret"}
+	auth := SignatureAuth(keys, time.Minute)
+
+	tests := []struct {
+		name   string
+		dial   []grpc.DialOption
+		expect codes.Code
+	}{
+		{"unsigned", nil, codes.Unauthenticated},
+		{"unknown key", []grpc.DialOption{
+			grpc.WithUnaryInterceptor(signing.UnaryClientInterceptor("desk2", "s3cret")),
+			grpc.WithStreamInterceptor(signing.StreamClientInterceptor("desk2", "s3cret")),
+		}, codes.Unauthenticated},
+		{"wrong secret", []grpc.DialOption{
+			grpc.WithUnaryInterceptor(signing.UnaryClientInterceptor("desk1", "guess")),
+			grpc.WithStreamInterceptor(signing.StreamClientInterceptor("desk1", "guess")),
+		}, codes.Unauthenticated},
+		{"signed", []grpc.DialOption{
+			grpc.WithUnaryInterceptor(signing.UnaryClientInterceptor("desk1", "s3cret")),
+			grpc.WithStreamInterceptor(signing.StreamClientInterceptor("desk1", "s3cret")),
+		}, codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := newTestClient(t, auth, tt.dial...)
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			_, err := client.PlaceOrder(ctx, limitRequest(omspb.Side_SIDE_BUY, 100, 1))
+			if code := status.Code(err); code != tt.expect {
+				t.Fatalf("PlaceOrder code = %s, want %s (%v)", code, tt.expect, err)
+			}
+
+			// A stream that fails authentication ends on its first receive;
+			// an authenticated one stays open until the deadline
+			streamCtx, cancelStream := context.WithTimeout(ctx, 200*time.Millisecond)
+			defer cancelStream()
+			stream, err := client.StreamTrades(streamCtx, &omspb.StreamTradesRequest{Symbol: "GRPC"})
+			if err == nil {
+				_, err = stream.Recv()
+			}
+			want := tt.expect
+			if want == codes.OK {
+				want = codes.DeadlineExceeded
+			}
+			if code := status.Code(err); code != want {
+				t.Fatalf("StreamTrades code = %s, want %s (%v)", code, want, err)
+			}
+		})
+	}
+}
+
+func TestCancelRecordsSigningKey(t *testing.T) {
+	client, store := newTestClient(t, SignatureAuth(map[string]string{"desk1": "s3cret"}, time.Minute),
+		grpc.WithUnaryInterceptor(signing.UnaryClientInterceptor("desk1", "s3cret")))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-actor", "someone-else")
+
+	placed, err := client.PlaceOrder(ctx, limitRequest(omspb.Side_SIDE_BUY, 100, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.CancelOrder(ctx, &omspb.CancelOrderRequest{OrderId: placed.GetOrder().GetId()}); err != nil {
+		t.Fatal(err)
+	}
+
+	events := store.Events(int(placed.GetOrder().GetId()))
+	last := events[len(events)-1]
+	if last.Type != models.OrderEventCanceled || last.Actor != "desk1" {
+		t.Fatalf("last event %s by %q, want canceled by desk1", last.Type, last.Actor)
+	}
+}
+
+// TestSignatureAuthBindsRequest checks that a signature made for one request
+// does not authorize another
+func TestSignatureAuthBindsRequest(t *testing.T) {
+	swap := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		// Sign the request as sent, then change it before it leaves
+		return signing.UnaryClientInterceptor("desk1", "s3cret")(ctx, method, req, reply, cc,
+			func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+				req.(*omspb.PlaceOrderRequest).Quantity = 1000
+				return invoker(ctx, method, req, reply, cc, opts...)
+			}, opts...)
+	}
+	client, _ := newTestClient(t, SignatureAuth(map[string]string{"desk1": "s3cret"}, time.Minute), grpc.WithUnaryInterceptor(swap))
+
+	_, err := client.PlaceOrder(context.Background(), limitRequest(omspb.Side_SIDE_BUY, 100, 1))
+	if code := status.Code(err); code != codes.Unauthenticated {
+		t.Fatalf("PlaceOrder code = %s, want %s (%v)", code, codes.Unauthenticated, err)
+	}
+}
+
+func TestPlaceAndCancelOrder(t *testing.T) {
+	client, store := newTestClient(t, nil)
+	ctx := context.Background()
+
+	sell, err := client.PlaceOrder(ctx, limitRequest(omspb.Side_SIDE_SELL, 100, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := sell.GetOrder(); got.GetStatus() != omspb.OrderStatus_ORDER_STATUS_OPEN || got.GetRemainingQuantity() != 2 {
+		t.Fatalf("sell is %s with %v remaining, want open with 2", got.GetStatus(), got.GetRemainingQuantity())
+	}
+
+	buy, err := client.PlaceOrder(ctx, limitRequest(omspb.Side_SIDE_BUY, 101, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := buy.GetOrder(); got.GetStatus() != omspb.OrderStatus_ORDER_STATUS_FILLED || got.GetAverageFillPrice() != 100 {
+		t.Fatalf("buy is %s at %v, want filled at 100", got.GetStatus(), got.GetAverageFillPrice())
+	}
+
+	_, err = client.PlaceOrder(ctx, limitRequest(omspb.Side_SIDE_BUY, 100, 0))
+	if code := status.Code(err); code != codes.InvalidArgument {
+		t.Fatalf("zero quantity code = %s, want %s", code, codes.InvalidArgument)
+	}
+
+	canceled, err := client.CancelOrder(ctx, &omspb.CancelOrderRequest{OrderId: sell.GetOrder().GetId()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := canceled.GetOrder(); got.GetStatus() != omspb.OrderStatus_ORDER_STATUS_CANCELED || got.GetFilledQuantity() != 1 {
+		t.Fatalf("canceled sell is %s with %v filled", got.GetStatus(), got.GetFilledQuantity())
+	}
+	if got := store.Order(int(sell.GetOrder().GetId())); got.Status != models.OrderStatusCanceled {
+		t.Fatalf("stored sell is %s, want canceled", got.Status)
+	}
+
+	_, err = client.CancelOrder(ctx, &omspb.CancelOrderRequest{OrderId: sell.GetOrder().GetId()})
+	if code := status.Code(err); code != codes.FailedPrecondition {
+		t.Fatalf("second cancel code = %s, want %s", code, codes.FailedPrecondition)
+	}
+	_, err = client.CancelOrder(ctx, &omspb.CancelOrderRequest{OrderId: 999})
+	if code := status.Code(err); code != codes.NotFound {
+		t.Fatalf("unknown order cancel code = %s, want %s", code, codes.NotFound)
+	}
+}
+
+func TestPlaceOrderIdempotentReplay(t *testing.T) {
+	client, store := newTestClient(t, nil)
+	ctx := context.Background()
+
+	req := limitRequest(omspb.Side_SIDE_BUY, 100, 1)
+	req.IdempotencyKey = "key-1"
+	first, err := client.PlaceOrder(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replayed, err := client.PlaceOrder(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.GetOrder().GetId() != first.GetOrder().GetId() {
+		t.Fatalf("replay returned order %d, want %d", replayed.GetOrder().GetId(), first.GetOrder().GetId())
+	}
+	if open := store.OpenOrders("acct", "GRPC"); len(open) != 1 {
+		t.Fatalf("%d open orders, want 1", len(open))
+	}
+
+	other := limitRequest(omspb.Side_SIDE_BUY, 99, 1)
+	other.IdempotencyKey = "key-1"
+	_, err = client.PlaceOrder(ctx, other)
+	if code := status.Code(err); code != codes.AlreadyExists {
+		t.Fatalf("reused key code = %s, want %s", code, codes.AlreadyExists)
+	}
+}
+
+func TestToStatusError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want codes.Code
+	}{
+		{service.ErrEngineStopped, codes.Unavailable},
+		{service.ErrIdempotencyKeyReused, codes.AlreadyExists},
+		{database.ErrOrderNotFound, codes.NotFound},
+		{fmt.Errorf("failed to load: %w", database.ErrOrderNotFound), codes.NotFound},
+		{service.ErrOrderNotCancelable, codes.FailedPrecondition},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{context.Canceled, codes.Canceled},
+		{errors.New("order not found"), codes.Internal},
+	}
+	for _, tt := range tests {
+		if got := status.Code(toStatusError(tt.err)); got != tt.want {
+			t.Errorf("toStatusError(%q) code = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
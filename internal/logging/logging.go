@@ -0,0 +1,126 @@
+// Package logging sets up structured logging with log/slog and carries
+// correlation fields, such as request and order IDs, in contexts so that
+// every record logged with a context includes them, along with its trace.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Format selects how records are written
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatText Format = "text"
+)
+
+// New returns a logger writing records at level and above to w in format.
+// Records logged with a context carry the fields added to it by With.
+func New(w io.Writer, format Format, level slog.Leveler) (*slog.Logger, error) {
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q, expected json or text", format)
+	}
+	return slog.New(contextHandler{handler}), nil
+}
+
+// ParseLevel reads a level name: debug, info, warn or error
+func ParseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", name)
+	}
+	return level, nil
+}
+
+type attrsKey struct{}
+
+// With returns a copy of ctx whose log records also carry attrs
+func With(ctx context.Context, attrs ...slog.Attr) context.Context {
+	existing := Attrs(ctx)
+	combined := make([]slog.Attr, 0, len(existing)+len(attrs))
+	combined = append(append(combined, existing...), attrs...)
+	return context.WithValue(ctx, attrsKey{}, combined)
+}
+
+// Attrs returns the fields added to ctx by With
+func Attrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// contextHandler adds the fields carried by a record's context, and the IDs
+// of the trace and span it is in, if any
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	attrs := Attrs(ctx)
+	span := trace.SpanContextFromContext(ctx)
+	if len(attrs) == 0 && !span.IsValid() {
+		return h.Handler.Handle(ctx, r)
+	}
+
+	r = r.Clone()
+	r.AddAttrs(attrs...)
+	if span.IsValid() {
+		r.AddAttrs(slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// RequestIDKey is the field request IDs are logged under
+const RequestIDKey = "request_id"
+
+// NewRequestID returns a random ID for a request that arrived without one
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID reports whether a caller-supplied request ID is safe to
+// log and echo back: at most 64 characters from a conservative set
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	return strings.IndexFunc(id, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_.:", r))
+	}) < 0
+}
+
+// RequestID returns the request ID carried by ctx, or ""
+func RequestID(ctx context.Context) string {
+	for _, attr := range Attrs(ctx) {
+		if attr.Key == RequestIDKey {
+			return attr.Value.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,147 @@
+package metrics
+
+import (
+	"database/sql"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "oms"
+
+// ProcessOrder phases reported by ProcessOrderDuration
+const (
+	PhaseLockWait = "lock_wait"
+	PhaseDB       = "db"
+	PhaseMatching = "matching"
+	PhaseTotal    = "total"
+)
+
+var (
+	ProcessOrderDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "engine",
+		Name:      "process_order_duration_seconds",
+		Help:      "Time spent in ProcessOrder, split by phase.",
+		Buckets:   prometheus.ExponentialBuckets(0.0001, 2, 16),
+	}, []string{"phase"})
+
+	OrdersTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "engine",
+		Name:      "orders_total",
+		Help:      "Orders processed by the matching engine, by type, side and outcome.",
+	}, []string{"type", "side", "outcome"})
+
+	TradesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "engine",
+		Name:      "trades_total",
+		Help:      "Trades executed, by symbol.",
+	}, []string{"symbol"})
+
+	TradedVolume = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "engine",
+		Name:      "traded_volume_total",
+		Help:      "Quantity traded, by symbol.",
+	}, []string{"symbol"})
+
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests handled, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency, by method and route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// RegisterDB adds connection pool statistics and resting order gauges for db
+func RegisterDB(db *sql.DB) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, "orders"))
+	prometheus.MustRegister(newRestingOrdersCollector(db))
+}
+
+// GinMiddleware records request counts and latency per matched route
+func GinMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		httpRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		httpDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// restingOrdersCollector reports open orders per symbol and side, read from
+// the database at scrape time so it never drifts from the book
+type restingOrdersCollector struct {
+	db       *sql.DB
+	count    *prometheus.Desc
+	quantity *prometheus.Desc
+}
+
+func newRestingOrdersCollector(db *sql.DB) *restingOrdersCollector {
+	return &restingOrdersCollector{
+		db: db,
+		count: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "book", "resting_orders"),
+			"Open orders resting in the book, by symbol and side.",
+			[]string{"symbol", "side"}, nil,
+		),
+		quantity: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "book", "resting_quantity"),
+			"Remaining quantity resting in the book, by symbol and side.",
+			[]string{"symbol", "side"}, nil,
+		),
+	}
+}
+
+func (c *restingOrdersCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.count
+	ch <- c.quantity
+}
+
+func (c *restingOrdersCollector) Collect(ch chan<- prometheus.Metric) {
+	query := `
+		SELECT symbol, side, COUNT(*), SUM(remaining_quantity)
+		FROM orders
+		WHERE status = 'open'
+		GROUP BY symbol, side
+	`
+
+	rows, err := c.db.Query(query)
+	if err != nil {
+		slog.Error("failed to collect resting orders", "component", "metrics", "error", err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var symbol, side string
+		var count, quantity float64
+		if err := rows.Scan(&symbol, &side, &count, &quantity); err != nil {
+			slog.Error("failed to scan resting orders", "component", "metrics", "error", err)
+			return
+		}
+		ch <- prometheus.MustNewConstMetric(c.count, prometheus.GaugeValue, count, symbol, side)
+		ch <- prometheus.MustNewConstMetric(c.quantity, prometheus.GaugeValue, quantity, symbol, side)
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+)
+
+type CandleInterval string
+
+const (
+	CandleInterval1m CandleInterval = "1m"
+	CandleInterval5m CandleInterval = "5m"
+	CandleInterval1h CandleInterval = "1h"
+	CandleInterval1d CandleInterval = "1d"
+)
+
+// CandleIntervals lists every interval maintained as trades are created
+var CandleIntervals = []CandleInterval{
+	CandleInterval1m,
+	CandleInterval5m,
+	CandleInterval1h,
+	CandleInterval1d,
+}
+
+// Duration returns the bar length, or false for an unknown interval
+func (i CandleInterval) Duration() (time.Duration, bool) {
+	switch i {
+	case CandleInterval1m:
+		return time.Minute, true
+	case CandleInterval5m:
+		return 5 * time.Minute, true
+	case CandleInterval1h:
+		return time.Hour, true
+	case CandleInterval1d:
+		return 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// BucketStart returns the UTC start of the bar containing t
+func (i CandleInterval) BucketStart(t time.Time) time.Time {
+	d, _ := i.Duration()
+	return t.UTC().Truncate(d)
+}
+
+type Candle struct {
+	Symbol     string         `json:"symbol"`
+	Interval   CandleInterval `json:"interval"`
+	OpenTime   time.Time      `json:"open_time"`
+	Open       float64        `json:"open"`
+	High       float64        `json:"high"`
+	Low        float64        `json:"low"`
+	Close      float64        `json:"close"`
+	Volume     float64        `json:"volume"`
+	VWAP       float64        `json:"vwap"`
+	TradeCount int            `json:"trade_count"`
+}
+
+type Ticker struct {
+	Symbol             string    `json:"symbol"`
+	LastPrice          float64   `json:"last_price"`
+	LastTradeAt        time.Time `json:"last_trade_at"`
+	PriceChange        float64   `json:"price_change"`         // Last price minus the first price in the window
+	PriceChangePercent float64   `json:"price_change_percent"` // PriceChange relative to the first price in the window
+	High               float64   `json:"high"`
+	Low                float64   `json:"low"`
+	Volume             float64   `json:"volume"`
+	BestBid            *float64  `json:"best_bid,omitempty"`
+	BestAsk            *float64  `json:"best_ask,omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// TradingHalt stops new orders and amendments for a symbol until an operator
+// resumes it. Cancels are still accepted.
+type TradingHalt struct {
+	Symbol   string    `json:"symbol"`
+	Reason   string    `json:"reason"`
+	HaltedBy string    `json:"halted_by"`
+	HaltedAt time.Time `json:"halted_at"`
+}
+
+type HaltRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+// MassCancelResult lists the orders canceled by a mass cancel
+type MassCancelResult struct {
+	Canceled int   `json:"canceled"`
+	OrderIDs []int `json:"order_ids"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+type MatchingAlgorithm string
+
+const (
+	MatchingFIFO    MatchingAlgorithm = "fifo"     // Price-time priority
+	MatchingFIFOLMM MatchingAlgorithm = "fifo_lmm" // Price-time with a lead market maker allocation
+	MatchingProRata MatchingAlgorithm = "pro_rata" // Size-proportional allocation at each price
+)
+
+// Instrument holds the per-symbol matching configuration. Symbols without a
+// configured instrument trade with DefaultInstrument.
+type Instrument struct {
+	Symbol           string            `json:"symbol"`
+	Algorithm        MatchingAlgorithm `json:"matching_algorithm" binding:"omitempty,oneof=fifo fifo_lmm pro_rata"`
+	LotSize          float64           `json:"lot_size" binding:"omitempty,gt=0"`
+	TickSize         float64           `json:"tick_size" binding:"omitempty,gt=0"`
+	MinAllocation    float64           `json:"min_allocation" binding:"omitempty,min=0"`
+	TopOrderPriority bool              `json:"top_order_priority"`
+	LMMAccountID     string            `json:"lmm_account_id,omitempty"`
+	LMMPercentage    float64           `json:"lmm_percentage" binding:"omitempty,min=0,max=100"`
+	// Market orders may not trade further from the best price at arrival
+	// than this band; zero disables each bound and the tighter one applies
+	ProtectionBandPercent float64   `json:"protection_band_percent" binding:"omitempty,min=0,max=100"`
+	ProtectionBandTicks   int       `json:"protection_band_ticks" binding:"omitempty,min=0"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+// DefaultInstrument returns the configuration used for unconfigured symbols
+func DefaultInstrument(symbol string) *Instrument {
+	return &Instrument{
+		Symbol:    symbol,
+		Algorithm: MatchingFIFO,
+		LotSize:   1 / QuantityScale,
+		TickSize:  0.01,
+	}
+}
+
+// QuantityScale is the number of fixed-point units per unit of quantity,
+// matching the 8 decimal places stored in the database
+const QuantityScale = 1e8
+
+// ToUnits converts a quantity to fixed-point units
+func ToUnits(quantity float64) int64 {
+	return int64(math.Round(quantity * QuantityScale))
+}
+
+// FromUnits converts fixed-point units back to a quantity
+func FromUnits(units int64) float64 {
+	return float64(units) / QuantityScale
+}
+
+// IsWholeUnits reports whether quantity is a whole number of fixed-point
+// units, that is, has at most 8 decimal places
+func IsWholeUnits(quantity float64) bool {
+	return FromUnits(ToUnits(quantity)) == quantity
+}
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -24,37 +26,214 @@ const (
 	OrderStatusOpen     OrderStatus = "open"
 	OrderStatusFilled   OrderStatus = "filled"
 	OrderStatusCanceled OrderStatus = "canceled"
+	OrderStatusRejected OrderStatus = "rejected" // Failed a pre-trade risk check; never entered the book
+)
+
+// RejectCode identifies the pre-trade risk check that rejected an order
+type RejectCode string
+
+const (
+	RejectQuantityLimit   RejectCode = "quantity_limit"
+	RejectNotionalLimit   RejectCode = "notional_limit"
+	RejectPriceCollar     RejectCode = "price_collar"
+	RejectOpenOrdersLimit RejectCode = "open_orders_limit"
+	RejectPositionLimit   RejectCode = "position_limit"
+	RejectDailyLossLimit  RejectCode = "daily_loss_limit"
+	RejectOrderRateLimit  RejectCode = "order_rate_limit"
+	RejectTradingHalted   RejectCode = "trading_halted" // The symbol is halted by an operator
 )
 
 type Order struct {
 	ID                int         `json:"id"`
+	AccountID         string      `json:"account_id,omitempty"`
+	IdempotencyKey    string      `json:"idempotency_key,omitempty"` // Client-chosen key that makes resubmission safe
 	Symbol            string      `json:"symbol"`
 	Side              OrderSide   `json:"side"`
 	Type              OrderType   `json:"type"`
-	Price             float64     `json:"price,omitempty"` // Only for limit orders
+	Price             float64     `json:"price,omitempty"`        // Only for limit orders
+	MaxSlippage       float64     `json:"max_slippage,omitempty"` // Only for market orders
 	InitialQuantity   float64     `json:"initial_quantity"`
 	RemainingQuantity float64     `json:"remaining_quantity"`
+	FilledQuantity    float64     `json:"filled_quantity"`
+	AverageFillPrice  float64     `json:"average_fill_price,omitempty"` // Volume-weighted over all fills
+	LastFillPrice     float64     `json:"last_fill_price,omitempty"`
+	LastFillAt        *time.Time  `json:"last_fill_at,omitempty"`
 	Status            OrderStatus `json:"status"`
+	StatusReason      string      `json:"status_reason,omitempty"` // Why the order was canceled or rejected
+	RejectCode        RejectCode  `json:"reject_code,omitempty"`
 	CreatedAt         time.Time   `json:"created_at"`
 	UpdatedAt         time.Time   `json:"updated_at"`
 }
 
+// ApplyFill updates the remaining quantity and fill summary for a trade of
+// quantity at price
+func (o *Order) ApplyFill(price, quantity float64, at time.Time) {
+	notional := o.AverageFillPrice*o.FilledQuantity + price*quantity
+	// Quantities are added in fixed point so that a fully filled order ends
+	// at exactly zero remaining
+	o.FilledQuantity = FromUnits(ToUnits(o.FilledQuantity) + ToUnits(quantity))
+	o.AverageFillPrice = notional / o.FilledQuantity
+	o.RemainingQuantity = FromUnits(ToUnits(o.RemainingQuantity) - ToUnits(quantity))
+	o.LastFillPrice = price
+	o.LastFillAt = &at
+}
+
+// OrderFills is an order's execution summary with the trades that filled it
+type OrderFills struct {
+	OrderID          int      `json:"order_id"`
+	FilledQuantity   float64  `json:"filled_quantity"`
+	AverageFillPrice float64  `json:"average_fill_price,omitempty"`
+	Fills            []*Trade `json:"fills"`
+}
+
 type PlaceOrderRequest struct {
-	Symbol   string    `json:"symbol" binding:"required"`
-	Side     OrderSide `json:"side" binding:"required,oneof=buy sell"`
-	Type     OrderType `json:"type" binding:"required,oneof=limit market"`
-	Price    float64   `json:"price" binding:"omitempty,min=0"`
-	Quantity float64   `json:"quantity" binding:"required,min=0"`
+	AccountID string    `json:"account_id" binding:"omitempty,max=64"`
+	Symbol    string    `json:"symbol" binding:"required"`
+	Side      OrderSide `json:"side" binding:"required,oneof=buy sell"`
+	Type      OrderType `json:"type" binding:"required,oneof=limit market"`
+	Price     float64   `json:"price" binding:"omitempty,min=0"`
+	Quantity  float64   `json:"quantity" binding:"required,min=0"`
+	// Market orders only: cancel any remainder that would trade further than
+	// this from the best opposite price at arrival
+	MaxSlippage float64 `json:"max_slippage" binding:"omitempty,gt=0"`
+}
+
+// MaxValue bounds prices and quantities to what the DECIMAL(18, 8) columns
+// hold, which also keeps quantities within fixed-point range
+const MaxValue = 1e10
+
+var errValueTooLarge = fmt.Errorf("price and quantity must be less than %.0f", MaxValue)
+
+var errQuantityPrecision = errors.New("quantity must have at most 8 decimal places")
+
+// Validate checks the rules that the binding tags cannot express
+func (r *PlaceOrderRequest) Validate() error {
+	// Validate price for limit orders
+	if r.Type == OrderTypeLimit && r.Price <= 0 {
+		return errors.New("price must be greater than 0 for limit orders")
+	}
+
+	// Validate quantity
+	if r.Quantity <= 0 {
+		return errors.New("quantity must be greater than 0")
+	}
+	if r.Price >= MaxValue || r.Quantity >= MaxValue || r.MaxSlippage >= MaxValue {
+		return errValueTooLarge
+	}
+	if !IsWholeUnits(r.Quantity) {
+		return errQuantityPrecision
+	}
+
+	// Slippage limits only make sense for orders without a price
+	if r.MaxSlippage > 0 && r.Type != OrderTypeMarket {
+		return errors.New("max_slippage only applies to market orders")
+	}
+
+	return nil
+}
+
+// SameRequest reports whether other was placed with the same parameters as
+// o, so that a resubmission under one idempotency key can be told apart from
+// a key reused for a different order
+func (o *Order) SameRequest(other *Order) bool {
+	return o.AccountID == other.AccountID &&
+		o.Symbol == other.Symbol &&
+		o.Side == other.Side &&
+		o.Type == other.Type &&
+		o.Price == other.Price &&
+		o.MaxSlippage == other.MaxSlippage &&
+		o.InitialQuantity == other.InitialQuantity
 }
 
+// AmendOrderRequest changes a resting limit order. Quantity is the new total
+// order quantity, including any part already filled. Omitted fields keep
+// their current value.
+type AmendOrderRequest struct {
+	Price    float64 `json:"price" binding:"omitempty,gt=0"`
+	Quantity float64 `json:"quantity" binding:"omitempty,gt=0"`
+}
+
+// Validate checks the rules that the binding tags cannot express
+func (r *AmendOrderRequest) Validate() error {
+	if r.Price == 0 && r.Quantity == 0 {
+		return errors.New("price or quantity is required")
+	}
+	if r.Price >= MaxValue || r.Quantity >= MaxValue {
+		return errValueTooLarge
+	}
+	if !IsWholeUnits(r.Quantity) {
+		return errQuantityPrecision
+	}
+	return nil
+}
+
+// OrderFilter selects orders to list or mass cancel. Empty fields match
+// every order.
+type OrderFilter struct {
+	Symbol    string      `json:"symbol" form:"symbol"`
+	AccountID string      `json:"account_id" form:"account_id"`
+	Side      OrderSide   `json:"side" form:"side" binding:"omitempty,oneof=buy sell"`
+	Status    OrderStatus `json:"-" form:"status" binding:"omitempty,oneof=open filled canceled rejected"`
+	Limit     int         `json:"-" form:"limit" binding:"omitempty,min=1,max=1000"`
+	BeforeID  int         `json:"-" form:"before_id" binding:"omitempty,min=1"` // Only older orders, to page through a listing
+}
+
+// NewOrder builds the order to submit to the matching engine
+func (r *PlaceOrderRequest) NewOrder() *Order {
+	return &Order{
+		AccountID:       r.AccountID,
+		Symbol:          r.Symbol,
+		Side:            r.Side,
+		Type:            r.Type,
+		Price:           r.Price,
+		MaxSlippage:     r.MaxSlippage,
+		InitialQuantity: r.Quantity,
+	}
+}
+
+// BookLevel selects how much detail the order book endpoint returns
+type BookLevel int
+
+const (
+	BookLevel1 BookLevel = 1 // Best bid and ask only
+	BookLevel2 BookLevel = 2 // Aggregated price levels
+	BookLevel3 BookLevel = 3 // Individual resting orders
+)
+
+const (
+	DefaultBookDepth = 50
+	MaxBookDepth     = 1000
+)
+
 type OrderBookEntry struct {
-	Price    float64 `json:"price"`
-	Quantity float64 `json:"quantity"`
-	Orders   int     `json:"orders"` // Number of orders at this price level
+	Price     float64    `json:"price"`
+	Quantity  float64    `json:"quantity"`
+	Orders    int        `json:"orders,omitempty"`     // Number of orders at this price level (L1/L2)
+	OrderID   int        `json:"order_id,omitempty"`   // Resting order ID (L3 only)
+	CreatedAt *time.Time `json:"created_at,omitempty"` // Resting order timestamp (L3 only)
 }
 
 type OrderBook struct {
-	Symbol string           `json:"symbol"`
-	Bids   []OrderBookEntry `json:"bids"` // Buy orders (sorted highest to lowest)
-	Asks   []OrderBookEntry `json:"asks"` // Sell orders (sorted lowest to highest)
+	Symbol   string           `json:"symbol"`
+	Level    BookLevel        `json:"level"`
+	Bids     []OrderBookEntry `json:"bids"`                // Buy orders (sorted highest to lowest)
+	Asks     []OrderBookEntry `json:"asks"`                // Sell orders (sorted lowest to highest)
+	Spread   *float64         `json:"spread,omitempty"`    // Best ask minus best bid, when both sides exist
+	MidPrice *float64         `json:"mid_price,omitempty"` // Midpoint of best bid and best ask
+}
+
+// SetSpread fills in Spread and MidPrice from the top of each side of the book
+func (ob *OrderBook) SetSpread() {
+	ob.Spread = nil
+	ob.MidPrice = nil
+	if len(ob.Bids) == 0 || len(ob.Asks) == 0 {
+		return
+	}
+
+	bestBid := ob.Bids[0].Price
+	bestAsk := ob.Asks[0].Price
+	spread := bestAsk - bestBid
+	mid := (bestAsk + bestBid) / 2
+	ob.Spread = &spread
+	ob.MidPrice = &mid
 }
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+type OrderEventType string
+
+const (
+	OrderEventAccepted        OrderEventType = "accepted"
+	OrderEventPartiallyFilled OrderEventType = "partially_filled"
+	OrderEventFilled          OrderEventType = "filled"
+	OrderEventCanceled        OrderEventType = "canceled"
+	OrderEventExpired         OrderEventType = "expired"
+	OrderEventAmended         OrderEventType = "amended"
+	OrderEventRejected        OrderEventType = "rejected"
+	OrderEventTradeBusted     OrderEventType = "trade_busted"    // A trade the order took part in was voided
+	OrderEventTradeCorrected  OrderEventType = "trade_corrected" // A trade the order took part in was amended
+)
+
+// Actors recorded on order events that are not initiated by a client
+const (
+	ActorEngine = "engine"
+	ActorAPI    = "api"
+)
+
+// OrderEvent is one state transition in an order's lifecycle
+type OrderEvent struct {
+	ID              int64          `json:"id"`
+	OrderID         int            `json:"order_id"`
+	Type            OrderEventType `json:"type"`
+	StatusBefore    OrderStatus    `json:"status_before,omitempty"`
+	StatusAfter     OrderStatus    `json:"status_after"`
+	RemainingBefore float64        `json:"remaining_before"`
+	RemainingAfter  float64        `json:"remaining_after"`
+	Price           float64        `json:"price,omitempty"`    // Limit price after acceptance or amendment
+	TradeID         *int           `json:"trade_id,omitempty"` // Trade that caused a fill
+	Cause           string         `json:"cause"`
+	Actor           string         `json:"actor"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+)
+
+// FlowEventKind is what happened in a recorded order stream
+type FlowEventKind string
+
+const (
+	FlowOrder  FlowEventKind = "order"  // An order arrived
+	FlowCancel FlowEventKind = "cancel" // A resting order was canceled
+	FlowAmend  FlowEventKind = "amend"  // A resting order's price or quantity was changed
+)
+
+// FlowEvent is one entry of a recorded order stream, as replayed by
+// backtests. OrderID is the order's ID in the recording; a cancel or amend
+// refers to an earlier order by it. A cancel carries no order details, and
+// an amend only the new price and total quantity.
+type FlowEvent struct {
+	Time        time.Time     `json:"time"`
+	Kind        FlowEventKind `json:"kind"`
+	OrderID     int           `json:"order_id"`
+	AccountID   string        `json:"account_id,omitempty"`
+	Symbol      string        `json:"symbol,omitempty"`
+	Side        OrderSide     `json:"side,omitempty"`
+	Type        OrderType     `json:"type,omitempty"`
+	Price       float64       `json:"price,omitempty"`
+	MaxSlippage float64       `json:"max_slippage,omitempty"`
+	Quantity    float64       `json:"quantity,omitempty"`
+}
+
+// PlaceOrderRequest returns the request that places the event's order
+func (e *FlowEvent) PlaceOrderRequest() *PlaceOrderRequest {
+	return &PlaceOrderRequest{
+		AccountID:   e.AccountID,
+		Symbol:      e.Symbol,
+		Side:        e.Side,
+		Type:        e.Type,
+		Price:       e.Price,
+		MaxSlippage: e.MaxSlippage,
+		Quantity:    e.Quantity,
+	}
+}
+
+// AmendOrderRequest returns the request that makes the event's amendment
+func (e *FlowEvent) AmendOrderRequest() *AmendOrderRequest {
+	return &AmendOrderRequest{Price: e.Price, Quantity: e.Quantity}
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Outbox event types published to downstream systems
+const (
+	EventTradeExecuted  = "trade.executed"
+	EventTradeBusted    = "trade.busted"
+	EventTradeCorrected = "trade.corrected"
+	EventOrderPrefix    = "order." // Followed by the OrderEventType, e.g. order.filled
+)
+
+// Aggregate types identifying what an outbox event's AggregateID refers to
+const (
+	AggregateOrder = "order"
+	AggregateTrade = "trade"
+)
+
+// OutboxEvent is a change written in the same transaction as the state it
+// describes. Sequence increases in commit order and is stable across
+// redeliveries, so consumers can use it to discard duplicates.
+type OutboxEvent struct {
+	Sequence      int64           `json:"sequence"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   int             `json:"aggregate_id"`
+	Symbol        string          `json:"symbol"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// TradeCorrectionPayload is the payload of trade.busted and trade.corrected
+// outbox events: the trade as it now stands and the correction record
+type TradeCorrectionPayload struct {
+	Trade      *Trade           `json:"trade"`
+	Correction *TradeCorrection `json:"correction"`
+}
+
+// OrderEventPayload is the payload of order.* outbox events
+type OrderEventPayload struct {
+	Event *OrderEvent `json:"event"`
+	Order *Order      `json:"order"`
+}
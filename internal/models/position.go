@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+)
+
+// CostMethod selects how closing trades are matched against the cost of a
+// position when realizing PnL
+type CostMethod string
+
+const (
+	CostMethodAverage CostMethod = "average" // Close against the running average cost
+	CostMethodFIFO    CostMethod = "fifo"    // Close against the oldest open lots first
+)
+
+// Position is an account's net holding in one symbol. Quantity is negative
+// for a short position.
+type Position struct {
+	AccountID     string    `json:"account_id"`
+	Symbol        string    `json:"symbol"`
+	Quantity      float64   `json:"quantity"`
+	AverageCost   float64   `json:"average_cost"`
+	RealizedPnL   float64   `json:"realized_pnl"`
+	LastPrice     float64   `json:"last_price,omitempty"`
+	UnrealizedPnL float64   `json:"unrealized_pnl"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// MarkToMarket values the open quantity at price
+func (p *Position) MarkToMarket(price float64) {
+	p.LastPrice = price
+	p.UnrealizedPnL = 0
+	if price > 0 {
+		p.UnrealizedPnL = p.Quantity * (price - p.AverageCost)
+	}
+}
+
+// AccountFill is one account's side of a trade, with a negative quantity
+// for a sell
+type AccountFill struct {
+	TradeID  int
+	Quantity float64
+	Price    float64
+}
+
+// PositionLot is an open slice of a position at the price it was traded,
+// kept oldest first for FIFO cost accounting. Quantity has the sign of the
+// position.
+type PositionLot struct {
+	ID        int64
+	AccountID string
+	Symbol    string
+	Quantity  float64
+	Price     float64
+	CreatedAt time.Time
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// BookOrder is a resting limit order as it appears in a book snapshot
+type BookOrder struct {
+	OrderID   int       `json:"order_id"`
+	Side      OrderSide `json:"side"`
+	Price     float64   `json:"price"`
+	Quantity  float64   `json:"quantity"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BookSnapshot is the resting book for a symbol as of LastEventID
+type BookSnapshot struct {
+	ID          int64       `json:"id"`
+	Symbol      string      `json:"symbol"`
+	TakenAt     time.Time   `json:"taken_at"`
+	LastEventID int64       `json:"last_event_id"`
+	Orders      []BookOrder `json:"orders"`
+}
+
+// BookEvent is an order event together with the order it applies to, as
+// needed to replay the book forward from a snapshot
+type BookEvent struct {
+	Event *OrderEvent
+	Order BookOrder // Quantity is the order's remaining quantity after the event
+	Type  OrderType
+}
+
+// BuildOrderBook arranges resting orders into a book at the given level of
+// detail, with at most depth entries per side
+func BuildOrderBook(symbol string, level BookLevel, depth int, orders []BookOrder) *OrderBook {
+	var bids, asks []BookOrder
+	for _, order := range orders {
+		if order.Side == OrderSideBuy {
+			bids = append(bids, order)
+		} else {
+			asks = append(asks, order)
+		}
+	}
+
+	// Price-time priority: best price, then oldest, then lowest ID
+	sortSide := func(side []BookOrder, better func(a, b float64) bool) {
+		sort.Slice(side, func(i, j int) bool {
+			if side[i].Price != side[j].Price {
+				return better(side[i].Price, side[j].Price)
+			}
+			if !side[i].CreatedAt.Equal(side[j].CreatedAt) {
+				return side[i].CreatedAt.Before(side[j].CreatedAt)
+			}
+			return side[i].OrderID < side[j].OrderID
+		})
+	}
+	sortSide(bids, func(a, b float64) bool { return a > b })
+	sortSide(asks, func(a, b float64) bool { return a < b })
+
+	if level == BookLevel1 {
+		depth = 1
+	}
+
+	book := &OrderBook{
+		Symbol: symbol,
+		Level:  level,
+		Bids:   bookEntries(bids, level, depth),
+		Asks:   bookEntries(asks, level, depth),
+	}
+	book.SetSpread()
+	return book
+}
+
+func bookEntries(orders []BookOrder, level BookLevel, depth int) []OrderBookEntry {
+	entries := []OrderBookEntry{}
+
+	if level == BookLevel3 {
+		for _, order := range orders {
+			if len(entries) == depth {
+				break
+			}
+			createdAt := order.CreatedAt
+			entries = append(entries, OrderBookEntry{
+				Price:     order.Price,
+				Quantity:  order.Quantity,
+				OrderID:   order.OrderID,
+				CreatedAt: &createdAt,
+			})
+		}
+		return entries
+	}
+
+	for _, order := range orders {
+		if n := len(entries); n > 0 && entries[n-1].Price == order.Price {
+			entries[n-1].Quantity += order.Quantity
+			entries[n-1].Orders++
+			continue
+		}
+		if len(entries) == depth {
+			break
+		}
+		entries = append(entries, OrderBookEntry{Price: order.Price, Quantity: order.Quantity, Orders: 1})
+	}
+	return entries
+}
@@ -11,5 +11,42 @@ type Trade struct {
 	SellOrderID int       `json:"sell_order_id"`
 	Price       float64   `json:"price"`
 	Quantity    float64   `json:"quantity"`
+	Busted      bool      `json:"busted"`              // Voided by an operator; kept for the record
+	Corrected   bool      `json:"corrected,omitempty"` // Price or quantity amended by an operator
 	CreatedAt   time.Time `json:"created_at"`
 }
+
+type TradeCorrectionAction string
+
+const (
+	TradeBust    TradeCorrectionAction = "bust"
+	TradeCorrect TradeCorrectionAction = "correct"
+)
+
+// TradeCorrection records an operator voiding or amending a trade, with the
+// values the trade had before
+type TradeCorrection struct {
+	ID               int                   `json:"id"`
+	TradeID          int                   `json:"trade_id"`
+	Action           TradeCorrectionAction `json:"action"`
+	OriginalPrice    float64               `json:"original_price"`
+	OriginalQuantity float64               `json:"original_quantity"`
+	NewPrice         float64               `json:"new_price"`    // Zero for a bust
+	NewQuantity      float64               `json:"new_quantity"` // Zero for a bust
+	Reason           string                `json:"reason"`
+	Actor            string                `json:"actor"`
+	CreatedAt        time.Time             `json:"created_at"`
+}
+
+// CorrectTradeRequest amends a trade's price and/or quantity. Omitted fields
+// keep their current value.
+type CorrectTradeRequest struct {
+	Price    float64 `json:"price" binding:"omitempty,gt=0"`
+	Quantity float64 `json:"quantity" binding:"omitempty,gt=0"`
+	Reason   string  `json:"reason" binding:"required,max=255"`
+}
+
+// BustTradeRequest voids a trade
+type BustTradeRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"`
+}
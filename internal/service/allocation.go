@@ -0,0 +1,190 @@
+package service
+
+import (
+	"math"
+	"math/big"
+
+	"order-matching-system/internal/models"
+)
+
+// RestingQuantity is one resting order at a price level as seen by an
+// Allocator: its remaining quantity in fixed-point units and its owner
+type RestingQuantity struct {
+	Units     int64
+	AccountID string
+}
+
+// Allocator divides an incoming quantity among the resting orders at a single
+// price level. Resting orders are given in time priority. The result has one
+// entry per resting order, never exceeds that order's quantity, and sums to
+// exactly min(quantity, total resting quantity).
+type Allocator interface {
+	Allocate(quantity int64, resting []RestingQuantity) []int64
+}
+
+// NewAllocator returns the allocation strategy configured for an instrument
+func NewAllocator(instrument *models.Instrument) Allocator {
+	switch instrument.Algorithm {
+	case models.MatchingFIFOLMM:
+		return &FIFOLMMAllocator{
+			LMMAccountID:  instrument.LMMAccountID,
+			LMMPercentage: instrument.LMMPercentage,
+			LotUnits:      lotUnits(instrument),
+		}
+	case models.MatchingProRata:
+		return &ProRataAllocator{
+			LotUnits:         lotUnits(instrument),
+			MinAllocation:    models.ToUnits(instrument.MinAllocation),
+			TopOrderPriority: instrument.TopOrderPriority,
+			LMMAccountID:     instrument.LMMAccountID,
+			LMMPercentage:    instrument.LMMPercentage,
+		}
+	default:
+		return FIFOAllocator{}
+	}
+}
+
+func lotUnits(instrument *models.Instrument) int64 {
+	if lot := models.ToUnits(instrument.LotSize); lot > 0 {
+		return lot
+	}
+	return 1
+}
+
+// FIFOAllocator fills resting orders strictly in time priority
+type FIFOAllocator struct{}
+
+func (FIFOAllocator) Allocate(quantity int64, resting []RestingQuantity) []int64 {
+	allocations := make([]int64, len(resting))
+	fillFIFO(quantity, resting, allocations)
+	return allocations
+}
+
+// FIFOLMMAllocator first gives the lead market maker's orders LMMPercentage of
+// the incoming quantity, rounded down to whole lots, then fills everything
+// that remains in time priority
+type FIFOLMMAllocator struct {
+	LMMAccountID  string
+	LMMPercentage float64
+	LotUnits      int64
+}
+
+func (a *FIFOLMMAllocator) Allocate(quantity int64, resting []RestingQuantity) []int64 {
+	allocations := make([]int64, len(resting))
+	remaining := allocateLMM(quantity, resting, allocations, a.LMMAccountID, a.LMMPercentage, a.LotUnits)
+	fillFIFO(remaining, resting, allocations)
+	return allocations
+}
+
+// ProRataAllocator allocates in proportion to resting size. The steps are:
+//
+//  1. with TopOrderPriority, the oldest order is filled first
+//  2. the lead market maker receives LMMPercentage of what is left
+//  3. the rest is split pro-rata by unfilled size, rounded down to whole lots,
+//     with shares below MinAllocation dropped
+//  4. any quantity left over by rounding is filled in time priority
+type ProRataAllocator struct {
+	LotUnits         int64
+	MinAllocation    int64
+	TopOrderPriority bool
+	LMMAccountID     string
+	LMMPercentage    float64
+}
+
+func (a *ProRataAllocator) Allocate(quantity int64, resting []RestingQuantity) []int64 {
+	allocations := make([]int64, len(resting))
+	remaining := quantity
+
+	if a.TopOrderPriority && len(resting) > 0 {
+		allocations[0] = min(remaining, resting[0].Units)
+		remaining -= allocations[0]
+	}
+
+	remaining = allocateLMM(remaining, resting, allocations, a.LMMAccountID, a.LMMPercentage, a.LotUnits)
+
+	// Pro-rata share of what is left, by each order's unfilled quantity
+	var total int64
+	for i, r := range resting {
+		total += r.Units - allocations[i]
+	}
+	if remaining >= total {
+		for i, r := range resting {
+			allocations[i] = r.Units
+		}
+		return allocations
+	}
+
+	pool := remaining
+	lot := max(a.LotUnits, 1)
+	for i, r := range resting {
+		open := r.Units - allocations[i]
+		if open <= 0 || remaining == 0 {
+			continue
+		}
+
+		share := proportion(pool, open, total)
+		share -= share % lot
+		share = min(share, open, remaining)
+		if share < a.MinAllocation {
+			continue
+		}
+
+		allocations[i] += share
+		remaining -= share
+	}
+
+	fillFIFO(remaining, resting, allocations)
+	return allocations
+}
+
+// allocateLMM gives the lead market maker's orders, in time priority, up to
+// percentage of quantity rounded down to whole lots. It returns the quantity
+// still to be allocated.
+func allocateLMM(quantity int64, resting []RestingQuantity, allocations []int64, accountID string, percentage float64, lot int64) int64 {
+	if accountID == "" || percentage <= 0 || quantity <= 0 {
+		return quantity
+	}
+
+	share := proportion(quantity, int64(math.Round(percentage*100)), 100*100)
+	share -= share % max(lot, 1)
+
+	for i, r := range resting {
+		if share == 0 {
+			break
+		}
+		if r.AccountID != accountID {
+			continue
+		}
+
+		fill := min(share, r.Units-allocations[i])
+		allocations[i] += fill
+		share -= fill
+		quantity -= fill
+	}
+
+	return quantity
+}
+
+// fillFIFO adds quantity to allocations in time priority, up to each order's
+// remaining capacity
+func fillFIFO(quantity int64, resting []RestingQuantity, allocations []int64) {
+	for i, r := range resting {
+		if quantity <= 0 {
+			return
+		}
+
+		fill := min(quantity, r.Units-allocations[i])
+		allocations[i] += fill
+		quantity -= fill
+	}
+}
+
+// proportion returns floor(quantity * part / whole) without overflowing
+func proportion(quantity, part, whole int64) int64 {
+	if whole == 0 {
+		return 0
+	}
+
+	n := new(big.Int).Mul(big.NewInt(quantity), big.NewInt(part))
+	return n.Quo(n, big.NewInt(whole)).Int64()
+}
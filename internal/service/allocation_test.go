@@ -0,0 +1,200 @@
+package service
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func resting(units ...int64) []RestingQuantity {
+	r := make([]RestingQuantity, len(units))
+	for i, u := range units {
+		r[i] = RestingQuantity{Units: u}
+	}
+	return r
+}
+
+func TestFIFOAllocator(t *testing.T) {
+	got := FIFOAllocator{}.Allocate(250, resting(100, 100, 100))
+	want := []int64{100, 100, 50}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestProRataAllocator(t *testing.T) {
+	tests := []struct {
+		name      string
+		allocator *ProRataAllocator
+		quantity  int64
+		resting   []RestingQuantity
+		want      []int64
+	}{
+		{
+			name:      "proportional to size",
+			allocator: &ProRataAllocator{LotUnits: 1},
+			quantity:  100,
+			resting:   resting(100, 300),
+			want:      []int64{25, 75},
+		},
+		{
+			name:      "rounding remainder goes to time priority",
+			allocator: &ProRataAllocator{LotUnits: 1},
+			quantity:  10,
+			resting:   resting(10, 10, 10),
+			want:      []int64{4, 3, 3},
+		},
+		{
+			name:      "whole lots only",
+			allocator: &ProRataAllocator{LotUnits: 10},
+			quantity:  50,
+			resting:   resting(100, 100, 100),
+			want:      []int64{30, 10, 10},
+		},
+		{
+			name:      "shares below minimum are reassigned",
+			allocator: &ProRataAllocator{LotUnits: 1, MinAllocation: 10},
+			quantity:  20,
+			resting:   resting(1000, 10),
+			want:      []int64{20, 0},
+		},
+		{
+			name:      "top order priority",
+			allocator: &ProRataAllocator{LotUnits: 1, TopOrderPriority: true},
+			quantity:  60,
+			resting:   resting(20, 40, 120),
+			want:      []int64{20, 10, 30},
+		},
+		{
+			name:      "quantity exceeds level",
+			allocator: &ProRataAllocator{LotUnits: 1},
+			quantity:  1000,
+			resting:   resting(10, 20),
+			want:      []int64{10, 20},
+		},
+		{
+			name:      "lead market maker share",
+			allocator: &ProRataAllocator{LotUnits: 1, LMMAccountID: "lmm", LMMPercentage: 40},
+			quantity:  100,
+			resting: []RestingQuantity{
+				{Units: 100, AccountID: "a"},
+				{Units: 100, AccountID: "lmm"},
+			},
+			want: []int64{38, 62},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.allocator.Allocate(tt.quantity, tt.resting)
+			if sum(got) != min(tt.quantity, total(tt.resting)) {
+				t.Fatalf("allocations %v sum to %d, want %d", got, sum(got), min(tt.quantity, total(tt.resting)))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFIFOLMMAllocator(t *testing.T) {
+	tests := []struct {
+		name       string
+		percentage float64
+		quantity   int64
+		resting    []RestingQuantity
+		want       []int64
+	}{
+		{
+			name:       "share before time priority",
+			percentage: 25,
+			quantity:   100,
+			resting: []RestingQuantity{
+				{Units: 80, AccountID: "a"},
+				{Units: 50, AccountID: "lmm"},
+				{Units: 80, AccountID: "b"},
+			},
+			want: []int64{75, 25, 0},
+		},
+		{
+			// 0.57 * 100 is just below 57 in floating point
+			name:       "fractional percentage",
+			percentage: 0.57,
+			quantity:   10000,
+			resting: []RestingQuantity{
+				{Units: 20000, AccountID: "a"},
+				{Units: 100, AccountID: "lmm"},
+			},
+			want: []int64{9943, 57},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &FIFOLMMAllocator{LMMAccountID: "lmm", LMMPercentage: tt.percentage, LotUnits: 1}
+			got := a.Allocate(tt.quantity, tt.resting)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestAllocatorsConserveQuantity checks on random levels that every strategy
+// allocates exactly the traded quantity and never overfills an order
+func TestAllocatorsConserveQuantity(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	accounts := []string{"", "a", "b", "lmm"}
+
+	for i := 0; i < 5000; i++ {
+		level := make([]RestingQuantity, 1+rng.Intn(8))
+		for j := range level {
+			level[j] = RestingQuantity{
+				Units:     1 + rng.Int63n(1_000_000_000),
+				AccountID: accounts[rng.Intn(len(accounts))],
+			}
+		}
+		quantity := 1 + rng.Int63n(total(level)*2)
+		lot := []int64{1, 100, 100_000_000}[rng.Intn(3)]
+
+		allocators := []Allocator{
+			FIFOAllocator{},
+			&FIFOLMMAllocator{LMMAccountID: "lmm", LMMPercentage: float64(rng.Intn(101)), LotUnits: lot},
+			&ProRataAllocator{
+				LotUnits:         lot,
+				MinAllocation:    rng.Int63n(1_000_000),
+				TopOrderPriority: rng.Intn(2) == 0,
+				LMMAccountID:     "lmm",
+				LMMPercentage:    float64(rng.Intn(101)),
+			},
+		}
+
+		for _, a := range allocators {
+			got := a.Allocate(quantity, level)
+			if want := min(quantity, total(level)); sum(got) != want {
+				t.Fatalf("%T: allocations %v of %d over %v sum to %d, want %d", a, got, quantity, level, sum(got), want)
+			}
+			for j, units := range got {
+				if units < 0 || units > level[j].Units {
+					t.Fatalf("%T: allocation %d to order %d is outside [0, %d]", a, units, j, level[j].Units)
+				}
+			}
+		}
+	}
+}
+
+func sum(allocations []int64) int64 {
+	var s int64
+	for _, a := range allocations {
+		s += a
+	}
+	return s
+}
+
+func total(resting []RestingQuantity) int64 {
+	var s int64
+	for _, r := range resting {
+		s += r.Units
+	}
+	return s
+}
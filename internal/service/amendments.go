@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/logging"
+	"order-matching-system/internal/models"
+)
+
+var (
+	// ErrOrderNotAmendable is returned when amending an order that does not
+	// exist, is no longer open or is not a limit order
+	ErrOrderNotAmendable = errors.New("order not found or not an open limit order")
+
+	// ErrInvalidAmendment is returned for an amendment that leaves the order
+	// no quantity to trade
+	ErrInvalidAmendment = errors.New("invalid amendment")
+)
+
+// AmendRejectedError is returned when an amended order would fail a
+// pre-trade risk check. The order is left unchanged.
+type AmendRejectedError struct {
+	Code   models.RejectCode
+	Reason string
+}
+
+func (e *AmendRejectedError) Error() string {
+	return "amendment rejected: " + e.Reason
+}
+
+// AmendOrder changes the price or total quantity of a resting limit order on
+// behalf of actor. Reducing the quantity keeps the order's time priority;
+// changing the price or increasing the quantity moves it to the back of the
+// queue at its price. An order amended to a price that crosses the book
+// trades immediately, as a new order would.
+func (me *MatchingEngine) AmendOrder(ctx context.Context, orderID int, req models.AmendOrderRequest, actor string) (_ *models.Order, err error) {
+	if !me.enter() {
+		return nil, ErrEngineStopped
+	}
+	defer me.inFlight.Done()
+
+	ctx = logging.With(ctx, slog.Int("order_id", orderID))
+	ctx, span := tracer.Start(ctx, "MatchingEngine.AmendOrder", trace.WithAttributes(attribute.Int("order.id", orderID)))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	if me.orderTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, me.orderTimeout)
+		defer cancel()
+	}
+
+	if err := me.lockOrderBook(ctx); err != nil {
+		return nil, err
+	}
+	defer me.unlockOrderBook()
+
+	tx, err := me.store.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	otx := newOrderTx(tx, me.costMethod)
+
+	order, err := otx.orders.GetOrderByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, database.ErrOrderNotFound) {
+			return nil, ErrOrderNotAmendable
+		}
+		return nil, err
+	}
+	ctx = logging.With(ctx, slog.String("symbol", order.Symbol), slog.String("account_id", order.AccountID))
+	if order.Status != models.OrderStatusOpen || order.Type != models.OrderTypeLimit {
+		return nil, ErrOrderNotAmendable
+	}
+
+	halt, err := tx.Halts().GetHalt(ctx, order.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if halt != nil {
+		if halt.Reason == "" {
+			return nil, ErrTradingHalted
+		}
+		return nil, fmt.Errorf("%w: %s", ErrTradingHalted, halt.Reason)
+	}
+
+	price, quantity := order.Price, order.InitialQuantity
+	if req.Price > 0 {
+		price = req.Price
+	}
+	if req.Quantity > 0 {
+		quantity = req.Quantity
+	}
+	if price == order.Price && quantity == order.InitialQuantity {
+		return order, nil
+	}
+	if models.ToUnits(quantity) <= models.ToUnits(order.FilledQuantity) {
+		return nil, fmt.Errorf("%w: quantity must be greater than the filled quantity %g", ErrInvalidAmendment, order.FilledQuantity)
+	}
+
+	repriced := price != order.Price
+	resetPriority := repriced || quantity > order.InitialQuantity
+
+	amended := *order
+	amended.Price = price
+	amended.InitialQuantity = quantity
+	amended.RemainingQuantity = models.FromUnits(models.ToUnits(quantity) - models.ToUnits(order.FilledQuantity))
+
+	// Shrinking an order only ever reduces risk
+	if resetPriority {
+		rejection, err := newRiskChecker(me.riskLimits, tx).checkAmendment(ctx, &amended)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run risk checks: %w", err)
+		}
+		if rejection != nil {
+			return nil, &AmendRejectedError{Code: rejection.code, Reason: rejection.reason}
+		}
+	}
+
+	remainingBefore := order.RemainingQuantity
+	order = &amended
+	if err := otx.orders.AmendOrder(ctx, order, resetPriority); err != nil {
+		return nil, err
+	}
+	if err := otx.recorder.orderEvent(ctx, order, amendedEvent(order, remainingBefore, actor)); err != nil {
+		return nil, err
+	}
+
+	var trades []*models.Trade
+	if repriced {
+		result, err := me.match(ctx, otx, order)
+		if err != nil {
+			return nil, err
+		}
+		trades = result.trades
+
+		if err := otx.orders.UpdateOrderExecution(ctx, order); err != nil {
+			return nil, fmt.Errorf("failed to update order status: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	me.feed.publish(order.Symbol, trades)
+	slog.InfoContext(ctx, "order amended", "actor", actor, "price", order.Price, "quantity", order.InitialQuantity,
+		"priority_reset", resetPriority, "status", order.Status, "trades", len(trades))
+
+	return order, nil
+}
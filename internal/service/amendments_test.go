@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"order-matching-system/internal/models"
+)
+
+func amend(t *testing.T, me *MatchingEngine, orderID int, price, quantity float64) *models.Order {
+	t.Helper()
+	order, err := me.AmendOrder(context.Background(), orderID, models.AmendOrderRequest{Price: price, Quantity: quantity}, "trader")
+	if err != nil {
+		t.Fatalf("failed to amend order %d: %v", orderID, err)
+	}
+	return order
+}
+
+func TestAmendPriority(t *testing.T) {
+	tests := []struct {
+		name      string
+		amend     func(t *testing.T, me *MatchingEngine, orderID int)
+		keepFirst bool // The amended order still trades first at its price
+	}{
+		{"size down keeps priority", func(t *testing.T, me *MatchingEngine, id int) {
+			amend(t, me, id, 0, 1)
+		}, true},
+		{"size up loses priority", func(t *testing.T, me *MatchingEngine, id int) {
+			amend(t, me, id, 0, 3)
+		}, false},
+		{"reprice loses priority", func(t *testing.T, me *MatchingEngine, id int) {
+			amend(t, me, id, 100.5, 0)
+			amend(t, me, id, 100, 0)
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			me, store := newTestEngine(t)
+			first := placeOrder(t, me, "acct-a", models.OrderSideSell, 100, 2)
+			second := placeOrder(t, me, "acct-b", models.OrderSideSell, 100, 2)
+			tt.amend(t, me, first.ID)
+
+			ahead, _, ok := store.QueuePosition(first.ID)
+			if !ok {
+				t.Fatal("amended order is not resting")
+			}
+			if tt.keepFirst != (ahead == 0) {
+				t.Fatalf("%v ahead of the amended order, want first in queue = %v", ahead, tt.keepFirst)
+			}
+
+			placeOrder(t, me, "acct-c", models.OrderSideBuy, 100, 1)
+			amendedFilled, otherFilled := store.Order(first.ID).FilledQuantity, store.Order(second.ID).FilledQuantity
+			if (amendedFilled == 1) != tt.keepFirst || (otherFilled == 1) == tt.keepFirst {
+				t.Fatalf("amended order filled %v, other %v; want amended first = %v", amendedFilled, otherFilled, tt.keepFirst)
+			}
+		})
+	}
+}
+
+func TestAmendCrossingPriceMatches(t *testing.T) {
+	me, store := newTestEngine(t)
+	ask := placeOrder(t, me, "acct-a", models.OrderSideSell, 101, 1)
+	bid := placeOrder(t, me, "acct-b", models.OrderSideBuy, 99, 3)
+
+	amended := amend(t, me, bid.ID, 102, 0)
+	if amended.FilledQuantity != 1 || amended.RemainingQuantity != 2 || amended.Status != models.OrderStatusOpen {
+		t.Fatalf("amended bid %+v, want 1 filled and 2 resting", amended)
+	}
+	if got := store.Order(ask.ID); got.Status != models.OrderStatusFilled {
+		t.Fatalf("ask is %s, want filled", got.Status)
+	}
+	trades := store.TradesSince(0)
+	if len(trades) != 1 || trades[0].Price != 101 || trades[0].BuyOrderID != bid.ID {
+		t.Fatalf("trades %+v, want one at the resting ask's price", trades)
+	}
+	if book := store.Book("TEST", models.BookLevel2, 1); len(book.Bids) != 1 || book.Bids[0].Price != 102 || len(book.Asks) != 0 {
+		t.Fatalf("book %+v, want the amended bid alone", book)
+	}
+}
+
+func TestAmendRefused(t *testing.T) {
+	me, _ := newTestEngine(t)
+	ctx := context.Background()
+	order := placeOrder(t, me, "acct-a", models.OrderSideBuy, 100, 2)
+	placeOrder(t, me, "acct-b", models.OrderSideSell, 100, 1)
+
+	// Its filled quantity leaves nothing to trade
+	if _, err := me.AmendOrder(ctx, order.ID, models.AmendOrderRequest{Quantity: 1}, "trader"); !errors.Is(err, ErrInvalidAmendment) {
+		t.Fatalf("got %v, want ErrInvalidAmendment", err)
+	}
+	market := placeOrder(t, me, "acct-b", models.OrderSideSell, 0, 1)
+	if _, err := me.AmendOrder(ctx, market.ID, models.AmendOrderRequest{Quantity: 2}, "trader"); !errors.Is(err, ErrOrderNotAmendable) {
+		t.Fatalf("amending a market order got %v, want ErrOrderNotAmendable", err)
+	}
+	if _, err := me.AmendOrder(ctx, 99, models.AmendOrderRequest{Quantity: 2}, "trader"); !errors.Is(err, ErrOrderNotAmendable) {
+		t.Fatalf("amending an unknown order got %v, want ErrOrderNotAmendable", err)
+	}
+}
+
+func TestAmendPositionLimitCountsUnfilledQuantity(t *testing.T) {
+	me, _ := newTestEngine(t)
+	me.SetRiskLimits(RiskLimits{MaxPosition: 5})
+	ctx := context.Background()
+
+	order := placeOrder(t, me, "acct-a", models.OrderSideBuy, 100, 5)
+	placeOrder(t, me, "acct-b", models.OrderSideSell, 100, 4) // acct-a is long 4 with 1 left to buy
+
+	// Repricing keeps the exposure at 5; the filled 4 are not counted twice
+	amended := amend(t, me, order.ID, 101, 5)
+	if amended.RemainingQuantity != 1 || amended.Price != 101 {
+		t.Fatalf("amended to %v remaining @ %v, want 1 @ 101", amended.RemainingQuantity, amended.Price)
+	}
+
+	_, err := me.AmendOrder(ctx, order.ID, models.AmendOrderRequest{Quantity: 6}, "trader")
+	var rejected *AmendRejectedError
+	if !errors.As(err, &rejected) || rejected.Code != models.RejectPositionLimit {
+		t.Fatalf("growing past the limit got %v, want a position limit rejection", err)
+	}
+}
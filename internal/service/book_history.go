@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/models"
+)
+
+// ReconstructBook rebuilds the resting book for symbol as it stood at the
+// given time, starting from the nearest earlier snapshot and replaying order
+// events forward. Without a snapshot the replay starts from the first event.
+func ReconstructBook(ctx context.Context, db database.DBTX, symbol string, at time.Time, level models.BookLevel, depth int) (*models.OrderBook, error) {
+	snapshot, err := database.NewSnapshotRepository(db).GetLatestSnapshot(ctx, symbol, at)
+	if err != nil {
+		return nil, err
+	}
+
+	resting := make(map[int]models.BookOrder)
+	var afterID int64
+	if snapshot != nil {
+		for _, order := range snapshot.Orders {
+			resting[order.OrderID] = order
+		}
+		afterID = snapshot.LastEventID
+	}
+
+	events, err := database.NewOrderEventRepository(db).GetBookEvents(ctx, symbol, afterID, at)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, event := range events {
+		// Market orders never rest, so they never appear in the book
+		if event.Type != models.OrderTypeLimit {
+			continue
+		}
+		if event.Event.StatusAfter != models.OrderStatusOpen {
+			delete(resting, event.Order.OrderID)
+			continue
+		}
+
+		// Only an amendment changes a resting order's price or time priority
+		order := event.Order
+		if prev, ok := resting[order.OrderID]; ok {
+			order.CreatedAt = prev.CreatedAt
+			if event.Event.Type != models.OrderEventAmended {
+				order.Price = prev.Price
+			} else if order.Price != prev.Price || order.Quantity > prev.Quantity {
+				order.CreatedAt = event.Event.CreatedAt
+			}
+		}
+		resting[order.OrderID] = order
+	}
+
+	orders := make([]models.BookOrder, 0, len(resting))
+	for _, order := range resting {
+		orders = append(orders, order)
+	}
+
+	return models.BuildOrderBook(symbol, level, depth, orders), nil
+}
+
+// Snapshotter periodically persists a copy of every symbol's resting book so
+// that ReconstructBook has a nearby starting point
+type Snapshotter struct {
+	db        *sql.DB
+	interval  time.Duration
+	retention time.Duration // Zero keeps every snapshot
+	done      chan struct{}
+}
+
+func NewSnapshotter(db *sql.DB, interval time.Duration) *Snapshotter {
+	return &Snapshotter{
+		db:       db,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+}
+
+// SetRetention makes Run delete snapshots older than d, except the latest of
+// each symbol. Zero keeps every snapshot.
+func (s *Snapshotter) SetRetention(d time.Duration) {
+	s.retention = d
+}
+
+// Run takes snapshots every interval until ctx is canceled
+func (s *Snapshotter) Run(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.SnapshotAll(ctx); err != nil && ctx.Err() == nil {
+				slog.ErrorContext(ctx, "failed to snapshot books", "component", "snapshotter", "error", err)
+			}
+			if s.retention > 0 {
+				s.prune(ctx)
+			}
+		}
+	}
+}
+
+// Done is closed once Run has returned
+func (s *Snapshotter) Done() <-chan struct{} {
+	return s.done
+}
+
+// SnapshotAll takes a snapshot of every symbol whose book may have changed
+// since its last snapshot. Symbols without new order events are skipped.
+func (s *Snapshotter) SnapshotAll(ctx context.Context) error {
+	symbols, err := database.NewSnapshotRepository(s.db).GetChangedSymbols(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, symbol := range symbols {
+		if err := s.Snapshot(ctx, symbol); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prune deletes the snapshots that have passed the retention period
+func (s *Snapshotter) prune(ctx context.Context) {
+	deleted, err := database.NewSnapshotRepository(s.db).DeleteSnapshotsBefore(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		if ctx.Err() == nil {
+			slog.ErrorContext(ctx, "failed to delete old snapshots", "component", "snapshotter", "error", err)
+		}
+		return
+	}
+	if deleted > 0 {
+		slog.DebugContext(ctx, "deleted old snapshots", "component", "snapshotter", "count", deleted)
+	}
+}
+
+// Snapshot records the resting book for symbol. The orders and the event
+// high-water mark are read in one consistent-snapshot transaction so that
+// replay resumes exactly where the snapshot left off.
+func (s *Snapshotter) Snapshot(ctx context.Context, symbol string) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	snapshotRepo := database.NewSnapshotRepository(tx)
+
+	lastEventID, err := database.NewOrderEventRepository(tx).GetLatestEventID(ctx)
+	if err != nil {
+		return err
+	}
+	orders, err := snapshotRepo.GetRestingOrders(ctx, symbol)
+	if err != nil {
+		return err
+	}
+
+	snapshot := &models.BookSnapshot{
+		Symbol:      symbol,
+		TakenAt:     time.Now(),
+		LastEventID: lastEventID,
+		Orders:      orders,
+	}
+	if err := snapshotRepo.CreateSnapshot(ctx, snapshot); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
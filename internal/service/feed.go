@@ -0,0 +1,124 @@
+package service
+
+import (
+	"sync"
+
+	"order-matching-system/internal/models"
+)
+
+// tradeBuffer is how many trades a subscriber may fall behind before it is
+// disconnected
+const tradeBuffer = 1024
+
+// MarketFeed fans out committed trades and book changes to in-process
+// subscribers such as streaming RPCs. It only carries changes made through
+// this engine instance after a subscriber joined.
+type MarketFeed struct {
+	mu     sync.Mutex
+	trades map[chan *models.Trade]string // Subscriber to symbol, empty for all
+	books  map[chan struct{}]string
+	closed bool
+}
+
+func newMarketFeed() *MarketFeed {
+	return &MarketFeed{
+		trades: make(map[chan *models.Trade]string),
+		books:  make(map[chan struct{}]string),
+	}
+}
+
+// SubscribeTrades returns a channel receiving every trade committed in symbol,
+// or in any symbol if symbol is empty, and a function to unsubscribe. The
+// channel is closed if the subscriber falls too far behind or the feed closes.
+func (f *MarketFeed) SubscribeTrades(symbol string) (<-chan *models.Trade, func()) {
+	ch := make(chan *models.Trade, tradeBuffer)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	f.trades[ch] = symbol
+
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.trades[ch]; ok {
+			delete(f.trades, ch)
+			close(ch)
+		}
+	}
+}
+
+// SubscribeBook returns a channel signalled after each committed change to
+// symbol's book and a function to unsubscribe. Signals are coalesced, so a
+// slow reader sees one signal for several changes. The channel is closed when
+// the feed closes.
+func (f *MarketFeed) SubscribeBook(symbol string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	f.books[ch] = symbol
+
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		if _, ok := f.books[ch]; ok {
+			delete(f.books, ch)
+			close(ch)
+		}
+	}
+}
+
+// Close disconnects every subscriber
+func (f *MarketFeed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for ch := range f.trades {
+		delete(f.trades, ch)
+		close(ch)
+	}
+	for ch := range f.books {
+		delete(f.books, ch)
+		close(ch)
+	}
+}
+
+// publish announces a committed change to symbol's book and its trades
+func (f *MarketFeed) publish(symbol string, trades []*models.Trade) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, trade := range trades {
+		for ch, want := range f.trades {
+			if want != "" && want != trade.Symbol {
+				continue
+			}
+			select {
+			case ch <- trade:
+			default:
+				// Too slow; disconnect rather than silently skip trades
+				delete(f.trades, ch)
+				close(ch)
+			}
+		}
+	}
+
+	for ch, want := range f.books {
+		if want != symbol {
+			continue
+		}
+		select {
+		case ch <- struct{}{}:
+		default: // A signal is already pending
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"order-matching-system/internal/logging"
+	"order-matching-system/internal/models"
+)
+
+// ErrNotHalted is returned when resuming a symbol that is not halted
+var ErrNotHalted = errors.New("symbol is not halted")
+
+// HaltTrading stops symbol accepting new orders and amendments until it is
+// resumed. Resting orders stay in the book and may still be canceled. The
+// halt takes the book lock, so no order for symbol matches after it returns.
+func (me *MatchingEngine) HaltTrading(ctx context.Context, symbol, reason, actor string) (*models.TradingHalt, error) {
+	if !me.enter() {
+		return nil, ErrEngineStopped
+	}
+	defer me.inFlight.Done()
+
+	if err := me.lockOrderBook(ctx); err != nil {
+		return nil, err
+	}
+	defer me.unlockOrderBook()
+
+	tx, err := me.store.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	haltRepo := tx.Halts()
+	if err := haltRepo.SaveHalt(ctx, &models.TradingHalt{Symbol: symbol, Reason: reason, HaltedBy: actor}); err != nil {
+		return nil, err
+	}
+	halt, err := haltRepo.GetHalt(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "trading halted", "symbol", symbol, "reason", reason, "actor", actor)
+
+	return halt, nil
+}
+
+// ResumeTrading lifts the halt on symbol
+func (me *MatchingEngine) ResumeTrading(ctx context.Context, symbol string) error {
+	if !me.enter() {
+		return ErrEngineStopped
+	}
+	defer me.inFlight.Done()
+
+	if err := me.lockOrderBook(ctx); err != nil {
+		return err
+	}
+	defer me.unlockOrderBook()
+
+	tx, err := me.store.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	resumed, err := tx.Halts().DeleteHalt(ctx, symbol)
+	if err != nil {
+		return err
+	}
+	if !resumed {
+		return ErrNotHalted
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "trading resumed", "symbol", symbol)
+
+	return nil
+}
+
+// MassCancel cancels every open order matching filter on behalf of actor, in
+// one transaction
+func (me *MatchingEngine) MassCancel(ctx context.Context, filter models.OrderFilter, actor string) (*models.MassCancelResult, error) {
+	if !me.enter() {
+		return nil, ErrEngineStopped
+	}
+	defer me.inFlight.Done()
+
+	if err := me.lockOrderBook(ctx); err != nil {
+		return nil, err
+	}
+	defer me.unlockOrderBook()
+
+	tx, err := me.store.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	orderRepo := tx.Orders()
+	recorder := newEventRecorder(tx)
+
+	filter.Status = models.OrderStatusOpen
+	filter.Limit = 0
+	orders, err := orderRepo.ListOrders(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.MassCancelResult{OrderIDs: []int{}}
+	symbols := make(map[string]bool)
+	for _, order := range orders {
+		orderCtx := logging.With(ctx, slog.Int("order_id", order.ID), slog.String("symbol", order.Symbol), slog.String("account_id", order.AccountID))
+		if err := orderRepo.CancelOrder(orderCtx, order.ID); err != nil {
+			return nil, err
+		}
+
+		order.Status = models.OrderStatusCanceled
+		if err := recorder.orderEvent(orderCtx, order, cancelEvent(order, order.RemainingQuantity, "mass cancel", actor)); err != nil {
+			return nil, err
+		}
+
+		result.OrderIDs = append(result.OrderIDs, order.ID)
+		symbols[order.Symbol] = true
+	}
+	result.Canceled = len(result.OrderIDs)
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	for symbol := range symbols {
+		me.feed.publish(symbol, nil)
+	}
+	slog.InfoContext(ctx, "mass cancel", "actor", actor, "canceled", result.Canceled, "order_ids", result.OrderIDs)
+
+	return result, nil
+}
+
+// haltMessage describes a halt for rejected orders and amendments
+func haltMessage(halt *models.TradingHalt) string {
+	if halt.Reason == "" {
+		return fmt.Sprintf("trading in %s is halted", halt.Symbol)
+	}
+	return fmt.Sprintf("trading in %s is halted: %s", halt.Symbol, halt.Reason)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"order-matching-system/internal/models"
+)
+
+func TestHaltRejectsOrdersAndAmendments(t *testing.T) {
+	me, store := newTestEngine(t)
+	ctx := context.Background()
+	resting := placeOrder(t, me, "acct-a", models.OrderSideBuy, 99, 2)
+	canceled := placeOrder(t, me, "acct-a", models.OrderSideBuy, 98, 2)
+
+	halt, err := me.HaltTrading(ctx, "TEST", "pending news", "ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if halt.Symbol != "TEST" || halt.HaltedBy != "ops" {
+		t.Fatalf("unexpected halt %+v", halt)
+	}
+
+	rejected := placeOrder(t, me, "acct-b", models.OrderSideSell, 99, 1)
+	if rejected.Status != models.OrderStatusRejected || rejected.RejectCode != models.RejectTradingHalted {
+		t.Fatalf("order during halt is %s (%s), want rejected as trading_halted", rejected.Status, rejected.RejectCode)
+	}
+	if len(store.TradesSince(0)) != 0 {
+		t.Fatal("an order traded during the halt")
+	}
+	if _, err := me.AmendOrder(ctx, resting.ID, models.AmendOrderRequest{Price: 100}, "trader"); !errors.Is(err, ErrTradingHalted) {
+		t.Fatalf("amendment during halt got %v, want ErrTradingHalted", err)
+	}
+	// Resting orders can still be canceled
+	if _, err := me.CancelOrder(ctx, canceled.ID, "trader"); err != nil {
+		t.Fatalf("cancel during halt failed: %v", err)
+	}
+
+	if err := me.ResumeTrading(ctx, "TEST"); err != nil {
+		t.Fatal(err)
+	}
+	if err := me.ResumeTrading(ctx, "TEST"); !errors.Is(err, ErrNotHalted) {
+		t.Fatalf("second resume got %v, want ErrNotHalted", err)
+	}
+	if order := placeOrder(t, me, "acct-b", models.OrderSideSell, 99, 1); order.Status != models.OrderStatusFilled {
+		t.Fatalf("order after resume is %s, want filled", order.Status)
+	}
+}
+
+func TestMassCancelFilters(t *testing.T) {
+	me, store := newTestEngine(t)
+	place := func(account, symbol string, side models.OrderSide, price float64) int {
+		order := (&models.PlaceOrderRequest{AccountID: account, Symbol: symbol, Side: side, Type: models.OrderTypeLimit, Price: price, Quantity: 1}).NewOrder()
+		if err := me.ProcessOrder(context.Background(), order); err != nil {
+			t.Fatal(err)
+		}
+		return order.ID
+	}
+	aBuy := place("acct-a", "TEST", models.OrderSideBuy, 99)
+	aSell := place("acct-a", "TEST", models.OrderSideSell, 101)
+	aOther := place("acct-a", "OTHER", models.OrderSideBuy, 50)
+	bBuy := place("acct-b", "TEST", models.OrderSideBuy, 98)
+	bOther := place("acct-b", "OTHER", models.OrderSideSell, 51)
+
+	tests := []struct {
+		name   string
+		filter models.OrderFilter
+		want   []int
+	}{
+		{"account and side", models.OrderFilter{AccountID: "acct-a", Side: models.OrderSideBuy}, []int{aBuy, aOther}},
+		{"symbol", models.OrderFilter{Symbol: "TEST"}, []int{aSell, bBuy}},
+		{"account and symbol", models.OrderFilter{AccountID: "acct-b", Symbol: "OTHER"}, []int{bOther}},
+		{"nothing left", models.OrderFilter{Symbol: "TEST"}, []int{}},
+	}
+	for _, tt := range tests {
+		result, err := me.MassCancel(context.Background(), tt.filter, "ops")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		sort.Ints(result.OrderIDs)
+		if result.Canceled != len(tt.want) || !reflect.DeepEqual(result.OrderIDs, tt.want) {
+			t.Fatalf("%s: canceled %v, want %v", tt.name, result.OrderIDs, tt.want)
+		}
+		for _, id := range tt.want {
+			events := store.Events(id)
+			if last := events[len(events)-1]; last.Type != models.OrderEventCanceled || last.Cause != "mass cancel" || last.Actor != "ops" {
+				t.Fatalf("%s: last event of order %d is %+v", tt.name, id, last)
+			}
+		}
+	}
+}
+
+func TestShutdownRefusesChanges(t *testing.T) {
+	me, _ := newTestEngine(t)
+	ctx := context.Background()
+	order := placeOrder(t, me, "acct-a", models.OrderSideBuy, 100, 2)
+	placeOrder(t, me, "acct-b", models.OrderSideSell, 100, 1)
+
+	if err := me.Shutdown(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	calls := map[string]func() error{
+		"cancel": func() error { _, err := me.CancelOrder(ctx, order.ID, "trader"); return err },
+		"amend": func() error {
+			_, err := me.AmendOrder(ctx, order.ID, models.AmendOrderRequest{Quantity: 3}, "trader")
+			return err
+		},
+		"mass cancel": func() error {
+			_, err := me.MassCancel(ctx, models.OrderFilter{Symbol: "TEST"}, "ops")
+			return err
+		},
+		"halt":    func() error { _, err := me.HaltTrading(ctx, "TEST", "", "ops"); return err },
+		"resume":  func() error { return me.ResumeTrading(ctx, "TEST") },
+		"bust":    func() error { _, _, err := me.BustTrade(ctx, 1, "error", "ops"); return err },
+		"correct": func() error { _, _, err := me.CorrectTrade(ctx, 1, 101, 0, "error", "ops"); return err },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrEngineStopped) {
+			t.Errorf("%s after shutdown got %v, want ErrEngineStopped", name, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"order-matching-system/internal/metrics"
+	"order-matching-system/internal/models"
+)
+
+var tracer = otel.Tracer("order-matching-system/internal/service")
+
+// observeProcessOrder records latency by phase and the outcome of one
+// ProcessOrder call. Trades are only counted once they have been committed.
+func observeProcessOrder(order *models.Order, arrived, locked time.Time, dbTime time.Duration, trades []*models.Trade, err error) {
+	now := time.Now()
+	matching := now.Sub(locked) - dbTime
+	if matching < 0 {
+		matching = 0
+	}
+
+	metrics.ProcessOrderDuration.WithLabelValues(metrics.PhaseLockWait).Observe(locked.Sub(arrived).Seconds())
+	metrics.ProcessOrderDuration.WithLabelValues(metrics.PhaseDB).Observe(dbTime.Seconds())
+	metrics.ProcessOrderDuration.WithLabelValues(metrics.PhaseMatching).Observe(matching.Seconds())
+	metrics.ProcessOrderDuration.WithLabelValues(metrics.PhaseTotal).Observe(now.Sub(arrived).Seconds())
+
+	outcome := "error"
+	if errors.Is(err, ErrDuplicateOrder) {
+		outcome = "duplicate"
+	} else if err == nil {
+		outcome = string(order.Status)
+		for _, trade := range trades {
+			metrics.TradesTotal.WithLabelValues(trade.Symbol).Inc()
+			metrics.TradedVolume.WithLabelValues(trade.Symbol).Add(trade.Quantity)
+		}
+	}
+	metrics.OrdersTotal.WithLabelValues(string(order.Type), string(order.Side), outcome).Inc()
+}
+
+// logProcessOrder logs the outcome of one ProcessOrder call. ctx carries the
+// order's symbol and account, and its ID once the order has been stored.
+func logProcessOrder(ctx context.Context, order *models.Order, trades []*models.Trade, err error) {
+	switch {
+	case errors.Is(err, ErrDuplicateOrder):
+		slog.InfoContext(ctx, "duplicate order", "order_id", order.ID, "idempotency_key", order.IdempotencyKey)
+	case errors.Is(err, ErrIdempotencyKeyReused), errors.Is(err, ErrEngineStopped):
+		slog.WarnContext(ctx, "order refused", "type", order.Type, "side", order.Side, "error", err)
+	case err != nil:
+		slog.ErrorContext(ctx, "order failed", "type", order.Type, "side", order.Side, "error", err)
+	case order.Status == models.OrderStatusRejected:
+		slog.WarnContext(ctx, "order rejected", "type", order.Type, "side", order.Side,
+			"reject_code", order.RejectCode, "reason", order.StatusReason)
+	default:
+		slog.InfoContext(ctx, "order processed", "type", order.Type, "side", order.Side, "price", order.Price,
+			"quantity", order.InitialQuantity, "status", order.Status, "filled", order.FilledQuantity, "trades", len(trades))
+	}
+}
+
+// orderAttributes describes an incoming order on its span
+func orderAttributes(order *models.Order) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("order.symbol", order.Symbol),
+		attribute.String("order.account_id", order.AccountID),
+		attribute.String("order.side", string(order.Side)),
+		attribute.String("order.type", string(order.Type)),
+		attribute.Float64("order.quantity", order.InitialQuantity),
+	}
+}
+
+// endProcessOrderSpan records the outcome of one ProcessOrder call on its
+// span and ends it
+func endProcessOrderSpan(span trace.Span, order *models.Order, trades []*models.Trade, err error) {
+	span.SetAttributes(
+		attribute.Int("order.id", order.ID),
+		attribute.String("order.status", string(order.Status)),
+		attribute.Float64("order.filled", order.FilledQuantity),
+		attribute.Int("order.trades", len(trades)),
+	)
+	if order.RejectCode != "" {
+		span.SetAttributes(attribute.String("order.reject_code", string(order.RejectCode)))
+	}
+	if errors.Is(err, ErrDuplicateOrder) {
+		err = nil
+	}
+	endSpan(span, err)
+}
+
+// endSpan marks span failed if err is set and ends it
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+)
+
+// diffOp is one step of a differential run: an order, or a cancel of the
+// order with ID Target
+type diffOp struct {
+	Cancel   bool
+	Side     models.OrderSide
+	Type     models.OrderType
+	Price    float64
+	Quantity float64
+	Target   int
+}
+
+func (op diffOp) String() string {
+	if op.Cancel {
+		return fmt.Sprintf("{Cancel: true, Target: %d}", op.Target)
+	}
+	return fmt.Sprintf("{Side: %q, Type: %q, Price: %v, Quantity: %v}", op.Side, op.Type, op.Price, op.Quantity)
+}
+
+// refOrder is an order as the reference matcher tracks it, in fixed-point
+// units
+type refOrder struct {
+	id        int
+	side      models.OrderSide
+	typ       models.OrderType
+	price     float64
+	initial   int64
+	remaining int64
+	filled    int64
+	notional  float64 // Sum of price times quantity over fills
+	status    models.OrderStatus
+}
+
+type refTrade struct {
+	price    float64
+	quantity int64
+	buyID    int
+	sellID   int
+}
+
+func (t refTrade) String() string {
+	return fmt.Sprintf("%v @ %v (buy %d, sell %d)", models.FromUnits(t.quantity), t.price, t.buyID, t.sellID)
+}
+
+// referenceMatcher is a deliberately simple FIFO matcher. Each step of an
+// incoming order scans every order for the best crossing one, lowest ID
+// first among equal prices, and trades as much as both have.
+type referenceMatcher struct {
+	orders []*refOrder // Indexed by ID - 1
+}
+
+func (m *referenceMatcher) place(op diffOp) []refTrade {
+	o := &refOrder{
+		id:        len(m.orders) + 1,
+		side:      op.Side,
+		typ:       op.Type,
+		price:     op.Price,
+		initial:   models.ToUnits(op.Quantity),
+		remaining: models.ToUnits(op.Quantity),
+		status:    models.OrderStatusOpen,
+	}
+	m.orders = append(m.orders, o)
+
+	var trades []refTrade
+	for o.remaining > 0 {
+		best := m.bestMatch(o)
+		if best == nil {
+			break
+		}
+		quantity := min(o.remaining, best.remaining)
+		for _, side := range []*refOrder{o, best} {
+			side.remaining -= quantity
+			side.filled += quantity
+			side.notional += best.price * models.FromUnits(quantity)
+			if side.remaining == 0 {
+				side.status = models.OrderStatusFilled
+			}
+		}
+
+		trade := refTrade{price: best.price, quantity: quantity, buyID: o.id, sellID: best.id}
+		if o.side == models.OrderSideSell {
+			trade.buyID, trade.sellID = best.id, o.id
+		}
+		trades = append(trades, trade)
+	}
+
+	// Market orders never rest
+	if o.typ == models.OrderTypeMarket && o.remaining > 0 {
+		o.remaining = 0
+		o.status = models.OrderStatusCanceled
+	}
+	return trades
+}
+
+// bestMatch returns the resting order incoming trades with next, or nil
+func (m *referenceMatcher) bestMatch(incoming *refOrder) *refOrder {
+	var best *refOrder
+	for _, o := range m.orders {
+		if o.status != models.OrderStatusOpen || o.side == incoming.side || o == incoming {
+			continue
+		}
+		if incoming.typ == models.OrderTypeLimit {
+			if incoming.side == models.OrderSideBuy && o.price > incoming.price {
+				continue
+			}
+			if incoming.side == models.OrderSideSell && o.price < incoming.price {
+				continue
+			}
+		}
+		// Orders are scanned in ID order, so a tie keeps the earlier one
+		if best == nil || better(o.side, o.price, best.price) {
+			best = o
+		}
+	}
+	return best
+}
+
+func (m *referenceMatcher) cancel(id int) bool {
+	if id < 1 || id > len(m.orders) || m.orders[id-1].status != models.OrderStatusOpen {
+		return false
+	}
+	m.orders[id-1].status = models.OrderStatusCanceled
+	return true
+}
+
+// runDifferential feeds ops to a FIFO MatchingEngine on an in-memory store
+// and to the reference matcher, and describes the first difference in their
+// trades or order states. It returns "" if they agree throughout.
+func runDifferential(ops []diffOp) string {
+	now := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+	store := memory.NewStore(func() time.Time { return now })
+	engine := NewMatchingEngineWithStore(store)
+	ref := &referenceMatcher{}
+	ctx := context.Background()
+	tradeID := 0
+
+	for i, op := range ops {
+		now = now.Add(time.Millisecond)
+
+		if op.Cancel {
+			_, err := engine.CancelOrder(ctx, op.Target, "test")
+			canceled := err == nil
+			if err != nil && !errors.Is(err, ErrOrderNotCancelable) {
+				return fmt.Sprintf("op %d: cancel failed: %v", i, err)
+			}
+			if want := ref.cancel(op.Target); canceled != want {
+				return fmt.Sprintf("op %d: cancel of order %d succeeded = %v, reference %v", i, op.Target, canceled, want)
+			}
+		} else {
+			order := (&models.PlaceOrderRequest{
+				Symbol: propertySymbol, Side: op.Side, Type: op.Type, Price: op.Price, Quantity: op.Quantity,
+			}).NewOrder()
+			if err := engine.ProcessOrder(ctx, order); err != nil {
+				return fmt.Sprintf("op %d: ProcessOrder failed: %v", i, err)
+			}
+			want := ref.place(op)
+			if order.ID != len(ref.orders) {
+				return fmt.Sprintf("op %d: order got ID %d, reference %d", i, order.ID, len(ref.orders))
+			}
+
+			var got []refTrade
+			for _, trade := range store.TradesSince(tradeID) {
+				tradeID = trade.ID
+				got = append(got, refTrade{
+					price: trade.Price, quantity: models.ToUnits(trade.Quantity), buyID: trade.BuyOrderID, sellID: trade.SellOrderID,
+				})
+			}
+			if diff := diffTrades(got, want); diff != "" {
+				return fmt.Sprintf("op %d (%v): %s", i, op, diff)
+			}
+		}
+
+		for _, o := range ref.orders {
+			if diff := diffOrder(store.Order(o.id), o); diff != "" {
+				return fmt.Sprintf("op %d (%v): order %d: %s", i, op, o.id, diff)
+			}
+		}
+	}
+	return ""
+}
+
+func diffTrades(got, want []refTrade) string {
+	for i := 0; i < max(len(got), len(want)); i++ {
+		switch {
+		case i >= len(got):
+			return fmt.Sprintf("missing trade %d: want %v", i, want[i])
+		case i >= len(want):
+			return fmt.Sprintf("extra trade %d: %v", i, got[i])
+		case got[i] != want[i]:
+			return fmt.Sprintf("trade %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+	return ""
+}
+
+func diffOrder(got *models.Order, want *refOrder) string {
+	if got == nil {
+		return "missing from the store"
+	}
+	if got.Status != want.status {
+		return fmt.Sprintf("status %s, want %s", got.Status, want.status)
+	}
+	if units := models.ToUnits(got.FilledQuantity); units != want.filled {
+		return fmt.Sprintf("filled %v, want %v", got.FilledQuantity, models.FromUnits(want.filled))
+	}
+	if units := models.ToUnits(got.RemainingQuantity); units != want.remaining {
+		return fmt.Sprintf("remaining %v, want %v", got.RemainingQuantity, models.FromUnits(want.remaining))
+	}
+	if want.filled > 0 {
+		average := want.notional / models.FromUnits(want.filled)
+		if math.Abs(got.AverageFillPrice-average) > 1e-9*average {
+			return fmt.Sprintf("average fill price %v, want %v", got.AverageFillPrice, average)
+		}
+	}
+	return ""
+}
+
+// shrinkOps reduces ops to a smaller sequence that still fails: it drops
+// ever smaller chunks of operations while the failure remains, then tries
+// simpler quantities and prices for the orders that are left
+func shrinkOps(ops []diffOp, fails func([]diffOp) bool) []diffOp {
+	for chunk := len(ops) / 2; chunk >= 1; {
+		removed := false
+		for start := 0; start+chunk <= len(ops); {
+			candidate := append(append([]diffOp(nil), ops[:start]...), ops[start+chunk:]...)
+			if fails(candidate) {
+				ops, removed = candidate, true
+				continue
+			}
+			start += chunk
+		}
+		if !removed {
+			chunk /= 2
+		}
+	}
+
+	for i := range ops {
+		for _, simplify := range simplifications {
+			candidate := append([]diffOp(nil), ops...)
+			if !simplify(&candidate[i]) {
+				continue
+			}
+			if fails(candidate) {
+				ops = candidate
+			}
+		}
+	}
+	return ops
+}
+
+// simplifications make an order smaller or closer to the middle of the book
+// while shrinking, reporting whether they changed it
+var simplifications = []func(op *diffOp) bool{
+	func(op *diffOp) bool {
+		if op.Cancel || op.Quantity == 1 {
+			return false
+		}
+		op.Quantity = 1
+		return true
+	},
+	func(op *diffOp) bool {
+		if op.Cancel || op.Type != models.OrderTypeLimit || op.Price == 100 {
+			return false
+		}
+		op.Price = 100
+		return true
+	},
+}
+
+// randomOps generates n operations over a handful of price levels, with
+// enough cancels and market orders to exercise every path
+func randomOps(rng *rand.Rand, n int) []diffOp {
+	ops := make([]diffOp, n)
+	placed := 0
+	for i := range ops {
+		if placed > 0 && rng.Intn(6) == 0 {
+			ops[i] = diffOp{Cancel: true, Target: rng.Intn(placed) + 1}
+			continue
+		}
+		op := diffOp{Side: models.OrderSideBuy, Type: models.OrderTypeLimit, Price: 100 + float64(rng.Intn(9)-4)*0.25}
+		if rng.Intn(2) == 0 {
+			op.Side = models.OrderSideSell
+		}
+		if rng.Intn(8) == 0 {
+			op.Type, op.Price = models.OrderTypeMarket, 0
+		}
+		// Mostly whole quantities, some fractional
+		op.Quantity = float64(rng.Intn(10) + 1)
+		if rng.Intn(4) == 0 {
+			op.Quantity = float64(rng.Intn(1000)+1) / 100
+		}
+		ops[i] = op
+		placed++
+	}
+	return ops
+}
+
+func formatOps(ops []diffOp) string {
+	var b strings.Builder
+	b.WriteString("[]diffOp{\n")
+	for _, op := range ops {
+		fmt.Fprintf(&b, "\t%v,\n", op)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func TestMatchingMatchesReference(t *testing.T) {
+	runs, steps := 50, 200
+	if testing.Short() {
+		runs = 10
+	}
+
+	for seed := int64(1); seed <= int64(runs); seed++ {
+		ops := randomOps(rand.New(rand.NewSource(seed)), steps)
+		if diff := runDifferential(ops); diff != "" {
+			minimal := shrinkOps(ops, func(ops []diffOp) bool { return runDifferential(ops) != "" })
+			t.Fatalf("seed %d: engine and reference differ: %s\nminimal repro (%d of %d ops): %s\n%s",
+				seed, diff, len(minimal), len(ops), runDifferential(minimal), formatOps(minimal))
+		}
+	}
+}
+
+func TestShrinkOps(t *testing.T) {
+	// A failure that needs a sell of 7 followed later by a cancel of order 2
+	fails := func(ops []diffOp) bool {
+		sold := false
+		for _, op := range ops {
+			if !op.Cancel && op.Side == models.OrderSideSell && op.Quantity == 7 {
+				sold = true
+			}
+			if sold && op.Cancel && op.Target == 2 {
+				return true
+			}
+		}
+		return false
+	}
+
+	ops := randomOps(rand.New(rand.NewSource(1)), 100)
+	ops = append(ops, diffOp{Side: models.OrderSideSell, Type: models.OrderTypeLimit, Price: 101, Quantity: 7}, diffOp{Cancel: true, Target: 2})
+
+	minimal := shrinkOps(ops, fails)
+	want := []diffOp{{Side: models.OrderSideSell, Type: models.OrderTypeLimit, Price: 100, Quantity: 7}, {Cancel: true, Target: 2}}
+	if fmt.Sprint(minimal) != fmt.Sprint(want) {
+		t.Fatalf("shrunk to %s, want %s", formatOps(minimal), formatOps(want))
+	}
+}
+
+// FuzzMatchingReference compares the engine with the reference matcher on
+// operation sequences decoded from the input, four bytes per operation
+func FuzzMatchingReference(f *testing.F) {
+	f.Add([]byte{2, 0, 5, 9, 2, 1, 5, 9})
+	f.Add([]byte{2, 0, 5, 9, 2, 0, 5, 3, 1, 1, 0, 19})
+	f.Add([]byte{2, 1, 4, 1, 2, 1, 6, 1, 0, 0, 0, 0, 3, 0, 10, 7})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var ops []diffOp
+		for i := 0; i+4 <= len(data) && len(ops) < 256; i += 4 {
+			ops = append(ops, decodeDiffOp([4]byte(data[i:i+4]), len(ops)))
+		}
+		if diff := runDifferential(ops); diff != "" {
+			t.Fatalf("engine and reference differ: %s\n%s", diff, formatOps(ops))
+		}
+	})
+}
+
+// decodeDiffOp reads an operation the way propertyHarness.apply does. A
+// cancel targets an ID up to the number of earlier operations, which may not
+// be an order.
+func decodeDiffOp(b [4]byte, earlier int) diffOp {
+	if b[0]%8 == 0 {
+		return diffOp{Cancel: true, Target: int(b[2])%max(earlier, 1) + 1}
+	}
+	op := diffOp{
+		Side:     models.OrderSideBuy,
+		Type:     models.OrderTypeLimit,
+		Price:    100 + float64(int(b[2]%11)-5)*0.5,
+		Quantity: float64(b[3]%20+1) / 2,
+	}
+	if b[1]&1 == 1 {
+		op.Side = models.OrderSideSell
+	}
+	if b[0]%8 == 1 {
+		op.Type, op.Price = models.OrderTypeMarket, 0
+	}
+	return op
+}
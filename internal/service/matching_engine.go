@@ -1,110 +1,262 @@
 package service
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
+	"strconv"
 	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"order-matching-system/internal/database"
+	"order-matching-system/internal/logging"
 	"order-matching-system/internal/models"
 )
 
+var (
+	// ErrEngineStopped is returned for orders and other changes submitted
+	// after Shutdown has begun
+	ErrEngineStopped = errors.New("matching engine is shutting down")
+
+	// ErrDuplicateOrder is returned when an order's idempotency key was
+	// already used for the same order; the order is replaced by the stored one
+	ErrDuplicateOrder = errors.New("order already placed with this idempotency key")
+
+	// ErrIdempotencyKeyReused is returned when an order's idempotency key was
+	// already used for a different order
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different order")
+
+	// ErrOrderNotCancelable is returned when canceling an order that does not
+	// exist or is no longer open. For an order that does not exist the error
+	// also matches database.ErrOrderNotFound.
+	ErrOrderNotCancelable = errors.New("order not found or already filled/canceled")
+
+	// ErrTradingHalted is returned when amending an order in a halted symbol
+	ErrTradingHalted = errors.New("trading is halted")
+)
+
+// unknownOrderError is ErrOrderNotCancelable for an order that does not exist
+type unknownOrderError struct{}
+
+func (unknownOrderError) Error() string { return ErrOrderNotCancelable.Error() }
+
+func (unknownOrderError) Is(target error) bool {
+	return target == ErrOrderNotCancelable || target == database.ErrOrderNotFound
+}
+
 type MatchingEngine struct {
-	db          *sql.DB
-	orderRepo   *database.OrderRepository
-	tradeRepo   *database.TradeRepository
-	orderBookMu sync.RWMutex // Protects concurrent access to order book
+	store        database.Store
+	orderBookMu  chan struct{} // Protects concurrent access to order book; a channel so lock waits can be abandoned
+	orderTimeout time.Duration // Upper bound on a single ProcessOrder call, zero for none
+	riskLimits   RiskLimits
+	costMethod   models.CostMethod // How positions realize PnL
+	feed         *MarketFeed
+
+	stateMu  sync.Mutex     // Guards stopping and additions to inFlight
+	stopping bool           // Set once Shutdown has been called
+	inFlight sync.WaitGroup // ProcessOrder calls that have not yet returned
 }
 
 func NewMatchingEngine(db *sql.DB) *MatchingEngine {
+	return NewMatchingEngineWithStore(database.NewSQLStore(db))
+}
+
+// NewMatchingEngineWithStore returns an engine that keeps orders, trades and
+// positions in store, such as an in-memory store for simulations
+func NewMatchingEngineWithStore(store database.Store) *MatchingEngine {
 	return &MatchingEngine{
-		db:        db,
-		orderRepo: database.NewOrderRepository(db),
-		tradeRepo: database.NewTradeRepository(db),
+		store:       store,
+		orderBookMu: make(chan struct{}, 1),
+		costMethod:  models.CostMethodAverage,
+		feed:        newMarketFeed(),
 	}
 }
 
-func (me *MatchingEngine) ProcessOrder(order *models.Order) error {
-	me.orderBookMu.Lock()
-	defer me.orderBookMu.Unlock()
+// Feed returns the stream of trades and book changes committed by this engine
+func (me *MatchingEngine) Feed() *MarketFeed {
+	return me.feed
+}
 
-	tx, err := me.db.Begin()
-	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+// SetOrderTimeout bounds how long a single ProcessOrder call, including the
+// wait for the order book lock, may take before it is abandoned and rolled back
+func (me *MatchingEngine) SetOrderTimeout(d time.Duration) {
+	me.orderTimeout = d
+}
+
+// SetRiskLimits configures the pre-trade risk checks applied to new orders
+func (me *MatchingEngine) SetRiskLimits(limits RiskLimits) {
+	me.riskLimits = limits
+}
+
+// SetCostMethod selects how account positions realize PnL on closing trades
+func (me *MatchingEngine) SetCostMethod(method models.CostMethod) {
+	me.costMethod = method
+}
+
+// lockOrderBook acquires the order book lock unless ctx is done first
+func (me *MatchingEngine) lockOrderBook(ctx context.Context) error {
+	_, span := tracer.Start(ctx, "MatchingEngine.lockOrderBook")
+	defer span.End()
+
+	select {
+	case me.orderBookMu <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for order book lock: %w", ctx.Err())
 	}
-	defer tx.Rollback()
+}
 
-	// Create repositories with transaction
-	orderRepo := database.NewOrderRepository(tx)
-	tradeRepo := database.NewTradeRepository(tx)
+func (me *MatchingEngine) unlockOrderBook() {
+	<-me.orderBookMu
+}
 
-	// Save the order to database first
-	order.Status = models.OrderStatusOpen
-	order.RemainingQuantity = order.InitialQuantity
-	if err := orderRepo.CreateOrder(order); err != nil {
-		return fmt.Errorf("failed to create order: %w", err)
+// Shutdown stops the engine accepting new orders and waits for in-flight
+// ProcessOrder calls to finish, or for ctx to expire
+func (me *MatchingEngine) Shutdown(ctx context.Context) error {
+	me.stateMu.Lock()
+	me.stopping = true
+	me.stateMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		me.inFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("waiting for in-flight orders: %w", ctx.Err())
 	}
+}
 
-	// Get matching orders from the opposite side
-	matchingOrders, err := me.getMatchingOrders(tx, order.Symbol, order.Side)
-	if err != nil {
-		return fmt.Errorf("failed to get matching orders: %w", err)
+// enter registers a ProcessOrder call, refusing it once shutdown has begun
+func (me *MatchingEngine) enter() bool {
+	me.stateMu.Lock()
+	defer me.stateMu.Unlock()
+
+	if me.stopping {
+		return false
 	}
+	me.inFlight.Add(1)
+	return true
+}
 
-	// Process matches
-	for _, matchOrder := range matchingOrders {
-		if order.RemainingQuantity <= 0 {
-			break
-		}
+// ProcessOrder persists an incoming order and matches it against the book in
+// a single transaction. If ctx expires before commit, the transaction is
+// rolled back and no part of the order or its trades is kept. An order that
+// fails a pre-trade risk check is stored with status rejected and a reject
+// code, and nil is returned.
+func (me *MatchingEngine) ProcessOrder(ctx context.Context, order *models.Order) (err error) {
+	if !me.enter() {
+		return ErrEngineStopped
+	}
+	defer me.inFlight.Done()
 
-		// Check if orders can match
-		if !me.canMatch(order, matchOrder) {
-			continue	//next iteration
-		}
+	ctx = logging.With(ctx, slog.String("symbol", order.Symbol), slog.String("account_id", order.AccountID))
 
-		// Determine trade price (use resting order's price)
-		tradePrice := me.determineTradePrice(order, matchOrder)
+	if me.orderTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, me.orderTimeout)
+		defer cancel()
+	}
 
-		// Calculate trade quantity
-		tradeQuantity := min(order.RemainingQuantity, matchOrder.RemainingQuantity)
+	ctx, span := tracer.Start(ctx, "MatchingEngine.ProcessOrder", trace.WithAttributes(orderAttributes(order)...))
 
-		// Create trade record
-		trade := &models.Trade{
-			Symbol:   order.Symbol,
-			Price:    tradePrice,
-			Quantity: tradeQuantity,
+	var trades []*models.Trade
+	var tx database.Tx
+	arrived := time.Now()
+	locked := arrived
+	defer func() {
+		var storeTime time.Duration
+		if tx != nil {
+			storeTime = tx.Elapsed()
 		}
+		observeProcessOrder(order, arrived, locked, storeTime, trades, err)
+		logProcessOrder(ctx, order, trades, err)
+		endProcessOrderSpan(span, order, trades, err)
+	}()
+
+	if err := me.lockOrderBook(ctx); err != nil {
+		locked = time.Now()
+		return err
+	}
+	defer me.unlockOrderBook()
+	locked = time.Now()
 
-		// Set buy and sell order IDs
-		if order.Side == models.OrderSideBuy {
-			trade.BuyOrderID = order.ID
-			trade.SellOrderID = matchOrder.ID
-		} else {
-			trade.BuyOrderID = matchOrder.ID
-			trade.SellOrderID = order.ID
-		}
+	tx, err = me.store.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-		// Save trade
-		if err := tradeRepo.CreateTrade(trade); err != nil {
-			return fmt.Errorf("failed to create trade: %w", err)
-		}
+	// Create repositories with transaction
+	otx := newOrderTx(tx, me.costMethod)
+	orderRepo := otx.orders
+	recorder := otx.recorder
 
-		// Update order quantities
-		order.RemainingQuantity -= tradeQuantity
-		matchOrder.RemainingQuantity -= tradeQuantity
+	// A resubmitted order returns the original instead of trading again
+	if order.IdempotencyKey != "" {
+		existing, err := orderRepo.GetOrderByIdempotencyKey(ctx, order.IdempotencyKey)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			if !existing.SameRequest(order) {
+				return ErrIdempotencyKeyReused
+			}
+			*order = *existing
+			return ErrDuplicateOrder
+		}
+	}
 
-		// Update matched order status
-		matchStatus := models.OrderStatusOpen
-		if matchOrder.RemainingQuantity == 0 {
-			matchStatus = models.OrderStatusFilled
+	// Checks run under the book lock so that they see every earlier order
+	rejection, err := me.checkOrder(ctx, otx, order)
+	if err != nil {
+		return err
+	}
+	if rejection != nil {
+		order.Status = models.OrderStatusRejected
+		order.StatusReason = rejection.reason
+		order.RejectCode = rejection.code
+		order.RemainingQuantity = 0
+		if err := orderRepo.CreateOrder(ctx, order); err != nil {
+			return fmt.Errorf("failed to create order: %w", err)
 		}
-		if err := orderRepo.UpdateOrderStatus(matchOrder.ID, matchStatus, matchOrder.RemainingQuantity); err != nil {
-			return fmt.Errorf("failed to update matched order: %w", err)
+		ctx = logging.With(ctx, slog.Int("order_id", order.ID))
+		if err := recorder.orderEvent(ctx, order, rejectedEvent(order)); err != nil {
+			return err
 		}
+
+		return tx.Commit()
 	}
 
+	// Save the order to database first
+	order.Status = models.OrderStatusOpen
+	order.RemainingQuantity = order.InitialQuantity
+	if err := orderRepo.CreateOrder(ctx, order); err != nil {
+		return fmt.Errorf("failed to create order: %w", err)
+	}
+	ctx = logging.With(ctx, slog.Int("order_id", order.ID))
+	if err := recorder.orderEvent(ctx, order, acceptedEvent(order)); err != nil {
+		return err
+	}
+
+	result, err := me.match(ctx, otx, order)
+	if err != nil {
+		return err
+	}
+	trades = result.trades
+
 	// Update the incoming order status
 	finalStatus := models.OrderStatusOpen
+	unfilled := order.RemainingQuantity
 	if order.RemainingQuantity == 0 {
 		finalStatus = models.OrderStatusFilled
 	} else if order.Type == models.OrderTypeMarket && order.RemainingQuantity < order.InitialQuantity {
@@ -117,86 +269,292 @@ func (me *MatchingEngine) ProcessOrder(order *models.Order) error {
 		order.RemainingQuantity = 0
 	}
 
-	if err := orderRepo.UpdateOrderStatus(order.ID, finalStatus, order.RemainingQuantity); err != nil {
+	order.Status = finalStatus
+	if finalStatus == models.OrderStatusCanceled {
+		order.StatusReason = "insufficient liquidity"
+		if result.protectionHit {
+			order.StatusReason = "price protection limit " + strconv.FormatFloat(result.protection, 'f', -1, 64) + " reached"
+		}
+		event := cancelEvent(order, unfilled, order.StatusReason, models.ActorEngine)
+		if err := recorder.orderEvent(ctx, order, event); err != nil {
+			return err
+		}
+	}
+
+	if err := orderRepo.UpdateOrderExecution(ctx, order); err != nil {
 		return fmt.Errorf("failed to update order status: %w", err)
 	}
 
 	// Commit transaction
 	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("failed to commit transaction: %w", err)
+		return err
 	}
+	me.feed.publish(order.Symbol, trades)
 
-	order.Status = finalStatus
 	return nil
 }
 
-// getMatchingOrders retrieves orders that can potentially match
-func (me *MatchingEngine) getMatchingOrders(tx *sql.Tx, symbol string, side models.OrderSide) ([]*models.Order, error) {
-	var query string
-
-	if side == models.OrderSideBuy {
-		// For buy orders, get sell orders sorted by price ASC, then by time
-		query = `
-			SELECT id, symbol, side, type, price, initial_quantity, remaining_quantity, status, created_at, updated_at
-			FROM orders
-			WHERE symbol = ? AND side = 'sell' AND status = 'open'
-			ORDER BY 
-				CASE WHEN type = 'market' THEN 0 ELSE price END ASC,
-				created_at ASC
-		`
-	} else {
-		// For sell orders, get buy orders sorted by price DESC, then by time
-		query = `
-			SELECT id, symbol, side, type, price, initial_quantity, remaining_quantity, status, created_at, updated_at
-			FROM orders
-			WHERE symbol = ? AND side = 'buy' AND status = 'open'
-			ORDER BY 
-				CASE WHEN type = 'market' THEN 999999999 ELSE price END DESC,
-				created_at ASC
-		`
-	}
-
-	rows, err := tx.Query(query, symbol)
+// CancelOrder cancels an open order on behalf of actor and records the
+// transition in the order's history
+func (me *MatchingEngine) CancelOrder(ctx context.Context, orderID int, actor string) (_ *models.Order, err error) {
+	if !me.enter() {
+		return nil, ErrEngineStopped
+	}
+	defer me.inFlight.Done()
+
+	ctx = logging.With(ctx, slog.Int("order_id", orderID))
+	ctx, span := tracer.Start(ctx, "MatchingEngine.CancelOrder", trace.WithAttributes(attribute.Int("order.id", orderID)))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	if err := me.lockOrderBook(ctx); err != nil {
+		return nil, err
+	}
+	defer me.unlockOrderBook()
+
+	tx, err := me.store.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query matching orders: %w", err)
-	}
-	defer rows.Close()
-
-	var orders []*models.Order
-	for rows.Next() {
-		order := &models.Order{}
-		var price sql.NullFloat64	//market 
-
-		err := rows.Scan(
-			&order.ID,
-			&order.Symbol,
-			&order.Side,
-			&order.Type,
-			&price,
-			&order.InitialQuantity,
-			&order.RemainingQuantity,
-			&order.Status,
-			&order.CreatedAt,
-			&order.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan order: %w", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	orderRepo := tx.Orders()
+	recorder := newEventRecorder(tx)
+
+	order, err := orderRepo.GetOrderByID(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, database.ErrOrderNotFound) {
+			return nil, unknownOrderError{}
+		}
+		return nil, err
+	}
+	ctx = logging.With(ctx, slog.String("symbol", order.Symbol), slog.String("account_id", order.AccountID))
+
+	if err := orderRepo.CancelOrder(ctx, orderID); err != nil {
+		if errors.Is(err, database.ErrOrderNotOpen) {
+			return nil, ErrOrderNotCancelable
+		}
+		return nil, err
+	}
+
+	order.Status = models.OrderStatusCanceled
+	if err := recorder.orderEvent(ctx, order, cancelEvent(order, order.RemainingQuantity, "canceled by request", actor)); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	me.feed.publish(order.Symbol, nil)
+	slog.InfoContext(ctx, "order canceled", "actor", actor, "remaining", order.RemainingQuantity)
+
+	return order, nil
+}
+
+// checkOrder returns why a new order may not trade: its symbol is halted or
+// it fails a pre-trade risk check. It returns nil if the order may proceed.
+func (me *MatchingEngine) checkOrder(ctx context.Context, otx *orderTx, order *models.Order) (*riskRejection, error) {
+	halt, err := otx.tx.Halts().GetHalt(ctx, order.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if halt != nil {
+		return reject(models.RejectTradingHalted, "%s", haltMessage(halt)), nil
+	}
+
+	rejection, err := newRiskChecker(me.riskLimits, otx.tx).check(ctx, order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run risk checks: %w", err)
+	}
+	return rejection, nil
+}
+
+// matchResult is the outcome of matching an incoming order against the book
+type matchResult struct {
+	trades        []*models.Trade
+	protection    float64 // Price protection limit of a market order
+	protectionHit bool    // Matching stopped at the protection limit
+}
+
+// match trades order against the opposite side of the book, best price
+// first, until it is filled or no resting order can match
+func (me *MatchingEngine) match(ctx context.Context, otx *orderTx, order *models.Order) (_ *matchResult, err error) {
+	ctx, span := tracer.Start(ctx, "MatchingEngine.match")
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	// Select the allocation strategy configured for the instrument
+	instrument, err := otx.tx.Instruments().GetInstrument(ctx, order.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	allocator := NewAllocator(instrument)
+
+	// Get matching orders from the opposite side
+	matchingOrders, err := otx.orders.GetMatchingOrders(ctx, order.Symbol, order.Side)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get matching orders: %w", err)
+	}
+
+	// Market orders are protected from walking too far through a thin book
+	levels := priceLevels(matchingOrders)
+	result := &matchResult{}
+	var protected bool
+	if order.Type == models.OrderTypeMarket && len(levels) > 0 {
+		result.protection, protected = protectionLimit(order, instrument, levels[0][0].Price)
+	}
+
+	// Process matches one price level at a time, best price first
+	for _, level := range levels {
+		if order.RemainingQuantity <= 0 {
+			break
 		}
 
-		if price.Valid {
-			order.Price = price.Float64
+		// Check if orders can match
+		if !me.canMatch(order, level[0]) {
+			continue //next iteration
+		}
+		if protected && beyondLimit(order.Side, level[0].Price, result.protection) {
+			result.protectionHit = true
+			break
 		}
 
-		orders = append(orders, order)
+		// Divide the incoming quantity among the orders at this price
+		resting := make([]RestingQuantity, len(level))
+		for i, matchOrder := range level {
+			resting[i] = RestingQuantity{
+				Units:     models.ToUnits(matchOrder.RemainingQuantity),
+				AccountID: matchOrder.AccountID,
+			}
+		}
+		allocations := allocator.Allocate(models.ToUnits(order.RemainingQuantity), resting)
+
+		for i, matchOrder := range level {
+			if allocations[i] == 0 {
+				continue
+			}
+
+			trade, err := me.executeTrade(ctx, otx, order, matchOrder, models.FromUnits(allocations[i]))
+			if err != nil {
+				return nil, err
+			}
+			result.trades = append(result.trades, trade)
+		}
 	}
+	span.SetAttributes(attribute.Int("match.levels", len(levels)), attribute.Int("match.trades", len(result.trades)))
+
+	return result, nil
+}
+
+// orderTx holds the repositories bound to one matching transaction
+type orderTx struct {
+	tx        database.Tx
+	orders    database.OrderStore
+	trades    database.TradeStore
+	candles   database.CandleStore
+	positions *positionKeeper
+	recorder  *eventRecorder
+}
 
-	return orders, nil
+func newOrderTx(tx database.Tx, costMethod models.CostMethod) *orderTx {
+	return &orderTx{
+		tx:        tx,
+		orders:    tx.Orders(),
+		trades:    tx.Trades(),
+		candles:   tx.Candles(),
+		positions: newPositionKeeper(costMethod, tx.Positions()),
+		recorder:  newEventRecorder(tx),
+	}
+}
+
+// executeTrade trades quantity between the incoming order and a resting
+// order, persisting the trade, both orders' new state and their events
+func (me *MatchingEngine) executeTrade(ctx context.Context, otx *orderTx, order, matchOrder *models.Order, quantity float64) (*models.Trade, error) {
+	// Determine trade price (use resting order's price)
+	tradePrice := me.determineTradePrice(order, matchOrder)
+
+	// Create trade record
+	trade := &models.Trade{
+		Symbol:   order.Symbol,
+		Price:    tradePrice,
+		Quantity: quantity,
+	}
+
+	// Set buy and sell order IDs
+	buyer, seller := order, matchOrder
+	if order.Side == models.OrderSideSell {
+		buyer, seller = matchOrder, order
+	}
+	trade.BuyOrderID = buyer.ID
+	trade.SellOrderID = seller.ID
+
+	// Save trade
+	if err := otx.trades.CreateTrade(ctx, trade); err != nil {
+		return nil, fmt.Errorf("failed to create trade: %w", err)
+	}
+	if err := otx.candles.ApplyTrade(ctx, trade); err != nil {
+		return nil, fmt.Errorf("failed to update candles: %w", err)
+	}
+	if err := otx.positions.trade(ctx, trade, buyer.AccountID, seller.AccountID); err != nil {
+		return nil, fmt.Errorf("failed to update positions: %w", err)
+	}
+	if err := otx.recorder.trade(ctx, trade); err != nil {
+		return nil, err
+	}
+	slog.DebugContext(ctx, "trade executed", "trade_id", trade.ID, "resting_order_id", matchOrder.ID,
+		"resting_account_id", matchOrder.AccountID, "price", trade.Price, "quantity", trade.Quantity)
+
+	// Update order quantities and fill summaries
+	incomingBefore := order.RemainingQuantity
+	restingBefore := matchOrder.RemainingQuantity
+	order.ApplyFill(trade.Price, trade.Quantity, trade.CreatedAt)
+	matchOrder.ApplyFill(trade.Price, trade.Quantity, trade.CreatedAt)
+
+	// Update order statuses
+	if order.RemainingQuantity == 0 {
+		order.Status = models.OrderStatusFilled
+	}
+	if matchOrder.RemainingQuantity == 0 {
+		matchOrder.Status = models.OrderStatusFilled
+	}
+	if err := otx.orders.UpdateOrderExecution(ctx, matchOrder); err != nil {
+		return nil, fmt.Errorf("failed to update matched order: %w", err)
+	}
+
+	if err := otx.recorder.orderEvent(ctx, matchOrder, fillEvent(matchOrder, restingBefore, trade)); err != nil {
+		return nil, err
+	}
+	if err := otx.recorder.orderEvent(ctx, order, fillEvent(order, incomingBefore, trade)); err != nil {
+		return nil, err
+	}
+
+	return trade, nil
+}
+
+// priceLevels splits orders already sorted by priority into runs that share
+// a price
+func priceLevels(orders []*models.Order) [][]*models.Order {
+	var levels [][]*models.Order
+	for _, order := range orders {
+		n := len(levels)
+		if n > 0 {
+			last := levels[n-1][0]
+			if last.Type == order.Type && last.Price == order.Price {
+				levels[n-1] = append(levels[n-1], order)
+				continue
+			}
+		}
+		levels = append(levels, []*models.Order{order})
+	}
+	return levels
 }
 
 // canMatch determines if two orders can match
 func (me *MatchingEngine) canMatch(incoming, resting *models.Order) bool {
 	// Market orders always match
-	if incoming.Type == models.OrderTypeMarket || resting.Type == models.OrderTypeMarket {	//resting.Type == models.OrderTypeMarket wont happen
+	if incoming.Type == models.OrderTypeMarket || resting.Type == models.OrderTypeMarket { //resting.Type == models.OrderTypeMarket wont happen
 		return true
 	}
 
@@ -220,11 +578,3 @@ func (me *MatchingEngine) determineTradePrice(incoming, resting *models.Order) f
 	// Both market orders shouldn't happen, but if it does, use incoming price
 	return incoming.Price
 }
-
-// min returns the minimum of two float64 values
-func min(a, b float64) float64 {
-	if a < b {
-		return a
-	}
-	return b
-}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+)
+
+// testTime is when the clock of engines made by newTestEngine starts
+var testTime = time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+
+// newTestEngine returns an engine on an in-memory store whose clock starts at
+// testTime and advances a microsecond each time it is read, so that time
+// priority follows the order of operations
+func newTestEngine(t *testing.T) (*MatchingEngine, *memory.Store) {
+	t.Helper()
+	now := testTime
+	store := memory.NewStore(func() time.Time {
+		now = now.Add(time.Microsecond)
+		return now
+	})
+	return NewMatchingEngineWithStore(store), store
+}
+
+// placeOrder submits an order for account in symbol TEST, failing the test
+// if the engine returns an error. A zero price places a market order.
+func placeOrder(t *testing.T, me *MatchingEngine, account string, side models.OrderSide, price, quantity float64) *models.Order {
+	t.Helper()
+	req := &models.PlaceOrderRequest{AccountID: account, Symbol: "TEST", Side: side, Type: models.OrderTypeLimit, Price: price, Quantity: quantity}
+	if price == 0 {
+		req.Type = models.OrderTypeMarket
+	}
+	order := req.NewOrder()
+	if err := me.ProcessOrder(context.Background(), order); err != nil {
+		t.Fatalf("failed to place %s %v @ %v: %v", side, quantity, price, err)
+	}
+	return order
+}
+
+func limitOrder(side models.OrderSide, price float64) *models.Order {
+	return &models.Order{Side: side, Type: models.OrderTypeLimit, Price: price}
+}
+
+func marketOrder(side models.OrderSide) *models.Order {
+	return &models.Order{Side: side, Type: models.OrderTypeMarket}
+}
+
+func TestCanMatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming *models.Order
+		resting  *models.Order
+		want     bool
+	}{
+		{"buy above ask", limitOrder(models.OrderSideBuy, 101), limitOrder(models.OrderSideSell, 100), true},
+		{"buy at ask", limitOrder(models.OrderSideBuy, 100), limitOrder(models.OrderSideSell, 100), true},
+		{"buy below ask", limitOrder(models.OrderSideBuy, 99.99), limitOrder(models.OrderSideSell, 100), false},
+		{"sell below bid", limitOrder(models.OrderSideSell, 99), limitOrder(models.OrderSideBuy, 100), true},
+		{"sell at bid", limitOrder(models.OrderSideSell, 100), limitOrder(models.OrderSideBuy, 100), true},
+		{"sell above bid", limitOrder(models.OrderSideSell, 100.01), limitOrder(models.OrderSideBuy, 100), false},
+		{"market buy", marketOrder(models.OrderSideBuy), limitOrder(models.OrderSideSell, 1e6), true},
+		{"market sell", marketOrder(models.OrderSideSell), limitOrder(models.OrderSideBuy, 0.01), true},
+	}
+
+	me := &MatchingEngine{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := me.canMatch(tt.incoming, tt.resting); got != tt.want {
+				t.Fatalf("canMatch = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetermineTradePrice(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming *models.Order
+		resting  *models.Order
+		want     float64
+	}{
+		{"limit buy takes resting ask price", limitOrder(models.OrderSideBuy, 105), limitOrder(models.OrderSideSell, 100), 100},
+		{"limit sell takes resting bid price", limitOrder(models.OrderSideSell, 95), limitOrder(models.OrderSideBuy, 100), 100},
+		{"market buy takes resting ask price", marketOrder(models.OrderSideBuy), limitOrder(models.OrderSideSell, 100.5), 100.5},
+		{"market sell takes resting bid price", marketOrder(models.OrderSideSell), limitOrder(models.OrderSideBuy, 99.5), 99.5},
+	}
+
+	me := &MatchingEngine{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := me.determineTradePrice(tt.incoming, tt.resting); got != tt.want {
+				t.Fatalf("determineTradePrice = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTradesBucketIntoCandles(t *testing.T) {
+	now := testTime
+	store := memory.NewStore(func() time.Time { return now })
+	me := NewMatchingEngineWithStore(store)
+
+	trades := []struct {
+		at    time.Duration // After testTime
+		price float64
+	}{
+		{0, 100},
+		{59*time.Second + 999*time.Millisecond, 102},
+		{time.Minute, 101},
+		{29*time.Minute + 59*time.Second, 99},
+		{30 * time.Minute, 103},
+	}
+	for _, trade := range trades {
+		now = testTime.Add(trade.at)
+		placeOrder(t, me, "acct-s", models.OrderSideSell, trade.price, 1)
+		placeOrder(t, me, "acct-b", models.OrderSideBuy, trade.price, 1)
+	}
+
+	tests := []struct {
+		interval models.CandleInterval
+		openTime time.Time
+		want     models.Candle // Only prices, volume and count are compared
+	}{
+		{models.CandleInterval1m, testTime, models.Candle{Open: 100, High: 102, Low: 100, Close: 102, Volume: 2, TradeCount: 2}},
+		{models.CandleInterval1m, testTime.Add(time.Minute), models.Candle{Open: 101, High: 101, Low: 101, Close: 101, Volume: 1, TradeCount: 1}},
+		{models.CandleInterval1m, testTime.Add(29 * time.Minute), models.Candle{Open: 99, High: 99, Low: 99, Close: 99, Volume: 1, TradeCount: 1}},
+		{models.CandleInterval1m, testTime.Add(30 * time.Minute), models.Candle{Open: 103, High: 103, Low: 103, Close: 103, Volume: 1, TradeCount: 1}},
+		// testTime is half past, so the first hour bar opens at 09:00
+		{models.CandleInterval1h, testTime.Add(-30 * time.Minute), models.Candle{Open: 100, High: 102, Low: 99, Close: 99, Volume: 4, TradeCount: 4}},
+		{models.CandleInterval1h, testTime.Add(30 * time.Minute), models.Candle{Open: 103, High: 103, Low: 103, Close: 103, Volume: 1, TradeCount: 1}},
+	}
+	for _, tt := range tests {
+		bar := store.Candle("TEST", tt.interval, tt.openTime)
+		if bar == nil {
+			t.Fatalf("no %s bar at %v", tt.interval, tt.openTime)
+		}
+		got := models.Candle{Open: bar.Open, High: bar.High, Low: bar.Low, Close: bar.Close, Volume: bar.Volume, TradeCount: bar.TradeCount}
+		if got != tt.want {
+			t.Errorf("%s bar at %v is %+v, want %+v", tt.interval, tt.openTime.Format("15:04"), got, tt.want)
+		}
+	}
+}
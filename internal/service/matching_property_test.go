@@ -0,0 +1,284 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+)
+
+const propertySymbol = "PROP"
+
+var propertyAccounts = []string{"acct-a", "acct-b", "acct-c"}
+
+// propertyHarness drives a MatchingEngine on an in-memory store and checks
+// the book's invariants after every operation. The clock advances with each
+// operation, so time priority follows order IDs.
+type propertyHarness struct {
+	t       testing.TB
+	engine  *MatchingEngine
+	store   *memory.Store
+	now     time.Time
+	fifo    bool
+	maxID   int
+	tradeID int
+	filled  map[int]int64 // Units filled per order, summed from trades
+}
+
+func newPropertyHarness(t testing.TB, algorithm models.MatchingAlgorithm) *propertyHarness {
+	h := &propertyHarness{
+		t:      t,
+		now:    time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC),
+		fifo:   algorithm == models.MatchingFIFO,
+		filled: make(map[int]int64),
+	}
+	h.store = memory.NewStore(func() time.Time { return h.now })
+	instrument := models.DefaultInstrument(propertySymbol)
+	instrument.Algorithm = algorithm
+	h.store.SetInstrument(instrument)
+	h.engine = NewMatchingEngineWithStore(h.store)
+	return h
+}
+
+// apply decodes one operation from four bytes: its kind, the side and
+// account, the price (or the order to cancel) and the quantity. Prices fall
+// on a few levels around 100 so that queues form.
+func (h *propertyHarness) apply(op [4]byte) {
+	h.t.Helper()
+
+	if op[0]%8 == 0 {
+		if h.maxID > 0 {
+			h.cancel(int(op[2])%h.maxID + 1)
+		}
+		return
+	}
+
+	req := &models.PlaceOrderRequest{
+		AccountID: propertyAccounts[int(op[1]>>1)%len(propertyAccounts)],
+		Symbol:    propertySymbol,
+		Side:      models.OrderSideBuy,
+		Type:      models.OrderTypeLimit,
+		Price:     100 + float64(int(op[2]%11)-5)*0.5,
+		Quantity:  float64(op[3]%20+1) / 2,
+	}
+	if op[1]&1 == 1 {
+		req.Side = models.OrderSideSell
+	}
+	if op[0]%8 == 1 {
+		req.Type = models.OrderTypeMarket
+		req.Price = 0
+	}
+	h.place(req)
+}
+
+func (h *propertyHarness) place(req *models.PlaceOrderRequest) {
+	h.t.Helper()
+	h.now = h.now.Add(time.Millisecond)
+
+	order := req.NewOrder()
+	if err := h.engine.ProcessOrder(context.Background(), order); err != nil {
+		h.t.Fatalf("ProcessOrder(%+v): %v", *req, err)
+	}
+	h.maxID = max(h.maxID, order.ID)
+
+	h.checkTrades(order)
+	h.checkBook()
+	h.checkOrders()
+}
+
+func (h *propertyHarness) cancel(id int) {
+	h.t.Helper()
+	h.now = h.now.Add(time.Millisecond)
+
+	before := h.store.Order(id)
+	_, err := h.engine.CancelOrder(context.Background(), id, "test")
+	switch {
+	case errors.Is(err, ErrOrderNotCancelable):
+		if before.Status == models.OrderStatusOpen {
+			h.t.Fatalf("cancel of open order %d refused", id)
+		}
+	case err != nil:
+		h.t.Fatalf("CancelOrder(%d): %v", id, err)
+	default:
+		if before.Status != models.OrderStatusOpen {
+			h.t.Fatalf("cancel of %s order %d succeeded", before.Status, id)
+		}
+	}
+
+	h.checkBook()
+	h.checkOrders()
+}
+
+// restingOrders returns every open order in the book
+func (h *propertyHarness) restingOrders() []*models.Order {
+	var orders []*models.Order
+	for _, account := range propertyAccounts {
+		orders = append(orders, h.store.OpenOrders(account, propertySymbol)...)
+	}
+	return orders
+}
+
+// better reports whether price a is better than b for an order on side
+func better(side models.OrderSide, a, b float64) bool {
+	if side == models.OrderSideBuy {
+		return a > b
+	}
+	return a < b
+}
+
+// checkTrades checks the trades made by incoming: each is priced at the
+// resting order's price within both limits, taken in price-time priority
+// (price only under pro-rata), and a market order only stops short of
+// filling when the opposite side is empty
+func (h *propertyHarness) checkTrades(incoming *models.Order) {
+	h.t.Helper()
+
+	resting := h.restingOrders()
+	lastPrice := 0.0
+	for i, trade := range h.store.TradesSince(h.tradeID) {
+		h.tradeID = trade.ID
+		buy, sell := h.store.Order(trade.BuyOrderID), h.store.Order(trade.SellOrderID)
+		if buy == nil || sell == nil {
+			h.t.Fatalf("trade %d refers to a missing order", trade.ID)
+		}
+		if trade.Quantity <= 0 {
+			h.t.Fatalf("trade %d has quantity %v", trade.ID, trade.Quantity)
+		}
+		if buy.Type == models.OrderTypeLimit && trade.Price > buy.Price {
+			h.t.Fatalf("trade %d at %v is above buy limit %v", trade.ID, trade.Price, buy.Price)
+		}
+		if sell.Type == models.OrderTypeLimit && trade.Price < sell.Price {
+			h.t.Fatalf("trade %d at %v is below sell limit %v", trade.ID, trade.Price, sell.Price)
+		}
+		h.filled[buy.ID] += models.ToUnits(trade.Quantity)
+		h.filled[sell.ID] += models.ToUnits(trade.Quantity)
+
+		match := buy
+		if incoming.Side == models.OrderSideBuy {
+			match = sell
+		}
+		if buy.ID != incoming.ID && sell.ID != incoming.ID {
+			h.t.Fatalf("trade %d does not involve incoming order %d", trade.ID, incoming.ID)
+		}
+		if trade.Price != match.Price {
+			h.t.Fatalf("trade %d at %v, resting order %d is priced %v", trade.ID, trade.Price, match.ID, match.Price)
+		}
+		if i > 0 && better(match.Side, trade.Price, lastPrice) {
+			h.t.Fatalf("trade %d at %v came after a worse price %v", trade.ID, trade.Price, lastPrice)
+		}
+		lastPrice = trade.Price
+
+		for _, other := range resting {
+			if other.Side != match.Side {
+				continue
+			}
+			if better(other.Side, other.Price, trade.Price) {
+				h.t.Fatalf("order %d at %v still rests after trade %d at worse price %v", other.ID, other.Price, trade.ID, trade.Price)
+			}
+			if h.fifo && other.Price == trade.Price && other.ID < match.ID {
+				h.t.Fatalf("order %d still rests after later order %d traded at %v", other.ID, match.ID, trade.Price)
+			}
+		}
+	}
+
+	final := h.store.Order(incoming.ID)
+	if final.Type == models.OrderTypeMarket && final.FilledQuantity < final.InitialQuantity {
+		for _, other := range resting {
+			if other.Side != final.Side {
+				h.t.Fatalf("market order %d stopped at %v of %v with order %d resting", final.ID, final.FilledQuantity, final.InitialQuantity, other.ID)
+			}
+		}
+	}
+}
+
+// checkBook checks that the book is not crossed and holds only limit orders
+func (h *propertyHarness) checkBook() {
+	h.t.Helper()
+
+	book := h.store.Book(propertySymbol, models.BookLevel2, 1)
+	if len(book.Bids) > 0 && len(book.Asks) > 0 && book.Bids[0].Price >= book.Asks[0].Price {
+		h.t.Fatalf("book crossed: best bid %v, best ask %v", book.Bids[0].Price, book.Asks[0].Price)
+	}
+	for _, order := range h.restingOrders() {
+		if order.Type != models.OrderTypeLimit {
+			h.t.Fatalf("%s order %d rests in the book", order.Type, order.ID)
+		}
+	}
+}
+
+// checkOrders checks that every order's filled quantity matches its trades,
+// and that open and filled orders account for their whole quantity
+func (h *propertyHarness) checkOrders() {
+	h.t.Helper()
+
+	for id := 1; id <= h.maxID; id++ {
+		o := h.store.Order(id)
+		if o == nil {
+			h.t.Fatalf("order %d is missing", id)
+		}
+		initial, filled, remaining := models.ToUnits(o.InitialQuantity), models.ToUnits(o.FilledQuantity), models.ToUnits(o.RemainingQuantity)
+		if filled != h.filled[id] {
+			h.t.Fatalf("order %d filled %v, its trades sum to %v", id, o.FilledQuantity, models.FromUnits(h.filled[id]))
+		}
+
+		switch o.Status {
+		case models.OrderStatusOpen:
+			if remaining <= 0 || filled+remaining != initial {
+				h.t.Fatalf("open order %d: initial %v, filled %v, remaining %v", id, o.InitialQuantity, o.FilledQuantity, o.RemainingQuantity)
+			}
+		case models.OrderStatusFilled:
+			if remaining != 0 || filled != initial {
+				h.t.Fatalf("filled order %d: initial %v, filled %v, remaining %v", id, o.InitialQuantity, o.FilledQuantity, o.RemainingQuantity)
+			}
+		case models.OrderStatusCanceled:
+			if filled >= initial {
+				h.t.Fatalf("canceled order %d: initial %v, filled %v", id, o.InitialQuantity, o.FilledQuantity)
+			}
+		default:
+			h.t.Fatalf("order %d has status %s", id, o.Status)
+		}
+	}
+}
+
+func TestMatchingInvariants(t *testing.T) {
+	steps := 400
+	if testing.Short() {
+		steps = 100
+	}
+
+	for _, algorithm := range []models.MatchingAlgorithm{models.MatchingFIFO, models.MatchingProRata} {
+		for seed := int64(1); seed <= 10; seed++ {
+			t.Run(fmt.Sprintf("%s/seed=%d", algorithm, seed), func(t *testing.T) {
+				h := newPropertyHarness(t, algorithm)
+				rng := rand.New(rand.NewSource(seed))
+				var op [4]byte
+				for i := 0; i < steps; i++ {
+					rng.Read(op[:])
+					h.apply(op)
+				}
+			})
+		}
+	}
+}
+
+// FuzzMatching runs sequences of orders and cancels decoded from the input,
+// four bytes per operation, checking the invariants after each
+func FuzzMatching(f *testing.F) {
+	f.Add([]byte{2, 0, 5, 9, 2, 1, 5, 9})                          // A limit buy and sell at the same price trade
+	f.Add([]byte{2, 0, 5, 9, 2, 2, 5, 3, 1, 1, 0, 19})             // Two bids at one level, then a market sell larger than both
+	f.Add([]byte{2, 1, 4, 1, 2, 1, 6, 1, 0, 0, 0, 0, 3, 0, 10, 7}) // Asks on two levels, a cancel, a buy through both
+	f.Add([]byte{1, 0, 0, 5})                                      // A market order into an empty book
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		const maxOps = 256
+		h := newPropertyHarness(t, models.MatchingFIFO)
+		for i := 0; i+4 <= len(data) && i < 4*maxOps; i += 4 {
+			h.apply([4]byte(data[i : i+4]))
+		}
+	})
+}
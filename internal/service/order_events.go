@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/models"
+)
+
+// eventRecorder writes order transitions to the audit trail and, together
+// with trades, to the outbox for downstream consumers. Both repositories must
+// share the transaction making the change.
+type eventRecorder struct {
+	events database.OrderEventStore
+	outbox database.OutboxStore
+}
+
+func newEventRecorder(tx database.Tx) *eventRecorder {
+	return &eventRecorder{
+		events: tx.Events(),
+		outbox: tx.Outbox(),
+	}
+}
+
+// orderEvent records event, publishing it with the order's current state.
+// The event is timestamped here so that the published copy carries the time
+// that is stored.
+func (r *eventRecorder) orderEvent(ctx context.Context, order *models.Order, event *models.OrderEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	if err := r.events.CreateEvent(ctx, event); err != nil {
+		return err
+	}
+
+	payload := models.OrderEventPayload{Event: event, Order: order}
+	return r.outbox.Append(ctx, models.EventOrderPrefix+string(event.Type), models.AggregateOrder, order.ID, order.Symbol, payload)
+}
+
+// trade publishes a newly created trade
+func (r *eventRecorder) trade(ctx context.Context, trade *models.Trade) error {
+	return r.outbox.Append(ctx, models.EventTradeExecuted, models.AggregateTrade, trade.ID, trade.Symbol, trade)
+}
+
+// tradeCorrection publishes an operator's bust or correction of trade
+func (r *eventRecorder) tradeCorrection(ctx context.Context, trade *models.Trade, correction *models.TradeCorrection) error {
+	eventType := models.EventTradeCorrected
+	if correction.Action == models.TradeBust {
+		eventType = models.EventTradeBusted
+	}
+	payload := models.TradeCorrectionPayload{Trade: trade, Correction: correction}
+	return r.outbox.Append(ctx, eventType, models.AggregateTrade, trade.ID, trade.Symbol, payload)
+}
+
+// acceptedEvent records a newly persisted order entering the book
+func acceptedEvent(order *models.Order) *models.OrderEvent {
+	return &models.OrderEvent{
+		OrderID:         order.ID,
+		Type:            models.OrderEventAccepted,
+		StatusAfter:     models.OrderStatusOpen,
+		RemainingBefore: 0,
+		RemainingAfter:  order.RemainingQuantity,
+		Price:           order.Price,
+		Cause:           "order accepted",
+		Actor:           models.ActorEngine,
+	}
+}
+
+// amendedEvent records a resting order's price or quantity being changed
+func amendedEvent(order *models.Order, remainingBefore float64, actor string) *models.OrderEvent {
+	return &models.OrderEvent{
+		OrderID:         order.ID,
+		Type:            models.OrderEventAmended,
+		StatusBefore:    models.OrderStatusOpen,
+		StatusAfter:     models.OrderStatusOpen,
+		RemainingBefore: remainingBefore,
+		RemainingAfter:  order.RemainingQuantity,
+		Price:           order.Price,
+		Cause:           fmt.Sprintf("amended to price %g, quantity %g", order.Price, order.InitialQuantity),
+		Actor:           actor,
+	}
+}
+
+// rejectedEvent records an order refused by the pre-trade risk checks
+func rejectedEvent(order *models.Order) *models.OrderEvent {
+	return &models.OrderEvent{
+		OrderID:         order.ID,
+		Type:            models.OrderEventRejected,
+		StatusAfter:     models.OrderStatusRejected,
+		RemainingBefore: 0,
+		RemainingAfter:  0,
+		Cause:           order.StatusReason,
+		Actor:           models.ActorEngine,
+	}
+}
+
+// fillEvent records an order's transition after trading against trade
+func fillEvent(order *models.Order, remainingBefore float64, trade *models.Trade) *models.OrderEvent {
+	event := &models.OrderEvent{
+		OrderID:         order.ID,
+		Type:            models.OrderEventPartiallyFilled,
+		StatusBefore:    models.OrderStatusOpen,
+		StatusAfter:     models.OrderStatusOpen,
+		RemainingBefore: remainingBefore,
+		RemainingAfter:  order.RemainingQuantity,
+		TradeID:         &trade.ID,
+		Cause:           "trade",
+		Actor:           models.ActorEngine,
+	}
+	if order.RemainingQuantity == 0 {
+		event.Type = models.OrderEventFilled
+		event.StatusAfter = models.OrderStatusFilled
+	}
+	return event
+}
+
+// cancelEvent records an open order being canceled with remainingBefore
+// still unfilled
+func cancelEvent(order *models.Order, remainingBefore float64, cause, actor string) *models.OrderEvent {
+	return &models.OrderEvent{
+		OrderID:         order.ID,
+		Type:            models.OrderEventCanceled,
+		StatusBefore:    models.OrderStatusOpen,
+		StatusAfter:     models.OrderStatusCanceled,
+		RemainingBefore: remainingBefore,
+		RemainingAfter:  order.RemainingQuantity,
+		Cause:           cause,
+		Actor:           actor,
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/models"
+)
+
+// positionKeeper updates account positions as trades execute. Its repository
+// must share the transaction that creates the trades.
+type positionKeeper struct {
+	method models.CostMethod
+	repo   database.PositionStore
+}
+
+func newPositionKeeper(method models.CostMethod, repo database.PositionStore) *positionKeeper {
+	return &positionKeeper{
+		method: method,
+		repo:   repo,
+	}
+}
+
+// trade applies trade to the positions of the buying and selling accounts.
+// Orders without an account are not tracked.
+func (k *positionKeeper) trade(ctx context.Context, trade *models.Trade, buyAccount, sellAccount string) error {
+	if buyAccount != "" {
+		if err := k.apply(ctx, buyAccount, trade.Symbol, trade.Quantity, trade.Price); err != nil {
+			return err
+		}
+	}
+	if sellAccount != "" {
+		if err := k.apply(ctx, sellAccount, trade.Symbol, -trade.Quantity, trade.Price); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuild recomputes one account's position in symbol by replaying its live
+// trades, after a trade has been busted or corrected
+func (k *positionKeeper) rebuild(ctx context.Context, accountID, symbol string) error {
+	fills, err := k.repo.GetAccountFills(ctx, accountID, symbol)
+	if err != nil {
+		return err
+	}
+
+	position := &models.Position{AccountID: accountID, Symbol: symbol}
+	var lots []*models.PositionLot
+	for _, fill := range fills {
+		lots = applyFill(position, lots, k.method, fill.Quantity, fill.Price)
+	}
+
+	if err := k.repo.DeleteLots(ctx, accountID, symbol); err != nil {
+		return err
+	}
+	for _, lot := range lots {
+		lot.ID = 0
+		if err := k.repo.CreateLot(ctx, lot); err != nil {
+			return err
+		}
+	}
+
+	return k.repo.SavePosition(ctx, position)
+}
+
+// apply adds a signed fill of quantity at price to one account's position
+// and persists the position and any lots it opened, reduced or closed
+func (k *positionKeeper) apply(ctx context.Context, accountID, symbol string, quantity, price float64) error {
+	position, err := k.repo.GetPosition(ctx, accountID, symbol)
+	if err != nil {
+		return err
+	}
+	lots, err := k.repo.GetLots(ctx, accountID, symbol)
+	if err != nil {
+		return err
+	}
+
+	before := make(map[int64]float64, len(lots))
+	for _, lot := range lots {
+		before[lot.ID] = lot.Quantity
+	}
+
+	for _, lot := range applyFill(position, lots, k.method, quantity, price) {
+		if lot.ID == 0 {
+			if err := k.repo.CreateLot(ctx, lot); err != nil {
+				return err
+			}
+			continue
+		}
+		if lot.Quantity != before[lot.ID] {
+			if err := k.repo.UpdateLotQuantity(ctx, lot.ID, lot.Quantity); err != nil {
+				return err
+			}
+		}
+		delete(before, lot.ID)
+	}
+
+	// Lots no longer open were closed by this fill
+	for id := range before {
+		if err := k.repo.DeleteLot(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return k.repo.SavePosition(ctx, position)
+}
+
+// applyFill updates position for a signed fill of quantity at price and
+// returns its open lots afterwards. The part of the fill opposite to the
+// position closes lots oldest first and realizes PnL against either the
+// average cost or those lots; the rest extends the position or, after a
+// reversal, opens it on the other side.
+func applyFill(position *models.Position, lots []*models.PositionLot, method models.CostMethod, quantity, price float64) []*models.PositionLot {
+	held := models.ToUnits(position.Quantity)
+	fill := models.ToUnits(quantity)
+
+	if held != 0 && (held > 0) != (fill > 0) {
+		closing := min(abs(fill), abs(held))
+		direction := 1.0 // PnL per unit of price rise
+		if held < 0 {
+			direction = -1
+		}
+
+		var lotsPnL float64
+		var uncovered int64
+		lots, lotsPnL, uncovered = closeLots(lots, closing, price, direction)
+
+		if method == models.CostMethodFIFO {
+			// Quantity without lots, e.g. from before the method changed, is
+			// closed against the average cost
+			position.RealizedPnL += lotsPnL + models.FromUnits(uncovered)*(price-position.AverageCost)*direction
+		} else {
+			position.RealizedPnL += models.FromUnits(closing) * (price - position.AverageCost) * direction
+		}
+
+		if held > 0 {
+			held -= closing
+			fill += closing
+		} else {
+			held += closing
+			fill -= closing
+		}
+	}
+
+	if fill != 0 {
+		lots = append(lots, &models.PositionLot{
+			AccountID: position.AccountID,
+			Symbol:    position.Symbol,
+			Quantity:  models.FromUnits(fill),
+			Price:     price,
+		})
+		if method != models.CostMethodFIFO {
+			h, f := float64(abs(held)), float64(abs(fill))
+			position.AverageCost = (h*position.AverageCost + f*price) / (h + f)
+		}
+		held += fill
+	}
+
+	position.Quantity = models.FromUnits(held)
+	switch {
+	case held == 0:
+		position.AverageCost = 0
+	case method == models.CostMethodFIFO:
+		position.AverageCost = lotAverage(lots)
+	}
+
+	return lots
+}
+
+// closeLots consumes closing units from the oldest lots first. It returns the
+// lots still open, the PnL realized against them and any units left over
+// once every lot is consumed.
+func closeLots(lots []*models.PositionLot, closing int64, price, direction float64) ([]*models.PositionLot, float64, int64) {
+	var pnl float64
+	for len(lots) > 0 && closing > 0 {
+		lot := lots[0]
+		units := abs(models.ToUnits(lot.Quantity))
+		take := min(units, closing)
+
+		pnl += models.FromUnits(take) * (price - lot.Price) * direction
+		closing -= take
+		if take == units {
+			lots = lots[1:]
+			continue
+		}
+		lot.Quantity = models.FromUnits(units-take) * direction
+	}
+	return lots, pnl, closing
+}
+
+// lotAverage returns the quantity-weighted average price of lots
+func lotAverage(lots []*models.PositionLot) float64 {
+	var quantity, notional float64
+	for _, lot := range lots {
+		q := float64(abs(models.ToUnits(lot.Quantity)))
+		quantity += q
+		notional += q * lot.Price
+	}
+	if quantity == 0 {
+		return 0
+	}
+	return notional / quantity
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"math"
+
+	"order-matching-system/internal/models"
+)
+
+// protectionLimit returns the worst price a market order may trade at: the
+// best opposite price at arrival moved by the tightest of the instrument's
+// percentage band, its tick band and the order's own max slippage. ok is
+// false when none of them is configured.
+func protectionLimit(order *models.Order, instrument *models.Instrument, bestPrice float64) (limit float64, ok bool) {
+	distance := math.Inf(1)
+
+	if instrument.ProtectionBandPercent > 0 {
+		distance = math.Min(distance, bestPrice*instrument.ProtectionBandPercent/100)
+	}
+	if instrument.ProtectionBandTicks > 0 && instrument.TickSize > 0 {
+		distance = math.Min(distance, float64(instrument.ProtectionBandTicks)*instrument.TickSize)
+	}
+	if order.MaxSlippage > 0 {
+		distance = math.Min(distance, order.MaxSlippage)
+	}
+
+	if math.IsInf(distance, 1) {
+		return 0, false
+	}
+
+	if order.Side == models.OrderSideBuy {
+		return bestPrice + distance, true
+	}
+	return bestPrice - distance, true
+}
+
+// beyondLimit reports whether an order on side trading at price would breach
+// its protection limit
+func beyondLimit(side models.OrderSide, price, limit float64) bool {
+	if side == models.OrderSideBuy {
+		return price > limit
+	}
+	return price < limit
+}
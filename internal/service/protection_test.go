@@ -0,0 +1,127 @@
+package service
+
+import (
+	"testing"
+
+	"order-matching-system/internal/models"
+)
+
+func TestProtectionLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		side       models.OrderSide
+		slippage   float64
+		instrument models.Instrument
+		want       float64
+		wantOK     bool
+	}{
+		{"no band", models.OrderSideBuy, 0, models.Instrument{TickSize: 0.01}, 0, false},
+		{"zero bands", models.OrderSideSell, 0, models.Instrument{TickSize: 0.01, ProtectionBandPercent: 0, ProtectionBandTicks: 0}, 0, false},
+		{"ticks without a tick size", models.OrderSideBuy, 0, models.Instrument{ProtectionBandTicks: 5}, 0, false},
+		{"buy percent", models.OrderSideBuy, 0, models.Instrument{ProtectionBandPercent: 2}, 102, true},
+		{"sell percent", models.OrderSideSell, 0, models.Instrument{ProtectionBandPercent: 2}, 98, true},
+		{"buy ticks", models.OrderSideBuy, 0, models.Instrument{TickSize: 0.25, ProtectionBandTicks: 4}, 101, true},
+		{"sell ticks", models.OrderSideSell, 0, models.Instrument{TickSize: 0.25, ProtectionBandTicks: 4}, 99, true},
+		{"tighter of percent and ticks", models.OrderSideBuy, 0, models.Instrument{ProtectionBandPercent: 5, TickSize: 0.5, ProtectionBandTicks: 3}, 101.5, true},
+		{"slippage alone", models.OrderSideSell, 3, models.Instrument{}, 97, true},
+		{"slippage tighter than the band", models.OrderSideBuy, 0.5, models.Instrument{ProtectionBandPercent: 2}, 100.5, true},
+		{"band tighter than slippage", models.OrderSideSell, 5, models.Instrument{ProtectionBandPercent: 2}, 98, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{Side: tt.side, Type: models.OrderTypeMarket, MaxSlippage: tt.slippage}
+			got, ok := protectionLimit(order, &tt.instrument, 100)
+			if ok != tt.wantOK || !near(got, tt.want) {
+				t.Fatalf("protectionLimit = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestBeyondLimit(t *testing.T) {
+	tests := []struct {
+		side  models.OrderSide
+		price float64
+		want  bool
+	}{
+		{models.OrderSideBuy, 101, false},
+		{models.OrderSideBuy, 102, false},
+		{models.OrderSideBuy, 102.01, true},
+		{models.OrderSideSell, 103, false},
+		{models.OrderSideSell, 102, false},
+		{models.OrderSideSell, 101.99, true},
+	}
+
+	for _, tt := range tests {
+		if got := beyondLimit(tt.side, tt.price, 102); got != tt.want {
+			t.Errorf("beyondLimit(%s, %v, 102) = %v, want %v", tt.side, tt.price, got, tt.want)
+		}
+	}
+}
+
+func TestMarketOrderProtection(t *testing.T) {
+	tests := []struct {
+		name       string
+		instrument models.Instrument
+		quantity   float64
+		wantFilled float64
+		wantReason string
+	}{
+		{
+			name:       "band hit mid-sweep",
+			instrument: models.Instrument{TickSize: 1, ProtectionBandTicks: 2},
+			quantity:   10,
+			wantFilled: 3, // 100 and 101, stopping before 105
+			wantReason: "price protection limit 102 reached",
+		},
+		{
+			name:       "book exhausted inside the band",
+			instrument: models.Instrument{TickSize: 1, ProtectionBandPercent: 10},
+			quantity:   10,
+			wantFilled: 6,
+			wantReason: "insufficient liquidity",
+		},
+		{
+			name:       "no band",
+			instrument: models.Instrument{TickSize: 1},
+			quantity:   10,
+			wantFilled: 6,
+			wantReason: "insufficient liquidity",
+		},
+		{
+			name:       "filled before the band",
+			instrument: models.Instrument{TickSize: 1, ProtectionBandTicks: 2},
+			quantity:   2,
+			wantFilled: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			me, store := newTestEngine(t)
+			instrument := tt.instrument
+			instrument.Symbol = "TEST"
+			store.SetInstrument(&instrument)
+
+			placeOrder(t, me, "acct-s", models.OrderSideSell, 100, 1)
+			placeOrder(t, me, "acct-s", models.OrderSideSell, 101, 2)
+			placeOrder(t, me, "acct-s", models.OrderSideSell, 105, 3)
+
+			order := placeOrder(t, me, "acct-b", models.OrderSideBuy, 0, tt.quantity)
+			if order.FilledQuantity != tt.wantFilled {
+				t.Fatalf("filled %v, want %v", order.FilledQuantity, tt.wantFilled)
+			}
+			if order.StatusReason != tt.wantReason {
+				t.Fatalf("status reason %q, want %q", order.StatusReason, tt.wantReason)
+			}
+			wantStatus := models.OrderStatusCanceled
+			if tt.wantReason == "" {
+				wantStatus = models.OrderStatusFilled
+			}
+			if order.Status != wantStatus {
+				t.Fatalf("order is %s, want %s", order.Status, wantStatus)
+			}
+		})
+	}
+}
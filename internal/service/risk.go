@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/models"
+)
+
+// RiskLimits configures the pre-trade risk checks run on every order before
+// it is matched. A zero limit disables its check. Checks on open orders,
+// position, daily loss and order rate apply per account and are skipped for
+// orders without an account.
+type RiskLimits struct {
+	MaxOrderQuantity   float64
+	MaxOrderNotional   float64 // Market orders are valued at the last trade price
+	PriceCollarPercent float64 // Max distance of a limit price from the last trade price
+	MaxOpenOrders      int     // Per account and symbol
+	MaxPosition        float64 // Absolute net position per account and symbol
+	MaxDailyLoss       float64 // Per account across symbols, since local midnight
+	MaxOrderRate       int     // Orders per account per OrderRateWindow
+	OrderRateWindow    time.Duration
+}
+
+// riskRejection is the outcome of a failed risk check
+type riskRejection struct {
+	code   models.RejectCode
+	reason string
+}
+
+func reject(code models.RejectCode, format string, args ...interface{}) *riskRejection {
+	return &riskRejection{code: code, reason: fmt.Sprintf(format, args...)}
+}
+
+// riskChecker evaluates RiskLimits for one order against the state seen by
+// the matching transaction
+type riskChecker struct {
+	limits RiskLimits
+	risk   database.RiskStore
+	orders database.OrderStore
+	trades database.TradeStore
+
+	lastPrice *float64 // Loaded on first use
+}
+
+func newRiskChecker(limits RiskLimits, tx database.Tx) *riskChecker {
+	return &riskChecker{
+		limits: limits,
+		risk:   tx.Risk(),
+		orders: tx.Orders(),
+		trades: tx.Trades(),
+	}
+}
+
+type riskCheck func(context.Context, *models.Order) (*riskRejection, error)
+
+// check runs every enabled check in turn and returns the first that fails,
+// or nil if the order may proceed
+func (rc *riskChecker) check(ctx context.Context, order *models.Order) (*riskRejection, error) {
+	return rc.run(ctx, order,
+		rc.checkQuantity,
+		rc.checkNotional,
+		rc.checkPriceCollar,
+		rc.checkOrderRate,
+		rc.checkOpenOrders,
+		rc.checkPosition,
+		rc.checkDailyLoss,
+	)
+}
+
+// checkAmendment runs the checks that depend on an order's price and size
+// against a resting order as it would be after an amendment
+func (rc *riskChecker) checkAmendment(ctx context.Context, order *models.Order) (*riskRejection, error) {
+	return rc.run(ctx, order,
+		rc.checkQuantity,
+		rc.checkNotional,
+		rc.checkPriceCollar,
+		rc.checkPosition,
+	)
+}
+
+func (rc *riskChecker) run(ctx context.Context, order *models.Order, checks ...riskCheck) (*riskRejection, error) {
+	for _, check := range checks {
+		rejection, err := check(ctx, order)
+		if err != nil || rejection != nil {
+			return rejection, err
+		}
+	}
+	return nil, nil
+}
+
+func (rc *riskChecker) checkQuantity(ctx context.Context, order *models.Order) (*riskRejection, error) {
+	limit := rc.limits.MaxOrderQuantity
+	if limit > 0 && order.InitialQuantity > limit {
+		return reject(models.RejectQuantityLimit, "quantity %g exceeds maximum %g", order.InitialQuantity, limit), nil
+	}
+	return nil, nil
+}
+
+func (rc *riskChecker) checkNotional(ctx context.Context, order *models.Order) (*riskRejection, error) {
+	limit := rc.limits.MaxOrderNotional
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	price := order.Price
+	if order.Type == models.OrderTypeMarket {
+		var err error
+		price, err = rc.getMarketPrice(ctx, order)
+		if err != nil {
+			return nil, err
+		}
+		if price == 0 {
+			return reject(models.RejectNotionalLimit, "no reference price to value a market order in %s", order.Symbol), nil
+		}
+	}
+
+	if notional := price * order.InitialQuantity; notional > limit {
+		return reject(models.RejectNotionalLimit, "notional %g exceeds maximum %g", notional, limit), nil
+	}
+	return nil, nil
+}
+
+func (rc *riskChecker) checkPriceCollar(ctx context.Context, order *models.Order) (*riskRejection, error) {
+	percent := rc.limits.PriceCollarPercent
+	if percent <= 0 || order.Type != models.OrderTypeLimit {
+		return nil, nil
+	}
+
+	last, err := rc.getLastPrice(ctx, order.Symbol)
+	if err != nil || last == 0 {
+		return nil, err
+	}
+
+	low, high := last*(1-percent/100), last*(1+percent/100)
+	if order.Price < low || order.Price > high {
+		return reject(models.RejectPriceCollar, "price %g is outside the collar %g-%g around last trade %g", order.Price, low, high, last), nil
+	}
+	return nil, nil
+}
+
+func (rc *riskChecker) checkOrderRate(ctx context.Context, order *models.Order) (*riskRejection, error) {
+	limit, window := rc.limits.MaxOrderRate, rc.limits.OrderRateWindow
+	if limit <= 0 || window <= 0 || order.AccountID == "" {
+		return nil, nil
+	}
+
+	count, err := rc.risk.CountOrdersSince(ctx, order.AccountID, time.Now().Add(-window))
+	if err != nil {
+		return nil, err
+	}
+	if count >= limit {
+		return reject(models.RejectOrderRateLimit, "account submitted %d orders in the last %s, maximum %d", count, window, limit), nil
+	}
+	return nil, nil
+}
+
+func (rc *riskChecker) checkOpenOrders(ctx context.Context, order *models.Order) (*riskRejection, error) {
+	limit := rc.limits.MaxOpenOrders
+	if limit <= 0 || order.AccountID == "" {
+		return nil, nil
+	}
+
+	count, err := rc.risk.CountOpenOrders(ctx, order.AccountID, order.Symbol)
+	if err != nil {
+		return nil, err
+	}
+	if count >= limit {
+		return reject(models.RejectOpenOrdersLimit, "account has %d open orders in %s, maximum %d", count, order.Symbol, limit), nil
+	}
+	return nil, nil
+}
+
+// checkPosition rejects orders that would, if their unfilled quantity
+// traded, take the account's net position beyond the limit. The filled part
+// of an amended order is already in the position. Orders reducing the
+// position are always allowed.
+func (rc *riskChecker) checkPosition(ctx context.Context, order *models.Order) (*riskRejection, error) {
+	limit := rc.limits.MaxPosition
+	if limit <= 0 || order.AccountID == "" {
+		return nil, nil
+	}
+
+	position, err := rc.risk.GetNetPosition(ctx, order.AccountID, order.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	unfilled := models.FromUnits(models.ToUnits(order.InitialQuantity) - models.ToUnits(order.FilledQuantity))
+	projected := position + unfilled
+	if order.Side == models.OrderSideSell {
+		projected = position - unfilled
+	}
+	if math.Abs(projected) > limit && math.Abs(projected) > math.Abs(position) {
+		return reject(models.RejectPositionLimit, "position would become %g, maximum %g", projected, limit), nil
+	}
+	return nil, nil
+}
+
+func (rc *riskChecker) checkDailyLoss(ctx context.Context, order *models.Order) (*riskRejection, error) {
+	limit := rc.limits.MaxDailyLoss
+	if limit <= 0 || order.AccountID == "" {
+		return nil, nil
+	}
+
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	pnl, err := rc.risk.GetTradingPnLSince(ctx, order.AccountID, midnight)
+	if err != nil {
+		return nil, err
+	}
+	if -pnl >= limit {
+		return reject(models.RejectDailyLossLimit, "daily loss %g has reached the maximum %g", -pnl, limit), nil
+	}
+	return nil, nil
+}
+
+// getMarketPrice returns the price a market order is valued at: the last
+// trade price, or before the first trade the best opposite limit price. It
+// returns zero if there is neither.
+func (rc *riskChecker) getMarketPrice(ctx context.Context, order *models.Order) (float64, error) {
+	last, err := rc.getLastPrice(ctx, order.Symbol)
+	if err != nil || last > 0 {
+		return last, err
+	}
+
+	resting, err := rc.orders.GetMatchingOrders(ctx, order.Symbol, order.Side)
+	if err != nil {
+		return 0, err
+	}
+	for _, o := range resting {
+		if o.Type == models.OrderTypeLimit {
+			return o.Price, nil
+		}
+	}
+	return 0, nil
+}
+
+func (rc *riskChecker) getLastPrice(ctx context.Context, symbol string) (float64, error) {
+	if rc.lastPrice == nil {
+		price, err := rc.trades.GetLastPrice(ctx, symbol)
+		if err != nil {
+			return 0, err
+		}
+		rc.lastPrice = &price
+	}
+	return *rc.lastPrice, nil
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+)
+
+func TestRiskChecks(t *testing.T) {
+	tests := []struct {
+		name   string
+		limits RiskLimits
+		setup  func(t *testing.T, me *MatchingEngine)
+		side   models.OrderSide
+		price  float64 // Zero for a market order
+		qty    float64
+		want   models.RejectCode // Empty if the order should be accepted
+	}{
+		{
+			name:   "quantity",
+			limits: RiskLimits{MaxOrderQuantity: 10},
+			side:   models.OrderSideBuy,
+			price:  100,
+			qty:    11,
+			want:   models.RejectQuantityLimit,
+		},
+		{
+			name:   "notional",
+			limits: RiskLimits{MaxOrderNotional: 1000},
+			side:   models.OrderSideBuy,
+			price:  100,
+			qty:    11,
+			want:   models.RejectNotionalLimit,
+		},
+		{
+			name:   "market notional at last price",
+			limits: RiskLimits{MaxOrderNotional: 1000},
+			setup: func(t *testing.T, me *MatchingEngine) {
+				placeOrder(t, me, "other-s", models.OrderSideSell, 200, 5)
+				placeOrder(t, me, "other-b", models.OrderSideBuy, 200, 1)
+			},
+			side: models.OrderSideBuy,
+			qty:  6,
+			want: models.RejectNotionalLimit,
+		},
+		{
+			name:   "market notional at best opposite price",
+			limits: RiskLimits{MaxOrderNotional: 1000},
+			setup: func(t *testing.T, me *MatchingEngine) {
+				placeOrder(t, me, "other-s", models.OrderSideSell, 200, 5)
+			},
+			side: models.OrderSideBuy,
+			qty:  6,
+			want: models.RejectNotionalLimit,
+		},
+		{
+			name:   "market notional without a reference price",
+			limits: RiskLimits{MaxOrderNotional: 1000},
+			side:   models.OrderSideSell,
+			qty:    1,
+			want:   models.RejectNotionalLimit,
+		},
+		{
+			name:   "price collar",
+			limits: RiskLimits{PriceCollarPercent: 5},
+			setup: func(t *testing.T, me *MatchingEngine) {
+				placeOrder(t, me, "other-s", models.OrderSideSell, 100, 1)
+				placeOrder(t, me, "other-b", models.OrderSideBuy, 100, 1)
+			},
+			side:  models.OrderSideSell,
+			price: 106,
+			qty:   1,
+			want:  models.RejectPriceCollar,
+		},
+		{
+			name:   "order rate",
+			limits: RiskLimits{MaxOrderRate: 2, OrderRateWindow: time.Minute},
+			setup: func(t *testing.T, me *MatchingEngine) {
+				placeOrder(t, me, "acct", models.OrderSideBuy, 99, 1)
+				placeOrder(t, me, "acct", models.OrderSideBuy, 98, 1)
+			},
+			side:  models.OrderSideBuy,
+			price: 97,
+			qty:   1,
+			want:  models.RejectOrderRateLimit,
+		},
+		{
+			name:   "rejected orders do not count toward the rate",
+			limits: RiskLimits{MaxOrderRate: 2, OrderRateWindow: time.Minute, MaxOrderQuantity: 10},
+			setup: func(t *testing.T, me *MatchingEngine) {
+				placeOrder(t, me, "acct", models.OrderSideBuy, 99, 1)
+				placeOrder(t, me, "acct", models.OrderSideBuy, 98, 20)
+			},
+			side:  models.OrderSideBuy,
+			price: 97,
+			qty:   1,
+		},
+		{
+			name:   "open orders",
+			limits: RiskLimits{MaxOpenOrders: 2},
+			setup: func(t *testing.T, me *MatchingEngine) {
+				placeOrder(t, me, "acct", models.OrderSideBuy, 99, 1)
+				placeOrder(t, me, "acct", models.OrderSideSell, 101, 1)
+			},
+			side:  models.OrderSideBuy,
+			price: 98,
+			qty:   1,
+			want:  models.RejectOpenOrdersLimit,
+		},
+		{
+			name:   "position",
+			limits: RiskLimits{MaxPosition: 5},
+			setup: func(t *testing.T, me *MatchingEngine) {
+				placeOrder(t, me, "other", models.OrderSideSell, 100, 4)
+				placeOrder(t, me, "acct", models.OrderSideBuy, 100, 4)
+			},
+			side:  models.OrderSideBuy,
+			price: 99,
+			qty:   2,
+			want:  models.RejectPositionLimit,
+		},
+		{
+			name:   "position reduced",
+			limits: RiskLimits{MaxPosition: 5},
+			setup: func(t *testing.T, me *MatchingEngine) {
+				placeOrder(t, me, "other", models.OrderSideSell, 100, 4)
+				placeOrder(t, me, "acct", models.OrderSideBuy, 100, 4)
+			},
+			side:  models.OrderSideSell,
+			price: 101,
+			qty:   8,
+		},
+		{
+			name:   "daily loss",
+			limits: RiskLimits{MaxDailyLoss: 10},
+			setup: func(t *testing.T, me *MatchingEngine) {
+				placeOrder(t, me, "other", models.OrderSideSell, 100, 1)
+				placeOrder(t, me, "acct", models.OrderSideBuy, 100, 1)
+				placeOrder(t, me, "other-s", models.OrderSideSell, 80, 1)
+				placeOrder(t, me, "other-b", models.OrderSideBuy, 80, 1)
+			},
+			side:  models.OrderSideBuy,
+			price: 79,
+			qty:   1,
+			want:  models.RejectDailyLossLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Rate and daily loss windows are measured from the wall clock
+			me := NewMatchingEngineWithStore(memory.NewStore(nil))
+			me.SetRiskLimits(tt.limits)
+			if tt.setup != nil {
+				tt.setup(t, me)
+			}
+
+			order := placeOrder(t, me, "acct", tt.side, tt.price, tt.qty)
+			if tt.want == "" {
+				if order.Status == models.OrderStatusRejected {
+					t.Fatalf("rejected with %s: %s", order.RejectCode, order.StatusReason)
+				}
+				return
+			}
+			if order.Status != models.OrderStatusRejected || order.RejectCode != tt.want {
+				t.Fatalf("order is %s with code %q, want rejected with %q", order.Status, order.RejectCode, tt.want)
+			}
+			if order.StatusReason == "" || order.RemainingQuantity != 0 {
+				t.Fatalf("rejected order has reason %q and %v remaining", order.StatusReason, order.RemainingQuantity)
+			}
+		})
+	}
+}
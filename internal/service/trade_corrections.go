@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"order-matching-system/internal/database"
+	"order-matching-system/internal/models"
+)
+
+var (
+	// ErrTradeNotFound is returned when busting or correcting an unknown trade
+	ErrTradeNotFound = errors.New("trade not found")
+
+	// ErrTradeBusted is returned when busting or correcting a trade that has
+	// already been busted
+	ErrTradeBusted = errors.New("trade has already been busted")
+
+	// ErrInvalidCorrection is returned for corrections that change nothing or
+	// would fill an order beyond its quantity
+	ErrInvalidCorrection = errors.New("invalid trade correction")
+)
+
+// BustTrade voids a trade on behalf of actor. The trade is kept and marked
+// busted, its quantity is returned to both orders, and candles and positions
+// are recomputed without it.
+func (me *MatchingEngine) BustTrade(ctx context.Context, tradeID int, reason, actor string) (*models.Trade, *models.TradeCorrection, error) {
+	return me.amendTrade(ctx, tradeID, models.TradeBust, 0, 0, reason, actor)
+}
+
+// CorrectTrade amends a trade's price and quantity on behalf of actor; a zero
+// price or quantity keeps the current value. Both orders' fills, candles and
+// positions are adjusted to match.
+func (me *MatchingEngine) CorrectTrade(ctx context.Context, tradeID int, price, quantity float64, reason, actor string) (*models.Trade, *models.TradeCorrection, error) {
+	return me.amendTrade(ctx, tradeID, models.TradeCorrect, price, quantity, reason, actor)
+}
+
+func (me *MatchingEngine) amendTrade(ctx context.Context, tradeID int, action models.TradeCorrectionAction, price, quantity float64, reason, actor string) (*models.Trade, *models.TradeCorrection, error) {
+	if !me.enter() {
+		return nil, nil, ErrEngineStopped
+	}
+	defer me.inFlight.Done()
+
+	if err := me.lockOrderBook(ctx); err != nil {
+		return nil, nil, err
+	}
+	defer me.unlockOrderBook()
+
+	tx, err := me.store.Begin(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	otx := newOrderTx(tx, me.costMethod)
+
+	trade, err := otx.trades.GetTradeByID(ctx, tradeID)
+	if err != nil {
+		if errors.Is(err, database.ErrTradeNotFound) {
+			return nil, nil, ErrTradeNotFound
+		}
+		return nil, nil, err
+	}
+	if trade.Busted {
+		return nil, nil, ErrTradeBusted
+	}
+
+	correction := &models.TradeCorrection{
+		TradeID:          trade.ID,
+		Action:           action,
+		OriginalPrice:    trade.Price,
+		OriginalQuantity: trade.Quantity,
+		Reason:           reason,
+		Actor:            actor,
+	}
+	if action == models.TradeCorrect {
+		if price == 0 {
+			price = trade.Price
+		}
+		if quantity == 0 {
+			quantity = trade.Quantity
+		}
+		if price == trade.Price && quantity == trade.Quantity {
+			return nil, nil, fmt.Errorf("%w: price and quantity are unchanged", ErrInvalidCorrection)
+		}
+		correction.NewPrice = price
+		correction.NewQuantity = quantity
+	}
+
+	// Adjust the fills of both orders before the trade itself changes
+	var accounts []string
+	for _, orderID := range []int{trade.BuyOrderID, trade.SellOrderID} {
+		order, err := otx.orders.GetOrderByID(ctx, orderID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		event, err := adjustFill(order, trade, correction)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := otx.orders.UpdateOrderExecution(ctx, order); err != nil {
+			return nil, nil, fmt.Errorf("failed to update order: %w", err)
+		}
+		if err := otx.recorder.orderEvent(ctx, order, event); err != nil {
+			return nil, nil, err
+		}
+
+		if order.AccountID != "" {
+			accounts = append(accounts, order.AccountID)
+		}
+	}
+
+	if action == models.TradeBust {
+		trade.Busted = true
+	} else {
+		trade.Price = correction.NewPrice
+		trade.Quantity = correction.NewQuantity
+		trade.Corrected = true
+	}
+	if err := otx.trades.UpdateTrade(ctx, trade); err != nil {
+		return nil, nil, err
+	}
+	if err := otx.trades.CreateCorrection(ctx, correction); err != nil {
+		return nil, nil, err
+	}
+
+	// Recompute everything derived from the trade
+	if err := rebuildBars(ctx, otx, trade); err != nil {
+		return nil, nil, err
+	}
+	for i, account := range accounts {
+		if i > 0 && account == accounts[0] {
+			continue // Both sides belong to one account
+		}
+		if err := otx.positions.rebuild(ctx, account, trade.Symbol); err != nil {
+			return nil, nil, fmt.Errorf("failed to rebuild position: %w", err)
+		}
+	}
+
+	if err := otx.recorder.tradeCorrection(ctx, trade, correction); err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	me.feed.publish(trade.Symbol, nil)
+	slog.InfoContext(ctx, "trade corrected", "trade_id", trade.ID, "symbol", trade.Symbol, "action", action,
+		"buy_order_id", trade.BuyOrderID, "sell_order_id", trade.SellOrderID, "reason", reason, "actor", actor)
+
+	return trade, correction, nil
+}
+
+// adjustFill moves order's fill summary from the trade's original values to
+// the corrected ones, or removes the trade entirely for a bust. Open orders
+// keep resting with the restored quantity; orders no longer in the book end
+// filled if the new fills cover them and canceled otherwise. A filled order
+// is not put back in the book, where the restored quantity could cross it or
+// take priority over later orders, so it is canceled with a reason naming
+// the trade.
+func adjustFill(order *models.Order, trade *models.Trade, correction *models.TradeCorrection) (*models.OrderEvent, error) {
+	filled := models.ToUnits(order.FilledQuantity) - models.ToUnits(trade.Quantity) + models.ToUnits(correction.NewQuantity)
+	if filled > models.ToUnits(order.InitialQuantity) {
+		return nil, fmt.Errorf("%w: order %d would be filled beyond its quantity", ErrInvalidCorrection, order.ID)
+	}
+
+	event := &models.OrderEvent{
+		OrderID:         order.ID,
+		Type:            models.OrderEventTradeCorrected,
+		StatusBefore:    order.Status,
+		RemainingBefore: order.RemainingQuantity,
+		TradeID:         &trade.ID,
+		Cause:           correction.Reason,
+		Actor:           correction.Actor,
+	}
+	outcome := "corrected"
+	if correction.Action == models.TradeBust {
+		event.Type = models.OrderEventTradeBusted
+		outcome = "busted"
+	}
+
+	notional := order.AverageFillPrice*order.FilledQuantity - trade.Price*trade.Quantity + correction.NewPrice*correction.NewQuantity
+	order.FilledQuantity = models.FromUnits(filled)
+	order.AverageFillPrice = 0
+	if filled > 0 {
+		order.AverageFillPrice = notional / order.FilledQuantity
+	} else {
+		order.LastFillPrice = 0
+		order.LastFillAt = nil
+	}
+	if order.LastFillPrice == trade.Price && correction.Action == models.TradeCorrect {
+		order.LastFillPrice = correction.NewPrice
+	}
+
+	order.RemainingQuantity = models.FromUnits(models.ToUnits(order.InitialQuantity) - filled)
+	switch {
+	case order.RemainingQuantity == 0:
+		order.Status = models.OrderStatusFilled
+	case order.Status == models.OrderStatusFilled:
+		order.Status = models.OrderStatusCanceled
+		order.StatusReason = fmt.Sprintf("trade %d %s after the order filled", trade.ID, outcome)
+	case order.Status != models.OrderStatusOpen:
+		order.Status = models.OrderStatusCanceled
+	}
+
+	event.StatusAfter = order.Status
+	event.RemainingAfter = order.RemainingQuantity
+	return event, nil
+}
+
+// rebuildBars recomputes the bar of every interval containing trade from the
+// live trades in it
+func rebuildBars(ctx context.Context, otx *orderTx, trade *models.Trade) error {
+	for _, interval := range models.CandleIntervals {
+		d, _ := interval.Duration()
+		openTime := interval.BucketStart(trade.CreatedAt)
+
+		trades, err := otx.trades.GetTradesInRange(ctx, trade.Symbol, openTime, openTime.Add(d))
+		if err != nil {
+			return err
+		}
+		if err := otx.candles.ReplaceBar(ctx, trade.Symbol, interval, openTime, trades); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestBustTrade(t *testing.T) {
+	me, store := newTestEngine(t)
+	ctx := context.Background()
+
+	first := placeOrder(t, me, "acct-s", models.OrderSideSell, 100, 1)
+	placeOrder(t, me, "acct-s", models.OrderSideSell, 101, 2)
+	buy := placeOrder(t, me, "acct-b", models.OrderSideBuy, 101, 3) // Trades 1 @ 100 and 2 @ 101
+	resting := placeOrder(t, me, "acct-s", models.OrderSideSell, 102, 4)
+	placeOrder(t, me, "acct-c", models.OrderSideBuy, 0, 1) // Trade 3, 1 @ 102
+
+	trade, correction, err := me.BustTrade(ctx, 1, "fat finger", "ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trade.Busted || correction.Action != models.TradeBust || correction.OriginalPrice != 100 || correction.Actor != "ops" {
+		t.Fatalf("unexpected bust %+v, %+v", trade, correction)
+	}
+
+	// The filled orders are not put back in the book
+	got := store.Order(buy.ID)
+	if got.FilledQuantity != 2 || got.RemainingQuantity != 1 || !near(got.AverageFillPrice, 101) {
+		t.Fatalf("buy filled %v at %v, remaining %v; want 2 at 101, 1 remaining", got.FilledQuantity, got.AverageFillPrice, got.RemainingQuantity)
+	}
+	if got.Status != models.OrderStatusCanceled || got.StatusReason != "trade 1 busted after the order filled" {
+		t.Fatalf("buy is %s (%q), want canceled naming the bust", got.Status, got.StatusReason)
+	}
+	got = store.Order(first.ID)
+	if got.FilledQuantity != 0 || got.AverageFillPrice != 0 || got.LastFillAt != nil || got.Status != models.OrderStatusCanceled {
+		t.Fatalf("unexpected sell after bust %+v", got)
+	}
+	events := store.Events(buy.ID)
+	if last := events[len(events)-1]; last.Type != models.OrderEventTradeBusted || last.Actor != "ops" || *last.TradeID != 1 {
+		t.Fatalf("last buy event %+v, want trade_busted by ops", last)
+	}
+
+	bar := store.Candle("TEST", models.CandleInterval1m, testTime)
+	if bar.TradeCount != 2 || bar.Open != 101 || bar.Low != 101 || bar.High != 102 || bar.Volume != 3 {
+		t.Fatalf("bar %+v, want trades 2 and 3 only", bar)
+	}
+	if position := store.Position("acct-b", "TEST"); position.Quantity != 2 || !near(position.AverageCost, 101) {
+		t.Fatalf("buyer position %v at %v, want 2 at 101", position.Quantity, position.AverageCost)
+	}
+	if position := store.Position("acct-s", "TEST"); position.Quantity != -3 {
+		t.Fatalf("seller position %v, want -3", position.Quantity)
+	}
+
+	// A partly filled order keeps resting with the quantity restored
+	if _, _, err := me.BustTrade(ctx, 3, "wrong symbol", "ops"); err != nil {
+		t.Fatal(err)
+	}
+	got = store.Order(resting.ID)
+	if got.Status != models.OrderStatusOpen || got.RemainingQuantity != 4 || got.FilledQuantity != 0 {
+		t.Fatalf("resting sell %+v, want open with 4 remaining", got)
+	}
+	if book := store.Book("TEST", models.BookLevel2, 1); len(book.Asks) != 1 || book.Asks[0].Quantity != 4 {
+		t.Fatalf("asks %+v, want 4 @ 102", book.Asks)
+	}
+	if position := store.Position("acct-c", "TEST"); position.Quantity != 0 {
+		t.Fatalf("position after bust %v, want flat", position.Quantity)
+	}
+
+	if _, _, err := me.BustTrade(ctx, 1, "again", "ops"); !errors.Is(err, ErrTradeBusted) {
+		t.Fatalf("second bust got %v, want ErrTradeBusted", err)
+	}
+	if _, _, err := me.BustTrade(ctx, 99, "unknown", "ops"); !errors.Is(err, ErrTradeNotFound) {
+		t.Fatalf("unknown trade got %v, want ErrTradeNotFound", err)
+	}
+}
+
+func TestBustTradeAtMinuteBoundary(t *testing.T) {
+	now := testTime
+	store := memory.NewStore(func() time.Time { return now })
+	me := NewMatchingEngineWithStore(store)
+
+	placeOrder(t, me, "acct-s", models.OrderSideSell, 100, 3)
+	placeOrder(t, me, "acct-b", models.OrderSideBuy, 100, 1) // Trade 1 at 09:30:00
+
+	// Half a second before the next minute, which a whole-second column
+	// would round up into the 09:31 bar
+	now = testTime.Add(59*time.Second + 600*time.Millisecond)
+	placeOrder(t, me, "acct-b", models.OrderSideBuy, 100, 1) // Trade 2
+	now = testTime.Add(70 * time.Second)
+	placeOrder(t, me, "acct-b", models.OrderSideBuy, 100, 1) // Trade 3 at 09:31:10
+
+	if _, _, err := me.BustTrade(context.Background(), 1, "fat finger", "ops"); err != nil {
+		t.Fatal(err)
+	}
+
+	if bar := store.Candle("TEST", models.CandleInterval1m, testTime); bar.TradeCount != 1 || bar.Volume != 1 {
+		t.Fatalf("09:30 bar %+v, want trade 2 only", bar)
+	}
+	if bar := store.Candle("TEST", models.CandleInterval1m, testTime.Add(time.Minute)); bar.TradeCount != 1 || bar.Volume != 1 {
+		t.Fatalf("09:31 bar %+v, want trade 3 only", bar)
+	}
+}
+
+func TestCorrectTrade(t *testing.T) {
+	me, store := newTestEngine(t)
+	ctx := context.Background()
+
+	sell := placeOrder(t, me, "acct-s", models.OrderSideSell, 100, 5)
+	buy := placeOrder(t, me, "acct-b", models.OrderSideBuy, 100, 2)
+
+	if _, _, err := me.CorrectTrade(ctx, 1, 0, 0, "nothing", "ops"); !errors.Is(err, ErrInvalidCorrection) {
+		t.Fatalf("unchanged correction got %v, want ErrInvalidCorrection", err)
+	}
+	if _, _, err := me.CorrectTrade(ctx, 1, 0, 3, "too much", "ops"); !errors.Is(err, ErrInvalidCorrection) {
+		t.Fatalf("overfilling correction got %v, want ErrInvalidCorrection", err)
+	}
+
+	trade, correction, err := me.CorrectTrade(ctx, 1, 101, 1, "agreed adjustment", "ops")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trade.Corrected || trade.Price != 101 || trade.Quantity != 1 || correction.OriginalQuantity != 2 {
+		t.Fatalf("unexpected correction %+v, %+v", trade, correction)
+	}
+
+	got := store.Order(buy.ID)
+	if got.FilledQuantity != 1 || got.AverageFillPrice != 101 || got.LastFillPrice != 101 {
+		t.Fatalf("buy filled %v at %v, want 1 at 101", got.FilledQuantity, got.AverageFillPrice)
+	}
+	if got.Status != models.OrderStatusCanceled || got.StatusReason != "trade 1 corrected after the order filled" {
+		t.Fatalf("buy is %s (%q), want canceled naming the correction", got.Status, got.StatusReason)
+	}
+	got = store.Order(sell.ID)
+	if got.Status != models.OrderStatusOpen || got.RemainingQuantity != 4 {
+		t.Fatalf("sell is %s with %v remaining, want open with 4", got.Status, got.RemainingQuantity)
+	}
+
+	bar := store.Candle("TEST", models.CandleInterval1h, models.CandleInterval1h.BucketStart(testTime))
+	if bar.TradeCount != 1 || bar.Close != 101 || bar.Volume != 1 || bar.VWAP != 101 {
+		t.Fatalf("bar %+v, want the corrected trade", bar)
+	}
+	if position := store.Position("acct-b", "TEST"); position.Quantity != 1 || position.AverageCost != 101 {
+		t.Fatalf("buyer position %v at %v, want 1 at 101", position.Quantity, position.AverageCost)
+	}
+	if position := store.Position("acct-s", "TEST"); position.Quantity != -1 {
+		t.Fatalf("seller position %v, want -1", position.Quantity)
+	}
+}
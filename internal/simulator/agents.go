@@ -0,0 +1,214 @@
+package simulator
+
+import (
+	"context"
+	"math"
+
+	"order-matching-system/internal/models"
+)
+
+// NoiseTrader places random orders around the fair value, supplying and
+// taking liquidity without a view of the market
+type NoiseTrader struct {
+	name        string
+	Rate        float64 // Chance of placing an order each step
+	MarketRatio float64 // Share of orders that are market orders
+	MaxOffset   int     // Limit prices fall up to this many ticks either side of fair value
+	MinQuantity float64
+	MaxQuantity float64
+	Lifetime    int // Steps a resting order lives before it is canceled
+
+	placedAt map[int]int // Step each resting order was placed
+}
+
+func NewNoiseTrader(name string) *NoiseTrader {
+	return &NoiseTrader{
+		name:        name,
+		Rate:        0.2,
+		MarketRatio: 0.2,
+		MaxOffset:   20,
+		MinQuantity: 1,
+		MaxQuantity: 20,
+		Lifetime:    50,
+		placedAt:    make(map[int]int),
+	}
+}
+
+func (a *NoiseTrader) Name() string { return a.name }
+
+func (a *NoiseTrader) Step(ctx context.Context, m *Market) error {
+	for _, order := range m.OpenOrders() {
+		if m.Step()-a.placedAt[order.ID] >= a.Lifetime {
+			if err := m.Cancel(ctx, order.ID); err != nil {
+				return err
+			}
+			delete(a.placedAt, order.ID)
+		}
+	}
+
+	rng := m.Rand()
+	if rng.Float64() >= a.Rate {
+		return nil
+	}
+
+	side := models.OrderSideBuy
+	if rng.Intn(2) == 0 {
+		side = models.OrderSideSell
+	}
+	quantity := math.Round(a.MinQuantity + rng.Float64()*(a.MaxQuantity-a.MinQuantity))
+
+	if rng.Float64() < a.MarketRatio {
+		_, err := m.PlaceMarket(ctx, side, quantity)
+		return err
+	}
+
+	offset := float64(rng.Intn(2*a.MaxOffset+1) - a.MaxOffset)
+	order, err := m.PlaceLimit(ctx, side, m.FairValue()+offset*m.TickSize(), quantity)
+	if err != nil {
+		return err
+	}
+	if order.Status == models.OrderStatusOpen {
+		a.placedAt[order.ID] = m.Step()
+	}
+	return nil
+}
+
+// MarketMaker quotes a bid and an ask around the fair value, shading both
+// against its inventory and requoting when the fair value moves or a quote
+// trades
+type MarketMaker struct {
+	name         string
+	HalfSpread   int     // Ticks from the quote center to each side
+	Size         float64 // Quantity quoted on each side
+	MaxInventory float64 // Absolute position beyond which a side is not quoted
+	SkewTicks    float64 // Ticks the quotes shift down when long MaxInventory, up when short
+	Requote      int     // Ticks the fair value may move before quotes are replaced
+
+	quotedAt float64 // Fair value at the last requote
+	quotes   int     // Quotes placed at the last requote
+}
+
+func NewMarketMaker(name string) *MarketMaker {
+	return &MarketMaker{
+		name:         name,
+		HalfSpread:   5,
+		Size:         10,
+		MaxInventory: 200,
+		SkewTicks:    5,
+		Requote:      2,
+	}
+}
+
+func (a *MarketMaker) Name() string { return a.name }
+
+func (a *MarketMaker) Step(ctx context.Context, m *Market) error {
+	tick := m.TickSize()
+	fair := m.FairValue()
+
+	open := m.OpenOrders()
+	traded := len(open) != a.quotes
+	for _, order := range open {
+		if order.FilledQuantity > 0 {
+			traded = true
+		}
+	}
+	if !traded && math.Abs(fair-a.quotedAt) < float64(a.Requote)*tick {
+		return nil
+	}
+
+	if err := m.CancelAll(ctx); err != nil {
+		return err
+	}
+
+	inventory := m.Position().Quantity
+	center := fair - a.SkewTicks*inventory/a.MaxInventory*tick
+	bid := math.Floor((center-float64(a.HalfSpread)*tick)/tick) * tick
+	ask := math.Ceil((center+float64(a.HalfSpread)*tick)/tick) * tick
+
+	a.quotedAt = fair
+	a.quotes = 0
+	if inventory+a.Size <= a.MaxInventory {
+		if err := a.quote(ctx, m, models.OrderSideBuy, bid); err != nil {
+			return err
+		}
+	}
+	if inventory-a.Size >= -a.MaxInventory {
+		if err := a.quote(ctx, m, models.OrderSideSell, ask); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quote places one side, counting it if it rests untouched
+func (a *MarketMaker) quote(ctx context.Context, m *Market, side models.OrderSide, price float64) error {
+	order, err := m.PlaceLimit(ctx, side, price, a.Size)
+	if err != nil {
+		return err
+	}
+	if order.Status == models.OrderStatusOpen {
+		a.quotes++
+	}
+	return nil
+}
+
+// MomentumTrader buys when the short moving average of trade prices rises
+// above the long one and sells when it falls below, with market orders
+type MomentumTrader struct {
+	name        string
+	Short       int     // Steps in the short average
+	Long        int     // Steps in the long average
+	Threshold   float64 // Relative gap between the averages needed to trade
+	Size        float64
+	MaxPosition float64
+
+	prices []float64
+}
+
+func NewMomentumTrader(name string) *MomentumTrader {
+	return &MomentumTrader{
+		name:        name,
+		Short:       10,
+		Long:        50,
+		Threshold:   0.001,
+		Size:        5,
+		MaxPosition: 100,
+	}
+}
+
+func (a *MomentumTrader) Name() string { return a.name }
+
+func (a *MomentumTrader) Step(ctx context.Context, m *Market) error {
+	price := m.LastPrice()
+	if price == 0 {
+		return nil
+	}
+	a.prices = append(a.prices, price)
+	if len(a.prices) > a.Long {
+		a.prices = a.prices[1:]
+	}
+	if len(a.prices) < a.Long {
+		return nil
+	}
+
+	short, long := average(a.prices[len(a.prices)-a.Short:]), average(a.prices)
+	position := m.Position().Quantity
+
+	switch {
+	case short > long*(1+a.Threshold) && position+a.Size <= a.MaxPosition:
+		_, err := m.PlaceMarket(ctx, models.OrderSideBuy, a.Size)
+		return err
+	case short < long*(1-a.Threshold) && position-a.Size >= -a.MaxPosition:
+		_, err := m.PlaceMarket(ctx, models.OrderSideSell, a.Size)
+		return err
+	}
+	return nil
+}
+
+func average(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
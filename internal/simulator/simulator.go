@@ -0,0 +1,316 @@
+// Package simulator runs a MatchingEngine on an in-memory store against
+// trading agents over simulated time, recording trades, book snapshots and
+// each agent's PnL.
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+	"order-matching-system/internal/service"
+)
+
+// Config describes a simulation. Runs with the same config and agents are
+// repeatable.
+type Config struct {
+	Symbol        string
+	Start         time.Time     // Simulated time of the first step
+	Steps         int           // Number of steps to run
+	StepDuration  time.Duration // Simulated time between steps
+	InitialPrice  float64       // Starting fair value
+	Volatility    float64       // Standard deviation of the fair value's log return per step
+	TickSize      float64
+	SnapshotEvery int // Steps between book and PnL snapshots
+	BookDepth     int // Price levels kept per side in snapshots
+	Seed          int64
+}
+
+func (c *Config) validate() error {
+	switch {
+	case c.Symbol == "":
+		return errors.New("symbol is required")
+	case c.Steps < 1:
+		return errors.New("steps must be at least 1")
+	case c.StepDuration <= 0:
+		return errors.New("step duration must be positive")
+	case c.InitialPrice <= 0 || c.TickSize <= 0:
+		return errors.New("initial price and tick size must be positive")
+	case c.Volatility < 0:
+		return errors.New("volatility must not be negative")
+	case c.SnapshotEvery < 1 || c.BookDepth < 1:
+		return errors.New("snapshot interval and book depth must be at least 1")
+	}
+	return nil
+}
+
+// Agent trades in the simulation. Step is called once per simulated step,
+// in a random order across agents, and acts through m.
+type Agent interface {
+	// Name identifies the agent and is the account its orders are placed under
+	Name() string
+	Step(ctx context.Context, m *Market) error
+}
+
+// Result is everything recorded during a simulation
+type Result struct {
+	Trades    []*Trade
+	Snapshots []*Snapshot
+	Agents    []*AgentPnL // Final PnL, in the order the agents were given
+}
+
+// Trade is an executed trade with the agents on each side
+type Trade struct {
+	*models.Trade
+	Step   int
+	Buyer  string
+	Seller string
+}
+
+// Snapshot is the state of the market at the end of a step
+type Snapshot struct {
+	Step      int
+	Time      time.Time
+	FairValue float64
+	LastPrice float64
+	Book      *models.OrderBook // Level 2, up to Config.BookDepth levels
+	PnL       []*AgentPnL
+}
+
+// AgentPnL is an agent's position and activity, marked to the last trade price
+type AgentPnL struct {
+	Agent         string
+	Position      float64
+	AverageCost   float64
+	RealizedPnL   float64
+	UnrealizedPnL float64
+	TotalPnL      float64
+	Orders        int     // Orders placed
+	Trades        int     // Trades the agent took part in
+	Volume        float64 // Quantity traded
+}
+
+// Simulator runs one simulation
+type Simulator struct {
+	cfg    Config
+	store  *memory.Store
+	engine *service.MatchingEngine
+	agents []Agent
+	rng    *rand.Rand
+
+	step        int
+	now         time.Time
+	fairValue   float64
+	owners      map[int]string // Agent that placed each order
+	stats       map[string]*AgentPnL
+	lastTradeID int
+	result      *Result
+}
+
+// New returns a simulator for cfg with a fresh store and engine
+func New(cfg Config, agents ...Agent) (*Simulator, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if len(agents) == 0 {
+		return nil, errors.New("at least one agent is required")
+	}
+
+	s := &Simulator{
+		cfg:       cfg,
+		agents:    agents,
+		rng:       rand.New(rand.NewSource(cfg.Seed)),
+		now:       cfg.Start,
+		fairValue: cfg.InitialPrice,
+		owners:    make(map[int]string),
+		stats:     make(map[string]*AgentPnL),
+		result:    &Result{},
+	}
+	for _, agent := range agents {
+		if _, dup := s.stats[agent.Name()]; dup {
+			return nil, fmt.Errorf("duplicate agent name %q", agent.Name())
+		}
+		s.stats[agent.Name()] = &AgentPnL{Agent: agent.Name()}
+	}
+
+	s.store = memory.NewStore(func() time.Time { return s.now })
+	instrument := models.DefaultInstrument(cfg.Symbol)
+	instrument.TickSize = cfg.TickSize
+	s.store.SetInstrument(instrument)
+	s.engine = service.NewMatchingEngineWithStore(s.store)
+
+	return s, nil
+}
+
+// Run steps the simulation to the end, or until ctx is done
+func (s *Simulator) Run(ctx context.Context) (*Result, error) {
+	order := make([]int, len(s.agents))
+	for i := range order {
+		order[i] = i
+	}
+
+	for s.step = 1; s.step <= s.cfg.Steps; s.step++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		s.now = s.cfg.Start.Add(time.Duration(s.step-1) * s.cfg.StepDuration)
+		s.fairValue *= math.Exp(s.cfg.Volatility * s.rng.NormFloat64())
+
+		s.rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		for _, i := range order {
+			agent := s.agents[i]
+			if err := agent.Step(ctx, &Market{sim: s, agent: agent.Name()}); err != nil {
+				return nil, fmt.Errorf("agent %s failed at step %d: %w", agent.Name(), s.step, err)
+			}
+		}
+
+		s.collectTrades()
+		if s.step%s.cfg.SnapshotEvery == 0 || s.step == s.cfg.Steps {
+			s.snapshot()
+		}
+	}
+
+	s.result.Agents = s.pnl()
+	return s.result, nil
+}
+
+// collectTrades records the trades made during the current step
+func (s *Simulator) collectTrades() {
+	for _, trade := range s.store.TradesSince(s.lastTradeID) {
+		s.lastTradeID = trade.ID
+		t := &Trade{
+			Trade:  trade,
+			Step:   s.step,
+			Buyer:  s.owners[trade.BuyOrderID],
+			Seller: s.owners[trade.SellOrderID],
+		}
+		s.result.Trades = append(s.result.Trades, t)
+
+		for _, agent := range []string{t.Buyer, t.Seller} {
+			if stats, ok := s.stats[agent]; ok {
+				stats.Trades++
+				stats.Volume += trade.Quantity
+			}
+		}
+	}
+}
+
+func (s *Simulator) snapshot() {
+	s.result.Snapshots = append(s.result.Snapshots, &Snapshot{
+		Step:      s.step,
+		Time:      s.now,
+		FairValue: s.fairValue,
+		LastPrice: s.store.LastPrice(s.cfg.Symbol),
+		Book:      s.store.Book(s.cfg.Symbol, models.BookLevel2, s.cfg.BookDepth),
+		PnL:       s.pnl(),
+	})
+}
+
+// pnl values every agent's position at the last trade price
+func (s *Simulator) pnl() []*AgentPnL {
+	pnl := make([]*AgentPnL, len(s.agents))
+	for i, agent := range s.agents {
+		position := s.store.Position(agent.Name(), s.cfg.Symbol)
+		stats := *s.stats[agent.Name()]
+		stats.Position = position.Quantity
+		stats.AverageCost = position.AverageCost
+		stats.RealizedPnL = position.RealizedPnL
+		stats.UnrealizedPnL = position.UnrealizedPnL
+		stats.TotalPnL = position.RealizedPnL + position.UnrealizedPnL
+		pnl[i] = &stats
+	}
+	return pnl
+}
+
+// Market is one agent's view of the simulation and its way to trade
+type Market struct {
+	sim   *Simulator
+	agent string
+}
+
+func (m *Market) Step() int          { return m.sim.step }
+func (m *Market) Now() time.Time     { return m.sim.now }
+func (m *Market) Symbol() string     { return m.sim.cfg.Symbol }
+func (m *Market) TickSize() float64  { return m.sim.cfg.TickSize }
+func (m *Market) Rand() *rand.Rand   { return m.sim.rng }
+func (m *Market) FairValue() float64 { return m.sim.fairValue }
+func (m *Market) LastPrice() float64 { return m.sim.store.LastPrice(m.sim.cfg.Symbol) }
+func (m *Market) OpenOrders() []*models.Order {
+	return m.sim.store.OpenOrders(m.agent, m.sim.cfg.Symbol)
+}
+
+// Book returns the order book with up to depth price levels per side
+func (m *Market) Book(depth int) *models.OrderBook {
+	return m.sim.store.Book(m.sim.cfg.Symbol, models.BookLevel2, depth)
+}
+
+// Position returns the agent's position marked to the last trade price
+func (m *Market) Position() *models.Position {
+	return m.sim.store.Position(m.agent, m.sim.cfg.Symbol)
+}
+
+// RoundPrice rounds price to the nearest tick, and up to one tick if that
+// would leave it at zero
+func (m *Market) RoundPrice(price float64) float64 {
+	tick := m.sim.cfg.TickSize
+	return math.Max(tick, models.FromUnits(models.ToUnits(math.Round(price/tick)*tick)))
+}
+
+// PlaceLimit places a limit order. The returned order has its state after
+// matching; rejected orders are returned without an error.
+func (m *Market) PlaceLimit(ctx context.Context, side models.OrderSide, price, quantity float64) (*models.Order, error) {
+	return m.place(ctx, &models.PlaceOrderRequest{
+		Symbol: m.sim.cfg.Symbol, Side: side, Type: models.OrderTypeLimit, Price: m.RoundPrice(price), Quantity: quantity,
+	})
+}
+
+// PlaceMarket places a market order
+func (m *Market) PlaceMarket(ctx context.Context, side models.OrderSide, quantity float64) (*models.Order, error) {
+	return m.place(ctx, &models.PlaceOrderRequest{
+		Symbol: m.sim.cfg.Symbol, Side: side, Type: models.OrderTypeMarket, Quantity: quantity,
+	})
+}
+
+func (m *Market) place(ctx context.Context, req *models.PlaceOrderRequest) (*models.Order, error) {
+	req.AccountID = m.agent
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	order := req.NewOrder()
+	if err := m.sim.engine.ProcessOrder(ctx, order); err != nil {
+		return nil, err
+	}
+	m.sim.owners[order.ID] = m.agent
+	m.sim.stats[m.agent].Orders++
+	return order, nil
+}
+
+// Cancel cancels one of the agent's resting orders. Canceling an order that
+// has already filled is not an error.
+func (m *Market) Cancel(ctx context.Context, orderID int) error {
+	if owner := m.sim.owners[orderID]; owner != m.agent {
+		return fmt.Errorf("order %d does not belong to %s", orderID, m.agent)
+	}
+	_, err := m.sim.engine.CancelOrder(ctx, orderID, m.agent)
+	if errors.Is(err, service.ErrOrderNotCancelable) {
+		return nil
+	}
+	return err
+}
+
+// CancelAll cancels every resting order of the agent
+func (m *Market) CancelAll(ctx context.Context) error {
+	for _, order := range m.OpenOrders() {
+		if err := m.Cancel(ctx, order.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package simulator
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func run(t *testing.T, seed int64) *Result {
+	t.Helper()
+	sim, err := New(Config{
+		Symbol:        "SIM",
+		Start:         time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC),
+		Steps:         300,
+		StepDuration:  time.Second,
+		InitialPrice:  100,
+		Volatility:    0.002,
+		TickSize:      0.01,
+		SnapshotEvery: 50,
+		BookDepth:     5,
+		Seed:          seed,
+	},
+		NewNoiseTrader("noise-1"),
+		NewNoiseTrader("noise-2"),
+		NewMarketMaker("maker-1"),
+		NewMomentumTrader("momentum-1"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := sim.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestRunIsDeterministic(t *testing.T) {
+	first, second := run(t, 42), run(t, 42)
+	if len(first.Trades) == 0 {
+		t.Fatal("simulation made no trades")
+	}
+
+	if len(first.Trades) != len(second.Trades) {
+		t.Fatalf("runs made %d and %d trades", len(first.Trades), len(second.Trades))
+	}
+	for i := range first.Trades {
+		if !reflect.DeepEqual(first.Trades[i], second.Trades[i]) {
+			t.Fatalf("trade %d differs: %+v and %+v", i, first.Trades[i].Trade, second.Trades[i].Trade)
+		}
+	}
+	if !reflect.DeepEqual(first.Snapshots, second.Snapshots) {
+		t.Fatal("snapshots differ between runs")
+	}
+	for i := range first.Agents {
+		if *first.Agents[i] != *second.Agents[i] {
+			t.Fatalf("agent PnL differs: %+v and %+v", first.Agents[i], second.Agents[i])
+		}
+	}
+
+	if other := run(t, 43); reflect.DeepEqual(first.Trades, other.Trades) {
+		t.Fatal("a different seed made the same trades")
+	}
+}
@@ -0,0 +1,70 @@
+// Package tracing configures OpenTelemetry tracing and exports spans over
+// OTLP to a collector.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// ServiceName identifies the server's spans unless OTEL_SERVICE_NAME is set
+const ServiceName = "order-matching-system"
+
+// Protocol selects how spans are sent to the collector
+type Protocol string
+
+const (
+	ProtocolGRPC Protocol = "grpc"          // OTLP over gRPC, usually port 4317
+	ProtocolHTTP Protocol = "http/protobuf" // OTLP over HTTP, usually port 4318
+)
+
+// Setup installs a global tracer provider that batches spans to an OTLP
+// collector, and W3C trace context and baggage propagation. The endpoint,
+// TLS, headers and sampler are read from the standard OTEL_EXPORTER_OTLP_*
+// and OTEL_TRACES_SAMPLER* environment variables. The returned function
+// flushes pending spans and stops the provider.
+func Setup(ctx context.Context, protocol Protocol) (func(context.Context) error, error) {
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch protocol {
+	case ProtocolGRPC:
+		exporter, err = otlptracegrpc.New(ctx)
+	case ProtocolHTTP:
+		exporter, err = otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %q, expected grpc or http/protobuf", protocol)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resource: %w", err)
+	}
+	// Attributes from OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME win
+	env, err := resource.New(ctx, resource.WithFromEnv())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read resource from environment: %w", err)
+	}
+	if res, err = resource.Merge(res, env); err != nil {
+		return nil, fmt.Errorf("failed to build resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
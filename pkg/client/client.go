@@ -0,0 +1,504 @@
+// Package client is a Go client for the order matching HTTP API. It signs
+// requests when given an API key, retries transient failures with backoff
+// and subscribes to the server-sent trade and order book streams.
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"order-matching-system/pkg/signing"
+)
+
+// Client calls the order matching API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	keyID      string
+	secret     string
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests. Streams are
+// long-lived, so its Timeout should be zero; deadlines come from the
+// context passed to each call.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithAPIKey signs every request with the given key
+func WithAPIKey(keyID, secret string) Option {
+	return func(c *Client) {
+		c.keyID = keyID
+		c.secret = secret
+	}
+}
+
+// WithRetry sets how many times a failed request is retried and the bounds
+// of the exponential backoff between attempts. Zero retries disables them.
+func WithRetry(maxRetries int, minBackoff, maxBackoff time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.minBackoff = minBackoff
+		c.maxBackoff = maxBackoff
+	}
+}
+
+// New returns a client for the API at baseURL, such as http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme must be http or https", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{},
+		maxRetries: 3,
+		minBackoff: 100 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// PlaceOrder submits an order under a new idempotency key, so retries after
+// a lost response cannot place it twice. A risk rejection is returned as a
+// *RejectedError.
+func (c *Client) PlaceOrder(ctx context.Context, req *PlaceOrderRequest) (*Order, error) {
+	return c.PlaceOrderWithKey(ctx, NewIdempotencyKey(), req)
+}
+
+// PlaceOrderWithKey submits an order under the caller's idempotency key.
+// Repeating a key returns the order placed by its first use.
+func (c *Client) PlaceOrderWithKey(ctx context.Context, key string, req *PlaceOrderRequest) (*Order, error) {
+	var order Order
+	err := c.do(ctx, &request{
+		method:         http.MethodPost,
+		path:           "/orders",
+		body:           req,
+		idempotencyKey: key,
+	}, &order)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// CancelOrder cancels a resting order. Orders that are filled, canceled or
+// unknown give an error matching ErrNotFound.
+func (c *Client) CancelOrder(ctx context.Context, orderID int) error {
+	return c.do(ctx, &request{
+		method: http.MethodDelete,
+		path:   "/orders/" + strconv.Itoa(orderID),
+	}, nil)
+}
+
+// AmendOrder changes the price or total quantity of a resting limit order.
+// A zero field keeps its current value.
+func (c *Client) AmendOrder(ctx context.Context, orderID int, req *AmendOrderRequest) (*Order, error) {
+	var order Order
+	err := c.do(ctx, &request{
+		method: http.MethodPatch,
+		path:   "/orders/" + strconv.Itoa(orderID),
+		body:   req,
+	}, &order)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// ListOrders returns the orders matching filter, newest first. A zero
+// filter.Limit uses the server default of 100.
+func (c *Client) ListOrders(ctx context.Context, filter OrderFilter) ([]*Order, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"symbol":     filter.Symbol,
+		"account_id": filter.AccountID,
+		"side":       string(filter.Side),
+		"status":     string(filter.Status),
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	if filter.Limit > 0 {
+		query.Set("limit", strconv.Itoa(filter.Limit))
+	}
+	if filter.BeforeID > 0 {
+		query.Set("before_id", strconv.Itoa(filter.BeforeID))
+	}
+
+	var orders []*Order
+	err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   "/orders",
+		query:  query,
+	}, &orders)
+	return orders, err
+}
+
+// GetOrder returns an order by ID
+func (c *Client) GetOrder(ctx context.Context, orderID int) (*Order, error) {
+	var order Order
+	err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   "/orders/" + strconv.Itoa(orderID),
+	}, &order)
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// GetOrderHistory returns an order's lifecycle events, oldest first
+func (c *Client) GetOrderHistory(ctx context.Context, orderID int) ([]*OrderEvent, error) {
+	var events []*OrderEvent
+	err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   "/orders/" + strconv.Itoa(orderID) + "/history",
+	}, &events)
+	return events, err
+}
+
+// GetOrderFills returns the trades an order took part in
+func (c *Client) GetOrderFills(ctx context.Context, orderID int) (*OrderFills, error) {
+	var fills OrderFills
+	err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   "/orders/" + strconv.Itoa(orderID) + "/fills",
+	}, &fills)
+	if err != nil {
+		return nil, err
+	}
+	return &fills, nil
+}
+
+// GetOrderBook returns the current book for a symbol
+func (c *Client) GetOrderBook(ctx context.Context, symbol string, opts BookOptions) (*OrderBook, error) {
+	var book OrderBook
+	err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   "/orderbook",
+		query:  bookQuery(symbol, opts),
+	}, &book)
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// ListTrades returns the trades for a symbol, or for every symbol when
+// symbol is empty
+func (c *Client) ListTrades(ctx context.Context, symbol string) ([]*Trade, error) {
+	query := url.Values{}
+	if symbol != "" {
+		query.Set("symbol", symbol)
+	}
+
+	var trades []*Trade
+	err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   "/trades",
+		query:  query,
+	}, &trades)
+	return trades, err
+}
+
+// GetTicker returns 24 hour statistics for a symbol, or for every traded
+// symbol when symbol is empty
+func (c *Client) GetTicker(ctx context.Context, symbol string) ([]*Ticker, error) {
+	query := url.Values{}
+	if symbol != "" {
+		query.Set("symbol", symbol)
+	}
+
+	var tickers []*Ticker
+	err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   "/ticker",
+		query:  query,
+	}, &tickers)
+	return tickers, err
+}
+
+// GetPositions returns an account's positions
+func (c *Client) GetPositions(ctx context.Context, accountID string) ([]*Position, error) {
+	var positions []*Position
+	err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   "/accounts/" + url.PathEscape(accountID) + "/positions",
+	}, &positions)
+	return positions, err
+}
+
+// ListHalts returns the symbols currently halted
+func (c *Client) ListHalts(ctx context.Context) ([]*TradingHalt, error) {
+	var halts []*TradingHalt
+	err := c.do(ctx, &request{
+		method: http.MethodGet,
+		path:   "/admin/halts",
+	}, &halts)
+	return halts, err
+}
+
+// HaltTrading stops a symbol accepting new orders and amendments
+func (c *Client) HaltTrading(ctx context.Context, symbol, reason string) (*TradingHalt, error) {
+	var halt TradingHalt
+	err := c.do(ctx, &request{
+		method: http.MethodPost,
+		path:   "/admin/symbols/" + url.PathEscape(symbol) + "/halt",
+		body:   HaltRequest{Reason: reason},
+	}, &halt)
+	if err != nil {
+		return nil, err
+	}
+	return &halt, nil
+}
+
+// ResumeTrading lifts a halt. Resuming a symbol that is not halted gives an
+// error matching ErrNotFound.
+func (c *Client) ResumeTrading(ctx context.Context, symbol string) error {
+	return c.do(ctx, &request{
+		method: http.MethodPost,
+		path:   "/admin/symbols/" + url.PathEscape(symbol) + "/resume",
+	}, nil)
+}
+
+// MassCancel cancels every open order matching the filter's symbol, account
+// and side. A symbol or account is required.
+func (c *Client) MassCancel(ctx context.Context, filter OrderFilter) (*MassCancelResult, error) {
+	var result MassCancelResult
+	err := c.do(ctx, &request{
+		method: http.MethodPost,
+		path:   "/admin/orders/mass-cancel",
+		body:   filter,
+	}, &result)
+	if err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// NewIdempotencyKey returns a random key for PlaceOrderWithKey
+func NewIdempotencyKey() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to generate idempotency key: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+type request struct {
+	method         string
+	path           string
+	query          url.Values
+	body           any
+	idempotencyKey string
+}
+
+// retryable reports whether repeating the request after err is safe. Reads,
+// updates to absolute values and order entry under an idempotency key can be
+// repeated after any temporary failure. Cancels, amendments and other orders
+// act on the order as it stands, so a repeat after a lost response could
+// fail or act twice: they are only repeated when the server refused them
+// before acting, by rate limiting or being unavailable.
+func (r *request) retryable(ctx context.Context, err error) bool {
+	if !temporary(ctx, err) {
+		return false
+	}
+	switch {
+	case r.method == http.MethodGet, r.method == http.MethodPut, r.idempotencyKey != "":
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) &&
+		(apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode == http.StatusServiceUnavailable)
+}
+
+// do sends the request, retrying transient failures, and decodes a
+// successful response into out when it is non-nil
+func (c *Client) do(ctx context.Context, r *request, out any) error {
+	var body []byte
+	if r.body != nil {
+		var err error
+		body, err = json.Marshal(r.body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, r, body)
+		if err == nil {
+			err = decodeResponse(resp, out)
+		}
+		if err == nil || attempt >= c.maxRetries || !r.retryable(ctx, err) {
+			return err
+		}
+
+		if err := sleep(ctx, c.backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+// send makes a single signed attempt at the request
+func (c *Client) send(ctx context.Context, r *request, body []byte) (*http.Response, error) {
+	req, err := c.newRequest(ctx, r.method, r.path, r.query, body)
+	if err != nil {
+		return nil, err
+	}
+	if r.idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", r.idempotencyKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// newRequest builds a request, signing it when the client has an API key.
+// Signatures carry a timestamp, so every attempt is signed afresh.
+func (c *Client) newRequest(ctx context.Context, method, path string, query url.Values, body []byte) (*http.Request, error) {
+	u := *c.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.keyID != "" {
+		timestamp := time.Now().Unix()
+		req.Header.Set(signing.HeaderKeyID, c.keyID)
+		req.Header.Set(signing.HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+		req.Header.Set(signing.HeaderSignature, signing.Sign(c.secret, timestamp, method, req.URL.RequestURI(), body))
+	}
+	return req, nil
+}
+
+// errorBody is the shape of the API's error responses
+type errorBody struct {
+	Error string     `json:"error"`
+	Code  RejectCode `json:"code"`
+	Order *Order     `json:"order"`
+}
+
+// decodeResponse reads a response, returning an *APIError or *RejectedError
+// for error statuses
+func decodeResponse(resp *http.Response, out any) error {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		apiErr := APIError{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		var body errorBody
+		if json.Unmarshal(data, &body) == nil && body.Error != "" {
+			apiErr.Message = body.Error
+		}
+		if resp.StatusCode == http.StatusUnprocessableEntity && body.Order != nil {
+			return &RejectedError{APIError: apiErr, Code: body.Code, Order: body.Order}
+		}
+		return &apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// temporary reports whether a failed attempt may succeed if repeated:
+// transport errors other than the caller's cancellation, rate limiting and
+// unavailable or timed out upstreams
+func temporary(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return true
+	}
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// backoff returns the delay before retry attempt+1: exponential from
+// minBackoff, capped at maxBackoff, with up to 50% jitter
+func (c *Client) backoff(attempt int) time.Duration {
+	d := time.Duration(float64(c.minBackoff) * math.Pow(2, float64(attempt)))
+	if d > c.maxBackoff || d <= 0 {
+		d = c.maxBackoff
+	}
+	return d/2 + time.Duration(mathrand.Int63n(int64(d/2)+1))
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func bookQuery(symbol string, opts BookOptions) url.Values {
+	query := url.Values{"symbol": {symbol}}
+	if opts.Level != 0 {
+		query.Set("level", strconv.Itoa(int(opts.Level)))
+	}
+	if opts.Depth != 0 {
+		query.Set("depth", strconv.Itoa(opts.Depth))
+	}
+	return query
+}
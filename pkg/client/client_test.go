@@ -0,0 +1,379 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"order-matching-system/internal/api"
+	"order-matching-system/internal/database/memory"
+	"order-matching-system/internal/models"
+	"order-matching-system/internal/service"
+)
+
+const (
+	testKeyID  = "test-key"
+	testSecret = "test-secret"
+)
+
+// newTestServer serves the API over a matching engine on an in-memory store,
+// requiring signed requests. Routes that read MySQL directly cannot be
+// served this way.
+func newTestServer(t *testing.T, limits service.RiskLimits) (*Client, *memory.Store) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	store := memory.NewStore(nil)
+	engine := service.NewMatchingEngineWithStore(store)
+	engine.SetRiskLimits(limits)
+
+	router := api.SetupRouter(nil, engine, api.Config{
+		APIKeys:      map[string]string{testKeyID: testSecret},
+		MaxClockSkew: time.Minute,
+	})
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+	t.Cleanup(engine.Feed().Close)
+
+	return newTestClient(t, srv.URL), store
+}
+
+// newStubServer serves handlers behind the API's signature middleware, for
+// failures the real API cannot be made to produce
+func newStubServer(t *testing.T, register func(*gin.Engine)) *Client {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(api.SignatureAuth(map[string]string{testKeyID: testSecret}, time.Minute))
+	register(router)
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	return newTestClient(t, srv.URL)
+}
+
+func newTestClient(t *testing.T, url string, opts ...Option) *Client {
+	t.Helper()
+	opts = append([]Option{
+		WithAPIKey(testKeyID, testSecret),
+		WithRetry(3, time.Millisecond, 10*time.Millisecond),
+	}, opts...)
+	c, err := New(url, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// headerRecorder keeps the headers of the last response it carried
+type headerRecorder struct {
+	mu     sync.Mutex
+	header http.Header
+}
+
+func (r *headerRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err == nil {
+		r.mu.Lock()
+		r.header = resp.Header
+		r.mu.Unlock()
+	}
+	return resp, err
+}
+
+func (r *headerRecorder) last(key string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.header.Get(key)
+}
+
+func limitRequest(side OrderSide, price, quantity float64) *PlaceOrderRequest {
+	return &PlaceOrderRequest{AccountID: "acct-" + string(side), Symbol: "BTC", Side: side, Type: Limit, Price: price, Quantity: quantity}
+}
+
+func TestPlaceAmendAndCancel(t *testing.T) {
+	c, store := newTestServer(t, service.RiskLimits{})
+	ctx := context.Background()
+
+	order, err := c.PlaceOrder(ctx, limitRequest(Buy, 100, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.ID == 0 || order.Status != Open {
+		t.Fatalf("unexpected order %+v", order)
+	}
+
+	amended, err := c.AmendOrder(ctx, order.ID, &AmendOrderRequest{Price: 101})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if amended.Price != 101 || store.Order(order.ID).Price != 101 {
+		t.Fatalf("amended price %v, stored %v, want 101", amended.Price, store.Order(order.ID).Price)
+	}
+
+	if err := c.CancelOrder(ctx, order.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.Order(order.ID).Status; got != Canceled {
+		t.Fatalf("stored status %s, want canceled", got)
+	}
+	if err := c.CancelOrder(ctx, order.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second cancel got %v, want ErrNotFound", err)
+	}
+}
+
+func TestIdempotentReplay(t *testing.T) {
+	c, store := newTestServer(t, service.RiskLimits{})
+	ctx := context.Background()
+	recorder := &headerRecorder{}
+	c.httpClient = &http.Client{Transport: recorder}
+
+	first, err := c.PlaceOrderWithKey(ctx, "key-1", limitRequest(Sell, 100, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorder.last("Idempotent-Replayed") != "" {
+		t.Fatal("first use of a key was marked as replayed")
+	}
+
+	replayed, err := c.PlaceOrderWithKey(ctx, "key-1", limitRequest(Sell, 100, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.ID != first.ID || recorder.last("Idempotent-Replayed") != "true" {
+		t.Fatalf("replay returned order %d, header %q; want order %d replayed", replayed.ID, recorder.last("Idempotent-Replayed"), first.ID)
+	}
+	if open := store.OpenOrders("acct-sell", "BTC"); len(open) != 1 {
+		t.Fatalf("got %d open orders, want 1", len(open))
+	}
+
+	_, err = c.PlaceOrderWithKey(ctx, "key-1", limitRequest(Sell, 105, 1))
+	if !errors.Is(err, ErrConflict) {
+		t.Fatalf("reused key got %v, want ErrConflict", err)
+	}
+}
+
+func TestPlaceOrderRetriesWithSameIdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	var keys []string
+	c := newStubServer(t, func(r *gin.Engine) {
+		r.POST("/orders", func(c *gin.Context) {
+			mu.Lock()
+			defer mu.Unlock()
+			keys = append(keys, c.GetHeader("Idempotency-Key"))
+			if len(keys) < 3 {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "matching engine is shutting down"})
+				return
+			}
+			var req PlaceOrderRequest
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			order := req.NewOrder()
+			order.ID = 7
+			c.JSON(http.StatusCreated, order)
+		})
+	})
+
+	order, err := c.PlaceOrder(context.Background(), &PlaceOrderRequest{
+		Symbol: "BTC", Side: Buy, Type: Limit, Price: 100, Quantity: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.ID != 7 || order.Symbol != "BTC" {
+		t.Fatalf("unexpected order %+v", order)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("got %d attempts, want 3", len(keys))
+	}
+	if keys[0] == "" || keys[1] != keys[0] || keys[2] != keys[0] {
+		t.Fatalf("idempotency keys differ across retries: %q", keys)
+	}
+}
+
+func TestRetriesGiveUp(t *testing.T) {
+	attempts := 0
+	c := newStubServer(t, func(r *gin.Engine) {
+		r.GET("/orders/:orderId", func(c *gin.Context) {
+			attempts++
+			c.JSON(http.StatusGatewayTimeout, gin.H{"error": "request timed out"})
+		})
+	})
+
+	_, err := c.GetOrder(context.Background(), 1)
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("got %v, want ErrTimeout", err)
+	}
+	if attempts != 4 {
+		t.Fatalf("got %d attempts, want 4", attempts)
+	}
+}
+
+func TestCancelAndAmendOnlyRetryWhenRefused(t *testing.T) {
+	for _, tt := range []struct {
+		status       int
+		wantAttempts int
+	}{
+		{http.StatusServiceUnavailable, 4},
+		{http.StatusTooManyRequests, 4},
+		{http.StatusBadGateway, 1},
+		{http.StatusGatewayTimeout, 1},
+	} {
+		var cancels, amends int
+		c := newStubServer(t, func(r *gin.Engine) {
+			r.DELETE("/orders/:orderId", func(c *gin.Context) {
+				cancels++
+				c.JSON(tt.status, gin.H{"error": http.StatusText(tt.status)})
+			})
+			r.PATCH("/orders/:orderId", func(c *gin.Context) {
+				amends++
+				c.JSON(tt.status, gin.H{"error": http.StatusText(tt.status)})
+			})
+		})
+
+		if err := c.CancelOrder(context.Background(), 1); err == nil {
+			t.Fatalf("%d: cancel succeeded", tt.status)
+		}
+		if _, err := c.AmendOrder(context.Background(), 1, &AmendOrderRequest{Quantity: 2}); err == nil {
+			t.Fatalf("%d: amend succeeded", tt.status)
+		}
+		if cancels != tt.wantAttempts || amends != tt.wantAttempts {
+			t.Fatalf("%d: got %d cancel and %d amend attempts, want %d", tt.status, cancels, amends, tt.wantAttempts)
+		}
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	c, _ := newTestServer(t, service.RiskLimits{MaxOrderQuantity: 100})
+	ctx := context.Background()
+
+	err := c.CancelOrder(ctx, 42)
+	var apiErr *APIError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Message == "" {
+		t.Fatalf("got %v, want ErrNotFound with the server's message", err)
+	}
+
+	_, err = c.PlaceOrder(ctx, &PlaceOrderRequest{Symbol: "BTC", Side: Sell, Type: Market, Quantity: 500})
+	var rejected *RejectedError
+	if !errors.Is(err, ErrRejected) || !errors.As(err, &rejected) {
+		t.Fatalf("got %v, want a RejectedError", err)
+	}
+	if rejected.Code != models.RejectQuantityLimit || rejected.Order == nil || rejected.Order.ID == 0 || rejected.Order.Status != Rejected {
+		t.Fatalf("unexpected rejection %+v", rejected)
+	}
+
+	_, err = c.PlaceOrder(ctx, &PlaceOrderRequest{Symbol: "BTC", Side: Buy, Type: Limit, Quantity: 1})
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("limit order without a price got %v, want ErrInvalidRequest", err)
+	}
+}
+
+func TestUnsignedRequestsAreRejected(t *testing.T) {
+	c, _ := newTestServer(t, service.RiskLimits{})
+
+	if _, err := c.PlaceOrder(context.Background(), limitRequest(Buy, 100, 1)); err != nil {
+		t.Fatalf("signed request failed: %v", err)
+	}
+
+	c.secret = "wrong"
+	if _, err := c.PlaceOrder(context.Background(), limitRequest(Buy, 100, 1)); !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("got %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestSubscribeTrades(t *testing.T) {
+	c, _ := newTestServer(t, service.RiskLimits{})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Cross the book until the subscription is up and sees a trade
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			if _, err := c.PlaceOrder(ctx, limitRequest(Sell, 100, 1)); err != nil {
+				return
+			}
+			if _, err := c.PlaceOrder(ctx, limitRequest(Buy, 100, 1)); err != nil {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	done := errors.New("done")
+	err := c.SubscribeTrades(ctx, "BTC", func(trade *Trade) error {
+		if trade.Symbol != "BTC" || trade.Price != 100 || trade.Quantity != 1 || trade.BuyOrderID == 0 || trade.SellOrderID == 0 {
+			t.Errorf("unexpected trade %+v", trade)
+		}
+		return done
+	})
+	if !errors.Is(err, done) {
+		t.Fatalf("got %v, want the callback's error", err)
+	}
+}
+
+func TestSubscribeTradesReconnects(t *testing.T) {
+	var mu sync.Mutex
+	connections := 0
+	c := newStubServer(t, func(r *gin.Engine) {
+		r.GET("/stream/trades", func(c *gin.Context) {
+			mu.Lock()
+			connections++
+			n := connections
+			mu.Unlock()
+
+			if c.Query("symbol") != "BTC" {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unexpected symbol"})
+				return
+			}
+			// Each connection sends one trade and a keep-alive, then drops
+			c.SSEvent("trade", Trade{ID: n, Symbol: "BTC", Price: 100, Quantity: 1})
+			c.Writer.WriteString(": keep-alive\n\n")
+		})
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var got []int
+	done := errors.New("done")
+	err := c.SubscribeTrades(ctx, "BTC", func(trade *Trade) error {
+		got = append(got, trade.ID)
+		if len(got) == 3 {
+			return done
+		}
+		return nil
+	})
+	if !errors.Is(err, done) {
+		t.Fatalf("got %v, want the callback's error", err)
+	}
+	if got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Fatalf("got trades %v, want 1, 2 and 3", got)
+	}
+}
+
+func TestSubscribeOrderBookStopsOnClientError(t *testing.T) {
+	c, _ := newTestServer(t, service.RiskLimits{})
+
+	err := c.SubscribeOrderBook(context.Background(), "BTC", BookOptions{Level: 4}, func(*OrderBook) error {
+		t.Fatal("unexpected book")
+		return nil
+	})
+	if !errors.Is(err, ErrInvalidRequest) {
+		t.Fatalf("got %v, want ErrInvalidRequest", err)
+	}
+}
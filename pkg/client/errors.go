@@ -0,0 +1,64 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors that an *APIError matches with errors.Is, by HTTP status
+var (
+	ErrInvalidRequest = errors.New("invalid request")       // 400
+	ErrUnauthorized   = errors.New("unauthorized")          // 401
+	ErrNotFound       = errors.New("not found")             // 404
+	ErrConflict       = errors.New("conflict")              // 409
+	ErrRejected       = errors.New("order rejected")        // 422
+	ErrUnavailable    = errors.New("service unavailable")   // 503
+	ErrTimeout        = errors.New("request timed out")     // 504
+	ErrServer         = errors.New("internal server error") // Other 5xx
+)
+
+var statusErrors = map[int]error{
+	http.StatusBadRequest:          ErrInvalidRequest,
+	http.StatusUnauthorized:        ErrUnauthorized,
+	http.StatusNotFound:            ErrNotFound,
+	http.StatusConflict:            ErrConflict,
+	http.StatusUnprocessableEntity: ErrRejected,
+	http.StatusServiceUnavailable:  ErrUnavailable,
+	http.StatusGatewayTimeout:      ErrTimeout,
+}
+
+// APIError is an error response from the API
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// Is matches the sentinel error for the response status
+func (e *APIError) Is(target error) bool {
+	if err, ok := statusErrors[e.StatusCode]; ok {
+		return err == target
+	}
+	return target == ErrServer && e.StatusCode >= 500
+}
+
+// RejectedError is returned by PlaceOrder when the order failed a pre-trade
+// risk check. It matches ErrRejected.
+type RejectedError struct {
+	APIError
+	Code  RejectCode
+	Order *Order // The rejected order as stored for audit
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("order rejected (%s): %s", e.Code, e.Message)
+}
+
+// Unwrap exposes the APIError so errors.Is matches ErrRejected
+func (e *RejectedError) Unwrap() error {
+	return &e.APIError
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SubscribeTrades calls fn with each trade for symbol, or for every symbol
+// when symbol is empty, as trades commit. Dropped connections are reopened
+// with backoff; trades made while disconnected are not replayed. It returns
+// when ctx is done, fn returns an error or the server refuses the stream.
+func (c *Client) SubscribeTrades(ctx context.Context, symbol string, fn func(*Trade) error) error {
+	query := url.Values{}
+	if symbol != "" {
+		query.Set("symbol", symbol)
+	}
+
+	return c.subscribe(ctx, "/stream/trades", query, "trade", func(data []byte) error {
+		var trade Trade
+		if err := json.Unmarshal(data, &trade); err != nil {
+			return fmt.Errorf("failed to decode trade: %w", err)
+		}
+		return fn(&trade)
+	})
+}
+
+// SubscribeOrderBook calls fn with the book for symbol on connecting and
+// again after every change. Rapid changes are coalesced, so each call has
+// the latest book rather than every intermediate state. Reconnection and
+// return behave as for SubscribeTrades.
+func (c *Client) SubscribeOrderBook(ctx context.Context, symbol string, opts BookOptions, fn func(*OrderBook) error) error {
+	return c.subscribe(ctx, "/stream/orderbook", bookQuery(symbol, opts), "orderbook", func(data []byte) error {
+		var book OrderBook
+		if err := json.Unmarshal(data, &book); err != nil {
+			return fmt.Errorf("failed to decode order book: %w", err)
+		}
+		return fn(&book)
+	})
+}
+
+// errStreamEnded marks a stream the server closed or failed, which is
+// reopened like a dropped connection
+var errStreamEnded = errors.New("stream ended")
+
+// subscribe reads the named events from a stream, reconnecting until ctx is
+// done or handle fails
+func (c *Client) subscribe(ctx context.Context, path string, query url.Values, event string, handle func([]byte) error) error {
+	attempt := 0
+	for {
+		connected, err := c.readStream(ctx, path, query, event, handle)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var handlerErr *handlerError
+		if errors.As(err, &handlerErr) {
+			return handlerErr.err
+		}
+		if !errors.Is(err, errStreamEnded) && !temporary(ctx, err) {
+			return err
+		}
+
+		if connected {
+			attempt = 0
+		}
+		if err := sleep(ctx, c.backoff(attempt)); err != nil {
+			return err
+		}
+		attempt++
+	}
+}
+
+// handlerError carries an error from the subscriber's callback
+type handlerError struct{ err error }
+
+func (e *handlerError) Error() string { return e.err.Error() }
+
+// readStream opens one connection and dispatches its events until it ends,
+// reporting whether the server accepted the stream
+func (c *Client) readStream(ctx context.Context, path string, query url.Values, event string, handle func([]byte) error) (bool, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("failed to open stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, decodeResponse(resp, nil)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var name string
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			// A blank line ends the event
+			if name == "error" {
+				return true, fmt.Errorf("%w: %s", errStreamEnded, data.String())
+			}
+			if name == event && data.Len() > 0 {
+				if err := handle([]byte(data.String())); err != nil {
+					return true, &handlerError{err}
+				}
+			}
+			name = ""
+			data.Reset()
+		case strings.HasPrefix(line, ":"):
+			// Keep-alive comment
+		case strings.HasPrefix(line, "event:"):
+			name = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return true, fmt.Errorf("%w: %v", errStreamEnded, err)
+	}
+	return true, errStreamEnded
+}
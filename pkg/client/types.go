@@ -0,0 +1,52 @@
+package client
+
+import (
+	"order-matching-system/internal/models"
+)
+
+// The API's request and response types, re-exported so that callers outside
+// this module can name them
+type (
+	Order             = models.Order
+	OrderSide         = models.OrderSide
+	OrderType         = models.OrderType
+	OrderStatus       = models.OrderStatus
+	RejectCode        = models.RejectCode
+	PlaceOrderRequest = models.PlaceOrderRequest
+	AmendOrderRequest = models.AmendOrderRequest
+	OrderFilter       = models.OrderFilter
+	OrderEvent        = models.OrderEvent
+	OrderFills        = models.OrderFills
+	OrderBook         = models.OrderBook
+	OrderBookEntry    = models.OrderBookEntry
+	BookLevel         = models.BookLevel
+	Trade             = models.Trade
+	Ticker            = models.Ticker
+	Position          = models.Position
+	TradingHalt       = models.TradingHalt
+	HaltRequest       = models.HaltRequest
+	MassCancelResult  = models.MassCancelResult
+)
+
+const (
+	Buy    = models.OrderSideBuy
+	Sell   = models.OrderSideSell
+	Limit  = models.OrderTypeLimit
+	Market = models.OrderTypeMarket
+
+	Open     = models.OrderStatusOpen
+	Filled   = models.OrderStatusFilled
+	Canceled = models.OrderStatusCanceled
+	Rejected = models.OrderStatusRejected
+
+	BookLevel1 = models.BookLevel1
+	BookLevel2 = models.BookLevel2
+	BookLevel3 = models.BookLevel3
+)
+
+// BookOptions selects the detail of an order book request. Zero values use
+// the server defaults.
+type BookOptions struct {
+	Level BookLevel
+	Depth int
+}
@@ -0,0 +1,5 @@
+// Package omspb contains the protobuf messages and gRPC stubs generated from
+// proto/oms.proto.
+package omspb
+
+//go:generate protoc -I ../../proto --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative oms.proto
@@ -0,0 +1,1465 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v5.29.3
+// source: oms.proto
+
+package omspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Side int32
+
+const (
+	Side_SIDE_UNSPECIFIED Side = 0
+	Side_SIDE_BUY         Side = 1
+	Side_SIDE_SELL        Side = 2
+)
+
+// Enum value maps for Side.
+var (
+	Side_name = map[int32]string{
+		0: "SIDE_UNSPECIFIED",
+		1: "SIDE_BUY",
+		2: "SIDE_SELL",
+	}
+	Side_value = map[string]int32{
+		"SIDE_UNSPECIFIED": 0,
+		"SIDE_BUY":         1,
+		"SIDE_SELL":        2,
+	}
+)
+
+func (x Side) Enum() *Side {
+	p := new(Side)
+	*p = x
+	return p
+}
+
+func (x Side) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Side) Descriptor() protoreflect.EnumDescriptor {
+	return file_oms_proto_enumTypes[0].Descriptor()
+}
+
+func (Side) Type() protoreflect.EnumType {
+	return &file_oms_proto_enumTypes[0]
+}
+
+func (x Side) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Side.Descriptor instead.
+func (Side) EnumDescriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{0}
+}
+
+type OrderType int32
+
+const (
+	OrderType_ORDER_TYPE_UNSPECIFIED OrderType = 0
+	OrderType_ORDER_TYPE_LIMIT       OrderType = 1
+	OrderType_ORDER_TYPE_MARKET      OrderType = 2
+)
+
+// Enum value maps for OrderType.
+var (
+	OrderType_name = map[int32]string{
+		0: "ORDER_TYPE_UNSPECIFIED",
+		1: "ORDER_TYPE_LIMIT",
+		2: "ORDER_TYPE_MARKET",
+	}
+	OrderType_value = map[string]int32{
+		"ORDER_TYPE_UNSPECIFIED": 0,
+		"ORDER_TYPE_LIMIT":       1,
+		"ORDER_TYPE_MARKET":      2,
+	}
+)
+
+func (x OrderType) Enum() *OrderType {
+	p := new(OrderType)
+	*p = x
+	return p
+}
+
+func (x OrderType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OrderType) Descriptor() protoreflect.EnumDescriptor {
+	return file_oms_proto_enumTypes[1].Descriptor()
+}
+
+func (OrderType) Type() protoreflect.EnumType {
+	return &file_oms_proto_enumTypes[1]
+}
+
+func (x OrderType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OrderType.Descriptor instead.
+func (OrderType) EnumDescriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{1}
+}
+
+type OrderStatus int32
+
+const (
+	OrderStatus_ORDER_STATUS_UNSPECIFIED OrderStatus = 0
+	OrderStatus_ORDER_STATUS_OPEN        OrderStatus = 1
+	OrderStatus_ORDER_STATUS_FILLED      OrderStatus = 2
+	OrderStatus_ORDER_STATUS_CANCELED    OrderStatus = 3
+	OrderStatus_ORDER_STATUS_REJECTED    OrderStatus = 4
+)
+
+// Enum value maps for OrderStatus.
+var (
+	OrderStatus_name = map[int32]string{
+		0: "ORDER_STATUS_UNSPECIFIED",
+		1: "ORDER_STATUS_OPEN",
+		2: "ORDER_STATUS_FILLED",
+		3: "ORDER_STATUS_CANCELED",
+		4: "ORDER_STATUS_REJECTED",
+	}
+	OrderStatus_value = map[string]int32{
+		"ORDER_STATUS_UNSPECIFIED": 0,
+		"ORDER_STATUS_OPEN":        1,
+		"ORDER_STATUS_FILLED":      2,
+		"ORDER_STATUS_CANCELED":    3,
+		"ORDER_STATUS_REJECTED":    4,
+	}
+)
+
+func (x OrderStatus) Enum() *OrderStatus {
+	p := new(OrderStatus)
+	*p = x
+	return p
+}
+
+func (x OrderStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OrderStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_oms_proto_enumTypes[2].Descriptor()
+}
+
+func (OrderStatus) Type() protoreflect.EnumType {
+	return &file_oms_proto_enumTypes[2]
+}
+
+func (x OrderStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OrderStatus.Descriptor instead.
+func (OrderStatus) EnumDescriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{2}
+}
+
+type Order struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	AccountId         string                 `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Symbol            string                 `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side              Side                   `protobuf:"varint,4,opt,name=side,proto3,enum=oms.v1.Side" json:"side,omitempty"`
+	Type              OrderType              `protobuf:"varint,5,opt,name=type,proto3,enum=oms.v1.OrderType" json:"type,omitempty"`
+	Price             float64                `protobuf:"fixed64,6,opt,name=price,proto3" json:"price,omitempty"`                                // Limit orders only
+	MaxSlippage       float64                `protobuf:"fixed64,7,opt,name=max_slippage,json=maxSlippage,proto3" json:"max_slippage,omitempty"` // Market orders only
+	InitialQuantity   float64                `protobuf:"fixed64,8,opt,name=initial_quantity,json=initialQuantity,proto3" json:"initial_quantity,omitempty"`
+	RemainingQuantity float64                `protobuf:"fixed64,9,opt,name=remaining_quantity,json=remainingQuantity,proto3" json:"remaining_quantity,omitempty"`
+	FilledQuantity    float64                `protobuf:"fixed64,10,opt,name=filled_quantity,json=filledQuantity,proto3" json:"filled_quantity,omitempty"`
+	AverageFillPrice  float64                `protobuf:"fixed64,11,opt,name=average_fill_price,json=averageFillPrice,proto3" json:"average_fill_price,omitempty"`
+	LastFillPrice     float64                `protobuf:"fixed64,12,opt,name=last_fill_price,json=lastFillPrice,proto3" json:"last_fill_price,omitempty"`
+	LastFillAt        *timestamppb.Timestamp `protobuf:"bytes,13,opt,name=last_fill_at,json=lastFillAt,proto3" json:"last_fill_at,omitempty"`
+	Status            OrderStatus            `protobuf:"varint,14,opt,name=status,proto3,enum=oms.v1.OrderStatus" json:"status,omitempty"`
+	StatusReason      string                 `protobuf:"bytes,15,opt,name=status_reason,json=statusReason,proto3" json:"status_reason,omitempty"` // Why the order was canceled or rejected
+	RejectCode        string                 `protobuf:"bytes,16,opt,name=reject_code,json=rejectCode,proto3" json:"reject_code,omitempty"`       // Failed risk check, e.g. quantity_limit
+	CreatedAt         *timestamppb.Timestamp `protobuf:"bytes,17,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt         *timestamppb.Timestamp `protobuf:"bytes,18,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Order) Reset() {
+	*x = Order{}
+	mi := &file_oms_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Order) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Order) ProtoMessage() {}
+
+func (x *Order) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Order.ProtoReflect.Descriptor instead.
+func (*Order) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Order) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Order) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *Order) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Order) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *Order) GetType() OrderType {
+	if x != nil {
+		return x.Type
+	}
+	return OrderType_ORDER_TYPE_UNSPECIFIED
+}
+
+func (x *Order) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Order) GetMaxSlippage() float64 {
+	if x != nil {
+		return x.MaxSlippage
+	}
+	return 0
+}
+
+func (x *Order) GetInitialQuantity() float64 {
+	if x != nil {
+		return x.InitialQuantity
+	}
+	return 0
+}
+
+func (x *Order) GetRemainingQuantity() float64 {
+	if x != nil {
+		return x.RemainingQuantity
+	}
+	return 0
+}
+
+func (x *Order) GetFilledQuantity() float64 {
+	if x != nil {
+		return x.FilledQuantity
+	}
+	return 0
+}
+
+func (x *Order) GetAverageFillPrice() float64 {
+	if x != nil {
+		return x.AverageFillPrice
+	}
+	return 0
+}
+
+func (x *Order) GetLastFillPrice() float64 {
+	if x != nil {
+		return x.LastFillPrice
+	}
+	return 0
+}
+
+func (x *Order) GetLastFillAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastFillAt
+	}
+	return nil
+}
+
+func (x *Order) GetStatus() OrderStatus {
+	if x != nil {
+		return x.Status
+	}
+	return OrderStatus_ORDER_STATUS_UNSPECIFIED
+}
+
+func (x *Order) GetStatusReason() string {
+	if x != nil {
+		return x.StatusReason
+	}
+	return ""
+}
+
+func (x *Order) GetRejectCode() string {
+	if x != nil {
+		return x.RejectCode
+	}
+	return ""
+}
+
+func (x *Order) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Order) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type Trade struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	BuyOrderId    int64                  `protobuf:"varint,3,opt,name=buy_order_id,json=buyOrderId,proto3" json:"buy_order_id,omitempty"`
+	SellOrderId   int64                  `protobuf:"varint,4,opt,name=sell_order_id,json=sellOrderId,proto3" json:"sell_order_id,omitempty"`
+	Price         float64                `protobuf:"fixed64,5,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity      float64                `protobuf:"fixed64,6,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Busted        bool                   `protobuf:"varint,7,opt,name=busted,proto3" json:"busted,omitempty"`
+	Corrected     bool                   `protobuf:"varint,8,opt,name=corrected,proto3" json:"corrected,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Trade) Reset() {
+	*x = Trade{}
+	mi := &file_oms_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Trade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Trade) ProtoMessage() {}
+
+func (x *Trade) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Trade.ProtoReflect.Descriptor instead.
+func (*Trade) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Trade) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Trade) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *Trade) GetBuyOrderId() int64 {
+	if x != nil {
+		return x.BuyOrderId
+	}
+	return 0
+}
+
+func (x *Trade) GetSellOrderId() int64 {
+	if x != nil {
+		return x.SellOrderId
+	}
+	return 0
+}
+
+func (x *Trade) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Trade) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *Trade) GetBusted() bool {
+	if x != nil {
+		return x.Busted
+	}
+	return false
+}
+
+func (x *Trade) GetCorrected() bool {
+	if x != nil {
+		return x.Corrected
+	}
+	return false
+}
+
+func (x *Trade) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type BookEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Price         float64                `protobuf:"fixed64,1,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity      float64                `protobuf:"fixed64,2,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Orders        int32                  `protobuf:"varint,3,opt,name=orders,proto3" json:"orders,omitempty"`                       // Levels 1 and 2
+	OrderId       int64                  `protobuf:"varint,4,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`      // Level 3
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // Level 3
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BookEntry) Reset() {
+	*x = BookEntry{}
+	mi := &file_oms_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BookEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BookEntry) ProtoMessage() {}
+
+func (x *BookEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BookEntry.ProtoReflect.Descriptor instead.
+func (*BookEntry) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *BookEntry) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *BookEntry) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *BookEntry) GetOrders() int32 {
+	if x != nil {
+		return x.Orders
+	}
+	return 0
+}
+
+func (x *BookEntry) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *BookEntry) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type OrderBook struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Level         int32                  `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
+	Bids          []*BookEntry           `protobuf:"bytes,3,rep,name=bids,proto3" json:"bids,omitempty"` // Highest price first
+	Asks          []*BookEntry           `protobuf:"bytes,4,rep,name=asks,proto3" json:"asks,omitempty"` // Lowest price first
+	Spread        *float64               `protobuf:"fixed64,5,opt,name=spread,proto3,oneof" json:"spread,omitempty"`
+	MidPrice      *float64               `protobuf:"fixed64,6,opt,name=mid_price,json=midPrice,proto3,oneof" json:"mid_price,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderBook) Reset() {
+	*x = OrderBook{}
+	mi := &file_oms_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderBook) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderBook) ProtoMessage() {}
+
+func (x *OrderBook) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderBook.ProtoReflect.Descriptor instead.
+func (*OrderBook) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *OrderBook) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *OrderBook) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *OrderBook) GetBids() []*BookEntry {
+	if x != nil {
+		return x.Bids
+	}
+	return nil
+}
+
+func (x *OrderBook) GetAsks() []*BookEntry {
+	if x != nil {
+		return x.Asks
+	}
+	return nil
+}
+
+func (x *OrderBook) GetSpread() float64 {
+	if x != nil && x.Spread != nil {
+		return *x.Spread
+	}
+	return 0
+}
+
+func (x *OrderBook) GetMidPrice() float64 {
+	if x != nil && x.MidPrice != nil {
+		return *x.MidPrice
+	}
+	return 0
+}
+
+type PlaceOrderRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	AccountId   string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Symbol      string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side        Side                   `protobuf:"varint,3,opt,name=side,proto3,enum=oms.v1.Side" json:"side,omitempty"`
+	Type        OrderType              `protobuf:"varint,4,opt,name=type,proto3,enum=oms.v1.OrderType" json:"type,omitempty"`
+	Price       float64                `protobuf:"fixed64,5,opt,name=price,proto3" json:"price,omitempty"`
+	Quantity    float64                `protobuf:"fixed64,6,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	MaxSlippage float64                `protobuf:"fixed64,7,opt,name=max_slippage,json=maxSlippage,proto3" json:"max_slippage,omitempty"`
+	// Resubmitting with the same key returns the original order instead of
+	// placing a new one
+	IdempotencyKey string `protobuf:"bytes,8,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PlaceOrderRequest) Reset() {
+	*x = PlaceOrderRequest{}
+	mi := &file_oms_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaceOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaceOrderRequest) ProtoMessage() {}
+
+func (x *PlaceOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaceOrderRequest.ProtoReflect.Descriptor instead.
+func (*PlaceOrderRequest) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *PlaceOrderRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *PlaceOrderRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *PlaceOrderRequest) GetSide() Side {
+	if x != nil {
+		return x.Side
+	}
+	return Side_SIDE_UNSPECIFIED
+}
+
+func (x *PlaceOrderRequest) GetType() OrderType {
+	if x != nil {
+		return x.Type
+	}
+	return OrderType_ORDER_TYPE_UNSPECIFIED
+}
+
+func (x *PlaceOrderRequest) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *PlaceOrderRequest) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *PlaceOrderRequest) GetMaxSlippage() float64 {
+	if x != nil {
+		return x.MaxSlippage
+	}
+	return 0
+}
+
+func (x *PlaceOrderRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+// PlaceOrderResponse carries the order after matching. An order that fails a
+// pre-trade risk check is returned with status REJECTED and a reject_code.
+type PlaceOrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Order         *Order                 `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaceOrderResponse) Reset() {
+	*x = PlaceOrderResponse{}
+	mi := &file_oms_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaceOrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaceOrderResponse) ProtoMessage() {}
+
+func (x *PlaceOrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaceOrderResponse.ProtoReflect.Descriptor instead.
+func (*PlaceOrderResponse) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PlaceOrderResponse) GetOrder() *Order {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+type CancelOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderRequest) Reset() {
+	*x = CancelOrderRequest{}
+	mi := &file_oms_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderRequest) ProtoMessage() {}
+
+func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderRequest.ProtoReflect.Descriptor instead.
+func (*CancelOrderRequest) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CancelOrderRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type CancelOrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Order         *Order                 `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelOrderResponse) Reset() {
+	*x = CancelOrderResponse{}
+	mi := &file_oms_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelOrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelOrderResponse) ProtoMessage() {}
+
+func (x *CancelOrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelOrderResponse.ProtoReflect.Descriptor instead.
+func (*CancelOrderResponse) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CancelOrderResponse) GetOrder() *Order {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+type GetOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       int64                  `protobuf:"varint,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderRequest) Reset() {
+	*x = GetOrderRequest{}
+	mi := &file_oms_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderRequest) ProtoMessage() {}
+
+func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderRequest) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetOrderRequest) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+type GetOrderResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Order         *Order                 `protobuf:"bytes,1,opt,name=order,proto3" json:"order,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderResponse) Reset() {
+	*x = GetOrderResponse{}
+	mi := &file_oms_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderResponse) ProtoMessage() {}
+
+func (x *GetOrderResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderResponse) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetOrderResponse) GetOrder() *Order {
+	if x != nil {
+		return x.Order
+	}
+	return nil
+}
+
+type GetOrderBookRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Level         int32                  `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"` // 1, 2 or 3; defaults to 2
+	Depth         int32                  `protobuf:"varint,3,opt,name=depth,proto3" json:"depth,omitempty"` // Defaults to 50
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderBookRequest) Reset() {
+	*x = GetOrderBookRequest{}
+	mi := &file_oms_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderBookRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderBookRequest) ProtoMessage() {}
+
+func (x *GetOrderBookRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderBookRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderBookRequest) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetOrderBookRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *GetOrderBookRequest) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *GetOrderBookRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+type GetOrderBookResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Book          *OrderBook             `protobuf:"bytes,1,opt,name=book,proto3" json:"book,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetOrderBookResponse) Reset() {
+	*x = GetOrderBookResponse{}
+	mi := &file_oms_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetOrderBookResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetOrderBookResponse) ProtoMessage() {}
+
+func (x *GetOrderBookResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetOrderBookResponse.ProtoReflect.Descriptor instead.
+func (*GetOrderBookResponse) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetOrderBookResponse) GetBook() *OrderBook {
+	if x != nil {
+		return x.Book
+	}
+	return nil
+}
+
+type ListTradesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"` // Empty for all symbols
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTradesRequest) Reset() {
+	*x = ListTradesRequest{}
+	mi := &file_oms_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTradesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTradesRequest) ProtoMessage() {}
+
+func (x *ListTradesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTradesRequest.ProtoReflect.Descriptor instead.
+func (*ListTradesRequest) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListTradesRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+type ListTradesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trades        []*Trade               `protobuf:"bytes,1,rep,name=trades,proto3" json:"trades,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTradesResponse) Reset() {
+	*x = ListTradesResponse{}
+	mi := &file_oms_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTradesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTradesResponse) ProtoMessage() {}
+
+func (x *ListTradesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTradesResponse.ProtoReflect.Descriptor instead.
+func (*ListTradesResponse) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListTradesResponse) GetTrades() []*Trade {
+	if x != nil {
+		return x.Trades
+	}
+	return nil
+}
+
+type StreamTradesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"` // Empty for all symbols
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamTradesRequest) Reset() {
+	*x = StreamTradesRequest{}
+	mi := &file_oms_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamTradesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamTradesRequest) ProtoMessage() {}
+
+func (x *StreamTradesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamTradesRequest.ProtoReflect.Descriptor instead.
+func (*StreamTradesRequest) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StreamTradesRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+type StreamBookUpdatesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Symbol        string                 `protobuf:"bytes,1,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Level         int32                  `protobuf:"varint,2,opt,name=level,proto3" json:"level,omitempty"`
+	Depth         int32                  `protobuf:"varint,3,opt,name=depth,proto3" json:"depth,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamBookUpdatesRequest) Reset() {
+	*x = StreamBookUpdatesRequest{}
+	mi := &file_oms_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamBookUpdatesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamBookUpdatesRequest) ProtoMessage() {}
+
+func (x *StreamBookUpdatesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_oms_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamBookUpdatesRequest.ProtoReflect.Descriptor instead.
+func (*StreamBookUpdatesRequest) Descriptor() ([]byte, []int) {
+	return file_oms_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StreamBookUpdatesRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *StreamBookUpdatesRequest) GetLevel() int32 {
+	if x != nil {
+		return x.Level
+	}
+	return 0
+}
+
+func (x *StreamBookUpdatesRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+var File_oms_proto protoreflect.FileDescriptor
+
+const file_oms_proto_rawDesc = "" +
+	"\n" +
+	"\toms.proto\x12\x06oms.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd0\x05\n" +
+	"\x05Order\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x02 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06symbol\x18\x03 \x01(\tR\x06symbol\x12 \n" +
+	"\x04side\x18\x04 \x01(\x0e2\f.oms.v1.SideR\x04side\x12%\n" +
+	"\x04type\x18\x05 \x01(\x0e2\x11.oms.v1.OrderTypeR\x04type\x12\x14\n" +
+	"\x05price\x18\x06 \x01(\x01R\x05price\x12!\n" +
+	"\fmax_slippage\x18\a \x01(\x01R\vmaxSlippage\x12)\n" +
+	"\x10initial_quantity\x18\b \x01(\x01R\x0finitialQuantity\x12-\n" +
+	"\x12remaining_quantity\x18\t \x01(\x01R\x11remainingQuantity\x12'\n" +
+	"\x0ffilled_quantity\x18\n" +
+	" \x01(\x01R\x0efilledQuantity\x12,\n" +
+	"\x12average_fill_price\x18\v \x01(\x01R\x10averageFillPrice\x12&\n" +
+	"\x0flast_fill_price\x18\f \x01(\x01R\rlastFillPrice\x12<\n" +
+	"\flast_fill_at\x18\r \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"lastFillAt\x12+\n" +
+	"\x06status\x18\x0e \x01(\x0e2\x13.oms.v1.OrderStatusR\x06status\x12#\n" +
+	"\rstatus_reason\x18\x0f \x01(\tR\fstatusReason\x12\x1f\n" +
+	"\vreject_code\x18\x10 \x01(\tR\n" +
+	"rejectCode\x129\n" +
+	"\n" +
+	"created_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\x98\x02\n" +
+	"\x05Trade\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12 \n" +
+	"\fbuy_order_id\x18\x03 \x01(\x03R\n" +
+	"buyOrderId\x12\"\n" +
+	"\rsell_order_id\x18\x04 \x01(\x03R\vsellOrderId\x12\x14\n" +
+	"\x05price\x18\x05 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bquantity\x18\x06 \x01(\x01R\bquantity\x12\x16\n" +
+	"\x06busted\x18\a \x01(\bR\x06busted\x12\x1c\n" +
+	"\tcorrected\x18\b \x01(\bR\tcorrected\x129\n" +
+	"\n" +
+	"created_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xab\x01\n" +
+	"\tBookEntry\x12\x14\n" +
+	"\x05price\x18\x01 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bquantity\x18\x02 \x01(\x01R\bquantity\x12\x16\n" +
+	"\x06orders\x18\x03 \x01(\x05R\x06orders\x12\x19\n" +
+	"\border_id\x18\x04 \x01(\x03R\aorderId\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\xdf\x01\n" +
+	"\tOrderBook\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\x05R\x05level\x12%\n" +
+	"\x04bids\x18\x03 \x03(\v2\x11.oms.v1.BookEntryR\x04bids\x12%\n" +
+	"\x04asks\x18\x04 \x03(\v2\x11.oms.v1.BookEntryR\x04asks\x12\x1b\n" +
+	"\x06spread\x18\x05 \x01(\x01H\x00R\x06spread\x88\x01\x01\x12 \n" +
+	"\tmid_price\x18\x06 \x01(\x01H\x01R\bmidPrice\x88\x01\x01B\t\n" +
+	"\a_spreadB\f\n" +
+	"\n" +
+	"_mid_price\"\x91\x02\n" +
+	"\x11PlaceOrderRequest\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12 \n" +
+	"\x04side\x18\x03 \x01(\x0e2\f.oms.v1.SideR\x04side\x12%\n" +
+	"\x04type\x18\x04 \x01(\x0e2\x11.oms.v1.OrderTypeR\x04type\x12\x14\n" +
+	"\x05price\x18\x05 \x01(\x01R\x05price\x12\x1a\n" +
+	"\bquantity\x18\x06 \x01(\x01R\bquantity\x12!\n" +
+	"\fmax_slippage\x18\a \x01(\x01R\vmaxSlippage\x12'\n" +
+	"\x0fidempotency_key\x18\b \x01(\tR\x0eidempotencyKey\"9\n" +
+	"\x12PlaceOrderResponse\x12#\n" +
+	"\x05order\x18\x01 \x01(\v2\r.oms.v1.OrderR\x05order\"/\n" +
+	"\x12CancelOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\":\n" +
+	"\x13CancelOrderResponse\x12#\n" +
+	"\x05order\x18\x01 \x01(\v2\r.oms.v1.OrderR\x05order\",\n" +
+	"\x0fGetOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\x03R\aorderId\"7\n" +
+	"\x10GetOrderResponse\x12#\n" +
+	"\x05order\x18\x01 \x01(\v2\r.oms.v1.OrderR\x05order\"Y\n" +
+	"\x13GetOrderBookRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\x05R\x05level\x12\x14\n" +
+	"\x05depth\x18\x03 \x01(\x05R\x05depth\"=\n" +
+	"\x14GetOrderBookResponse\x12%\n" +
+	"\x04book\x18\x01 \x01(\v2\x11.oms.v1.OrderBookR\x04book\"+\n" +
+	"\x11ListTradesRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\";\n" +
+	"\x12ListTradesResponse\x12%\n" +
+	"\x06trades\x18\x01 \x03(\v2\r.oms.v1.TradeR\x06trades\"-\n" +
+	"\x13StreamTradesRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\"^\n" +
+	"\x18StreamBookUpdatesRequest\x12\x16\n" +
+	"\x06symbol\x18\x01 \x01(\tR\x06symbol\x12\x14\n" +
+	"\x05level\x18\x02 \x01(\x05R\x05level\x12\x14\n" +
+	"\x05depth\x18\x03 \x01(\x05R\x05depth*9\n" +
+	"\x04Side\x12\x14\n" +
+	"\x10SIDE_UNSPECIFIED\x10\x00\x12\f\n" +
+	"\bSIDE_BUY\x10\x01\x12\r\n" +
+	"\tSIDE_SELL\x10\x02*T\n" +
+	"\tOrderType\x12\x1a\n" +
+	"\x16ORDER_TYPE_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10ORDER_TYPE_LIMIT\x10\x01\x12\x15\n" +
+	"\x11ORDER_TYPE_MARKET\x10\x02*\x91\x01\n" +
+	"\vOrderStatus\x12\x1c\n" +
+	"\x18ORDER_STATUS_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11ORDER_STATUS_OPEN\x10\x01\x12\x17\n" +
+	"\x13ORDER_STATUS_FILLED\x10\x02\x12\x19\n" +
+	"\x15ORDER_STATUS_CANCELED\x10\x03\x12\x19\n" +
+	"\x15ORDER_STATUS_REJECTED\x10\x042\xf5\x03\n" +
+	"\rOrderMatching\x12C\n" +
+	"\n" +
+	"PlaceOrder\x12\x19.oms.v1.PlaceOrderRequest\x1a\x1a.oms.v1.PlaceOrderResponse\x12F\n" +
+	"\vCancelOrder\x12\x1a.oms.v1.CancelOrderRequest\x1a\x1b.oms.v1.CancelOrderResponse\x12=\n" +
+	"\bGetOrder\x12\x17.oms.v1.GetOrderRequest\x1a\x18.oms.v1.GetOrderResponse\x12I\n" +
+	"\fGetOrderBook\x12\x1b.oms.v1.GetOrderBookRequest\x1a\x1c.oms.v1.GetOrderBookResponse\x12C\n" +
+	"\n" +
+	"ListTrades\x12\x19.oms.v1.ListTradesRequest\x1a\x1a.oms.v1.ListTradesResponse\x12<\n" +
+	"\fStreamTrades\x12\x1b.oms.v1.StreamTradesRequest\x1a\r.oms.v1.Trade0\x01\x12J\n" +
+	"\x11StreamBookUpdates\x12 .oms.v1.StreamBookUpdatesRequest\x1a\x11.oms.v1.OrderBook0\x01B!Z\x1forder-matching-system/pkg/omspbb\x06proto3"
+
+var (
+	file_oms_proto_rawDescOnce sync.Once
+	file_oms_proto_rawDescData []byte
+)
+
+func file_oms_proto_rawDescGZIP() []byte {
+	file_oms_proto_rawDescOnce.Do(func() {
+		file_oms_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_oms_proto_rawDesc), len(file_oms_proto_rawDesc)))
+	})
+	return file_oms_proto_rawDescData
+}
+
+var file_oms_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
+var file_oms_proto_msgTypes = make([]protoimpl.MessageInfo, 16)
+var file_oms_proto_goTypes = []any{
+	(Side)(0),                        // 0: oms.v1.Side
+	(OrderType)(0),                   // 1: oms.v1.OrderType
+	(OrderStatus)(0),                 // 2: oms.v1.OrderStatus
+	(*Order)(nil),                    // 3: oms.v1.Order
+	(*Trade)(nil),                    // 4: oms.v1.Trade
+	(*BookEntry)(nil),                // 5: oms.v1.BookEntry
+	(*OrderBook)(nil),                // 6: oms.v1.OrderBook
+	(*PlaceOrderRequest)(nil),        // 7: oms.v1.PlaceOrderRequest
+	(*PlaceOrderResponse)(nil),       // 8: oms.v1.PlaceOrderResponse
+	(*CancelOrderRequest)(nil),       // 9: oms.v1.CancelOrderRequest
+	(*CancelOrderResponse)(nil),      // 10: oms.v1.CancelOrderResponse
+	(*GetOrderRequest)(nil),          // 11: oms.v1.GetOrderRequest
+	(*GetOrderResponse)(nil),         // 12: oms.v1.GetOrderResponse
+	(*GetOrderBookRequest)(nil),      // 13: oms.v1.GetOrderBookRequest
+	(*GetOrderBookResponse)(nil),     // 14: oms.v1.GetOrderBookResponse
+	(*ListTradesRequest)(nil),        // 15: oms.v1.ListTradesRequest
+	(*ListTradesResponse)(nil),       // 16: oms.v1.ListTradesResponse
+	(*StreamTradesRequest)(nil),      // 17: oms.v1.StreamTradesRequest
+	(*StreamBookUpdatesRequest)(nil), // 18: oms.v1.StreamBookUpdatesRequest
+	(*timestamppb.Timestamp)(nil),    // 19: google.protobuf.Timestamp
+}
+var file_oms_proto_depIdxs = []int32{
+	0,  // 0: oms.v1.Order.side:type_name -> oms.v1.Side
+	1,  // 1: oms.v1.Order.type:type_name -> oms.v1.OrderType
+	19, // 2: oms.v1.Order.last_fill_at:type_name -> google.protobuf.Timestamp
+	2,  // 3: oms.v1.Order.status:type_name -> oms.v1.OrderStatus
+	19, // 4: oms.v1.Order.created_at:type_name -> google.protobuf.Timestamp
+	19, // 5: oms.v1.Order.updated_at:type_name -> google.protobuf.Timestamp
+	19, // 6: oms.v1.Trade.created_at:type_name -> google.protobuf.Timestamp
+	19, // 7: oms.v1.BookEntry.created_at:type_name -> google.protobuf.Timestamp
+	5,  // 8: oms.v1.OrderBook.bids:type_name -> oms.v1.BookEntry
+	5,  // 9: oms.v1.OrderBook.asks:type_name -> oms.v1.BookEntry
+	0,  // 10: oms.v1.PlaceOrderRequest.side:type_name -> oms.v1.Side
+	1,  // 11: oms.v1.PlaceOrderRequest.type:type_name -> oms.v1.OrderType
+	3,  // 12: oms.v1.PlaceOrderResponse.order:type_name -> oms.v1.Order
+	3,  // 13: oms.v1.CancelOrderResponse.order:type_name -> oms.v1.Order
+	3,  // 14: oms.v1.GetOrderResponse.order:type_name -> oms.v1.Order
+	6,  // 15: oms.v1.GetOrderBookResponse.book:type_name -> oms.v1.OrderBook
+	4,  // 16: oms.v1.ListTradesResponse.trades:type_name -> oms.v1.Trade
+	7,  // 17: oms.v1.OrderMatching.PlaceOrder:input_type -> oms.v1.PlaceOrderRequest
+	9,  // 18: oms.v1.OrderMatching.CancelOrder:input_type -> oms.v1.CancelOrderRequest
+	11, // 19: oms.v1.OrderMatching.GetOrder:input_type -> oms.v1.GetOrderRequest
+	13, // 20: oms.v1.OrderMatching.GetOrderBook:input_type -> oms.v1.GetOrderBookRequest
+	15, // 21: oms.v1.OrderMatching.ListTrades:input_type -> oms.v1.ListTradesRequest
+	17, // 22: oms.v1.OrderMatching.StreamTrades:input_type -> oms.v1.StreamTradesRequest
+	18, // 23: oms.v1.OrderMatching.StreamBookUpdates:input_type -> oms.v1.StreamBookUpdatesRequest
+	8,  // 24: oms.v1.OrderMatching.PlaceOrder:output_type -> oms.v1.PlaceOrderResponse
+	10, // 25: oms.v1.OrderMatching.CancelOrder:output_type -> oms.v1.CancelOrderResponse
+	12, // 26: oms.v1.OrderMatching.GetOrder:output_type -> oms.v1.GetOrderResponse
+	14, // 27: oms.v1.OrderMatching.GetOrderBook:output_type -> oms.v1.GetOrderBookResponse
+	16, // 28: oms.v1.OrderMatching.ListTrades:output_type -> oms.v1.ListTradesResponse
+	4,  // 29: oms.v1.OrderMatching.StreamTrades:output_type -> oms.v1.Trade
+	6,  // 30: oms.v1.OrderMatching.StreamBookUpdates:output_type -> oms.v1.OrderBook
+	24, // [24:31] is the sub-list for method output_type
+	17, // [17:24] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
+}
+
+func init() { file_oms_proto_init() }
+func file_oms_proto_init() {
+	if File_oms_proto != nil {
+		return
+	}
+	file_oms_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_oms_proto_rawDesc), len(file_oms_proto_rawDesc)),
+			NumEnums:      3,
+			NumMessages:   16,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_oms_proto_goTypes,
+		DependencyIndexes: file_oms_proto_depIdxs,
+		EnumInfos:         file_oms_proto_enumTypes,
+		MessageInfos:      file_oms_proto_msgTypes,
+	}.Build()
+	File_oms_proto = out.File
+	file_oms_proto_goTypes = nil
+	file_oms_proto_depIdxs = nil
+}
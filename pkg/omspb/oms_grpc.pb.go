@@ -0,0 +1,368 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: oms.proto
+
+package omspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	OrderMatching_PlaceOrder_FullMethodName        = "/oms.v1.OrderMatching/PlaceOrder"
+	OrderMatching_CancelOrder_FullMethodName       = "/oms.v1.OrderMatching/CancelOrder"
+	OrderMatching_GetOrder_FullMethodName          = "/oms.v1.OrderMatching/GetOrder"
+	OrderMatching_GetOrderBook_FullMethodName      = "/oms.v1.OrderMatching/GetOrderBook"
+	OrderMatching_ListTrades_FullMethodName        = "/oms.v1.OrderMatching/ListTrades"
+	OrderMatching_StreamTrades_FullMethodName      = "/oms.v1.OrderMatching/StreamTrades"
+	OrderMatching_StreamBookUpdates_FullMethodName = "/oms.v1.OrderMatching/StreamBookUpdates"
+)
+
+// OrderMatchingClient is the client API for OrderMatching service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// OrderMatching is the gRPC counterpart of the HTTP order entry and market
+// data API. Both delegate to the same matching engine and repositories.
+type OrderMatchingClient interface {
+	PlaceOrder(ctx context.Context, in *PlaceOrderRequest, opts ...grpc.CallOption) (*PlaceOrderResponse, error)
+	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error)
+	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*GetOrderResponse, error)
+	GetOrderBook(ctx context.Context, in *GetOrderBookRequest, opts ...grpc.CallOption) (*GetOrderBookResponse, error)
+	ListTrades(ctx context.Context, in *ListTradesRequest, opts ...grpc.CallOption) (*ListTradesResponse, error)
+	// StreamTrades sends each trade as it is committed
+	StreamTrades(ctx context.Context, in *StreamTradesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Trade], error)
+	// StreamBookUpdates sends the current book, then the book again after
+	// every change to it. Bursts of changes may be coalesced into one update.
+	StreamBookUpdates(ctx context.Context, in *StreamBookUpdatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderBook], error)
+}
+
+type orderMatchingClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOrderMatchingClient(cc grpc.ClientConnInterface) OrderMatchingClient {
+	return &orderMatchingClient{cc}
+}
+
+func (c *orderMatchingClient) PlaceOrder(ctx context.Context, in *PlaceOrderRequest, opts ...grpc.CallOption) (*PlaceOrderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PlaceOrderResponse)
+	err := c.cc.Invoke(ctx, OrderMatching_PlaceOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderMatchingClient) CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*CancelOrderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelOrderResponse)
+	err := c.cc.Invoke(ctx, OrderMatching_CancelOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderMatchingClient) GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*GetOrderResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrderResponse)
+	err := c.cc.Invoke(ctx, OrderMatching_GetOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderMatchingClient) GetOrderBook(ctx context.Context, in *GetOrderBookRequest, opts ...grpc.CallOption) (*GetOrderBookResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetOrderBookResponse)
+	err := c.cc.Invoke(ctx, OrderMatching_GetOrderBook_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderMatchingClient) ListTrades(ctx context.Context, in *ListTradesRequest, opts ...grpc.CallOption) (*ListTradesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTradesResponse)
+	err := c.cc.Invoke(ctx, OrderMatching_ListTrades_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *orderMatchingClient) StreamTrades(ctx context.Context, in *StreamTradesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Trade], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OrderMatching_ServiceDesc.Streams[0], OrderMatching_StreamTrades_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamTradesRequest, Trade]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderMatching_StreamTradesClient = grpc.ServerStreamingClient[Trade]
+
+func (c *orderMatchingClient) StreamBookUpdates(ctx context.Context, in *StreamBookUpdatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[OrderBook], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &OrderMatching_ServiceDesc.Streams[1], OrderMatching_StreamBookUpdates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamBookUpdatesRequest, OrderBook]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderMatching_StreamBookUpdatesClient = grpc.ServerStreamingClient[OrderBook]
+
+// OrderMatchingServer is the server API for OrderMatching service.
+// All implementations must embed UnimplementedOrderMatchingServer
+// for forward compatibility.
+//
+// OrderMatching is the gRPC counterpart of the HTTP order entry and market
+// data API. Both delegate to the same matching engine and repositories.
+type OrderMatchingServer interface {
+	PlaceOrder(context.Context, *PlaceOrderRequest) (*PlaceOrderResponse, error)
+	CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error)
+	GetOrder(context.Context, *GetOrderRequest) (*GetOrderResponse, error)
+	GetOrderBook(context.Context, *GetOrderBookRequest) (*GetOrderBookResponse, error)
+	ListTrades(context.Context, *ListTradesRequest) (*ListTradesResponse, error)
+	// StreamTrades sends each trade as it is committed
+	StreamTrades(*StreamTradesRequest, grpc.ServerStreamingServer[Trade]) error
+	// StreamBookUpdates sends the current book, then the book again after
+	// every change to it. Bursts of changes may be coalesced into one update.
+	StreamBookUpdates(*StreamBookUpdatesRequest, grpc.ServerStreamingServer[OrderBook]) error
+	mustEmbedUnimplementedOrderMatchingServer()
+}
+
+// UnimplementedOrderMatchingServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedOrderMatchingServer struct{}
+
+func (UnimplementedOrderMatchingServer) PlaceOrder(context.Context, *PlaceOrderRequest) (*PlaceOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PlaceOrder not implemented")
+}
+func (UnimplementedOrderMatchingServer) CancelOrder(context.Context, *CancelOrderRequest) (*CancelOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
+}
+func (UnimplementedOrderMatchingServer) GetOrder(context.Context, *GetOrderRequest) (*GetOrderResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrder not implemented")
+}
+func (UnimplementedOrderMatchingServer) GetOrderBook(context.Context, *GetOrderBookRequest) (*GetOrderBookResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetOrderBook not implemented")
+}
+func (UnimplementedOrderMatchingServer) ListTrades(context.Context, *ListTradesRequest) (*ListTradesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrades not implemented")
+}
+func (UnimplementedOrderMatchingServer) StreamTrades(*StreamTradesRequest, grpc.ServerStreamingServer[Trade]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamTrades not implemented")
+}
+func (UnimplementedOrderMatchingServer) StreamBookUpdates(*StreamBookUpdatesRequest, grpc.ServerStreamingServer[OrderBook]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBookUpdates not implemented")
+}
+func (UnimplementedOrderMatchingServer) mustEmbedUnimplementedOrderMatchingServer() {}
+func (UnimplementedOrderMatchingServer) testEmbeddedByValue()                       {}
+
+// UnsafeOrderMatchingServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OrderMatchingServer will
+// result in compilation errors.
+type UnsafeOrderMatchingServer interface {
+	mustEmbedUnimplementedOrderMatchingServer()
+}
+
+func RegisterOrderMatchingServer(s grpc.ServiceRegistrar, srv OrderMatchingServer) {
+	// If the following call pancis, it indicates UnimplementedOrderMatchingServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&OrderMatching_ServiceDesc, srv)
+}
+
+func _OrderMatching_PlaceOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlaceOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderMatchingServer).PlaceOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderMatching_PlaceOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderMatchingServer).PlaceOrder(ctx, req.(*PlaceOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderMatching_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderMatchingServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderMatching_CancelOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderMatchingServer).CancelOrder(ctx, req.(*CancelOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderMatching_GetOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderMatchingServer).GetOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderMatching_GetOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderMatchingServer).GetOrder(ctx, req.(*GetOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderMatching_GetOrderBook_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetOrderBookRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderMatchingServer).GetOrderBook(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderMatching_GetOrderBook_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderMatchingServer).GetOrderBook(ctx, req.(*GetOrderBookRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderMatching_ListTrades_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTradesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OrderMatchingServer).ListTrades(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: OrderMatching_ListTrades_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OrderMatchingServer).ListTrades(ctx, req.(*ListTradesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OrderMatching_StreamTrades_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamTradesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrderMatchingServer).StreamTrades(m, &grpc.GenericServerStream[StreamTradesRequest, Trade]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderMatching_StreamTradesServer = grpc.ServerStreamingServer[Trade]
+
+func _OrderMatching_StreamBookUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamBookUpdatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OrderMatchingServer).StreamBookUpdates(m, &grpc.GenericServerStream[StreamBookUpdatesRequest, OrderBook]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type OrderMatching_StreamBookUpdatesServer = grpc.ServerStreamingServer[OrderBook]
+
+// OrderMatching_ServiceDesc is the grpc.ServiceDesc for OrderMatching service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OrderMatching_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "oms.v1.OrderMatching",
+	HandlerType: (*OrderMatchingServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PlaceOrder",
+			Handler:    _OrderMatching_PlaceOrder_Handler,
+		},
+		{
+			MethodName: "CancelOrder",
+			Handler:    _OrderMatching_CancelOrder_Handler,
+		},
+		{
+			MethodName: "GetOrder",
+			Handler:    _OrderMatching_GetOrder_Handler,
+		},
+		{
+			MethodName: "GetOrderBook",
+			Handler:    _OrderMatching_GetOrderBook_Handler,
+		},
+		{
+			MethodName: "ListTrades",
+			Handler:    _OrderMatching_ListTrades_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamTrades",
+			Handler:       _OrderMatching_StreamTrades_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamBookUpdates",
+			Handler:       _OrderMatching_StreamBookUpdates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "oms.proto",
+}
@@ -0,0 +1,36 @@
+// Package signing implements the HMAC request signatures accepted by the
+// order matching API when API keys are configured.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Headers carrying a request signature
+const (
+	HeaderKeyID     = "X-API-Key"
+	HeaderTimestamp = "X-Timestamp" // Unix seconds
+	HeaderSignature = "X-Signature" // Hex HMAC-SHA256
+)
+
+// Sign returns the signature of a request: the hex HMAC-SHA256, keyed by
+// secret, of the timestamp, method, request URI (path and query) and the
+// hex SHA-256 of the body, separated by newlines.
+func Sign(secret string, timestamp int64, method, requestURI string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("\n" + method + "\n" + requestURI + "\n"))
+	mac.Write([]byte(hex.EncodeToString(bodyHash[:])))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether signature is valid for the request, in constant time
+func Verify(secret, signature string, timestamp int64, method, requestURI string, body []byte) bool {
+	expected := Sign(secret, timestamp, method, requestURI, body)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}